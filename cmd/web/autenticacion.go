@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// exigirClaveAPI indica si la API solo atiende peticiones con una clave válida en el header
+// X-API-Key. Se configura con REQUIRE_API_KEY; por defecto la API es pública.
+var exigirClaveAPI atomic.Bool
+
+// claveAPI es una clave de acceso a la API con el nombre del cliente que la usa
+type claveAPI struct {
+	nombre string
+	valor  string
+}
+
+// clavesAPI son las claves aceptadas cuando exigirClaveAPI está activo (API_KEYS y API_KEYS_FILE)
+var clavesAPI atomic.Pointer[[]claveAPI]
+
+// parsearClaveAPI interpreta una entrada "nombre:clave" o solo "clave". Sin nombre, el cliente
+// se identifica con un prefijo del hash de la clave para no escribirla en los logs.
+func parsearClaveAPI(entrada string) (claveAPI, bool) {
+	entrada = strings.TrimSpace(entrada)
+	if entrada == "" {
+		return claveAPI{}, false
+	}
+	nombre, valor, conNombre := strings.Cut(entrada, ":")
+	if !conNombre {
+		suma := sha256.Sum256([]byte(entrada))
+		return claveAPI{nombre: "clave-" + hex.EncodeToString(suma[:4]), valor: entrada}, true
+	}
+	nombre, valor = strings.TrimSpace(nombre), strings.TrimSpace(valor)
+	if valor == "" {
+		return claveAPI{}, false
+	}
+	return claveAPI{nombre: nombre, valor: valor}, true
+}
+
+// cargarClavesAPI lee las claves de la lista separada por comas y, si se indica, del archivo
+// (una por línea; se ignoran las líneas vacías y las que empiezan con #)
+func cargarClavesAPI(lista, ruta string) ([]claveAPI, error) {
+	var claves []claveAPI
+	for _, entrada := range parsearListaEnv(lista) {
+		if clave, ok := parsearClaveAPI(entrada); ok {
+			claves = append(claves, clave)
+		}
+	}
+	if ruta == "" {
+		return claves, nil
+	}
+
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+	lector := bufio.NewScanner(archivo)
+	for lector.Scan() {
+		linea := strings.TrimSpace(lector.Text())
+		if strings.HasPrefix(linea, "#") {
+			continue
+		}
+		if clave, ok := parsearClaveAPI(linea); ok {
+			claves = append(claves, clave)
+		}
+	}
+	return claves, lector.Err()
+}
+
+// buscarClaveAPI devuelve el nombre del cliente de la clave, si es una de las configuradas.
+// Las claves privilegiadas (PRIVILEGED_API_KEYS) también dan acceso.
+func buscarClaveAPI(valor string) (string, bool) {
+	if claves := clavesAPI.Load(); claves != nil {
+		for _, clave := range *claves {
+			if subtle.ConstantTimeCompare([]byte(valor), []byte(clave.valor)) == 1 {
+				return clave.nombre, true
+			}
+		}
+	}
+	if esClavePrivilegiada(valor) {
+		return "privilegiada", true
+	}
+	return "", false
+}
+
+// requerirClaveAPI responde 401 a las peticiones sin una clave válida en X-API-Key mientras
+// REQUIRE_API_KEY está activo. El nombre del cliente se agrega al logger de la petición.
+func requerirClaveAPI(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !exigirClaveAPI.Load() {
+			siguiente.ServeHTTP(w, r)
+			return
+		}
+
+		nombre, ok := buscarClaveAPI(r.Header.Get("X-API-Key"))
+		if !ok {
+			cedula.LoggerFrom(r.Context()).Info("Petición rechazada por falta de una clave de API válida",
+				"conClave", r.Header.Get("X-API-Key") != "")
+			writeError(w, r, errNoAutorizado)
+			return
+		}
+		registro := cedula.LoggerFrom(r.Context()).With("clienteAPI", nombre)
+		siguiente.ServeHTTP(w, r.WithContext(cedula.WithLogger(r.Context(), registro)))
+	})
+}
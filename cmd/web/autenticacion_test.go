@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// configurarClavesAPI activa o desactiva durante la prueba la exigencia de clave de API con las
+// claves de la lista (formato de API_KEYS) y las claves privilegiadas indicadas
+func configurarClavesAPI(t *testing.T, exigir bool, lista string, privilegiadas ...string) {
+	t.Helper()
+	claves, err := cargarClavesAPI(lista, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exigirAnterior, clavesAnteriores, privilegiadasAnteriores := exigirClaveAPI.Load(), clavesAPI.Load(), clavesPrivilegiadas.Load()
+	exigirClaveAPI.Store(exigir)
+	clavesAPI.Store(&claves)
+	clavesPrivilegiadas.Store(&privilegiadas)
+	t.Cleanup(func() {
+		exigirClaveAPI.Store(exigirAnterior)
+		clavesAPI.Store(clavesAnteriores)
+		clavesPrivilegiadas.Store(privilegiadasAnteriores)
+	})
+}
+
+// peticionConClave envía una petición con la clave indicada (si no está vacía) a un handler detrás
+// de requerirClaveAPI y devuelve la respuesta junto con lo que registró el handler
+func peticionConClave(clave string) (*httptest.ResponseRecorder, string) {
+	var registros bytes.Buffer
+	req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+	req = req.WithContext(cedula.WithLogger(req.Context(), slog.New(slog.NewJSONHandler(&registros, nil))))
+	if clave != "" {
+		req.Header.Set("X-API-Key", clave)
+	}
+	rec := httptest.NewRecorder()
+	requerirClaveAPI(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cedula.LoggerFrom(r.Context()).Info("atendida")
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	return rec, registros.String()
+}
+
+func TestRequerirClaveAPI(t *testing.T) {
+	configurarClavesAPI(t, true, "movil:clave-movil, clave-sin-nombre", "clave-privilegiada")
+
+	casos := []struct {
+		nombre  string
+		clave   string
+		estado  int
+		cliente string
+	}{
+		{"sin clave", "", http.StatusUnauthorized, ""},
+		{"clave incorrecta", "otra-clave", http.StatusUnauthorized, ""},
+		{"prefijo de una clave válida", "clave-mov", http.StatusUnauthorized, ""},
+		{"clave con nombre", "clave-movil", http.StatusOK, "movil"},
+		{"clave sin nombre", "clave-sin-nombre", http.StatusOK, "clave-"},
+		{"clave privilegiada", "clave-privilegiada", http.StatusOK, "privilegiada"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec, registros := peticionConClave(caso.clave)
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			if caso.estado == http.StatusUnauthorized {
+				var respuesta ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+					t.Fatal(err)
+				}
+				if respuesta.Code != CodigoNoAutorizado {
+					t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoNoAutorizado)
+				}
+				return
+			}
+			// El cliente queda identificado en los logs de la petición, nunca con su clave
+			if !strings.Contains(registros, `"clienteAPI":"`+caso.cliente) {
+				t.Errorf("los logs no identifican al cliente %q:\n%s", caso.cliente, registros)
+			}
+			if strings.Contains(registros, caso.clave) {
+				t.Errorf("los logs no deberían contener la clave:\n%s", registros)
+			}
+		})
+	}
+}
+
+func TestRequerirClaveAPIDesactivado(t *testing.T) {
+	configurarClavesAPI(t, false, "movil:clave-movil")
+
+	for _, clave := range []string{"", "otra-clave"} {
+		if rec, _ := peticionConClave(clave); rec.Code != http.StatusOK {
+			t.Errorf("clave %q: estado = %d, la API debería ser pública por defecto", clave, rec.Code)
+		}
+	}
+}
+
+func TestCargarClavesAPI(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "claves")
+	contenido := "# clientes internos\nweb:clave-web\n\n  clave-suelta  \nsin-valor:\n"
+	if err := os.WriteFile(ruta, []byte(contenido), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	claves, err := cargarClavesAPI("movil:clave-movil, ,", ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valores := make([]string, len(claves))
+	for i, clave := range claves {
+		valores[i] = clave.valor
+	}
+	if got := strings.Join(valores, ","); got != "clave-movil,clave-web,clave-suelta" {
+		t.Errorf("claves = %s, se esperaba clave-movil,clave-web,clave-suelta", got)
+	}
+	if claves[1].nombre != "web" || !strings.HasPrefix(claves[2].nombre, "clave-") || claves[2].nombre == "clave-suelta" {
+		t.Errorf("nombres = %q, %q", claves[1].nombre, claves[2].nombre)
+	}
+
+	if _, err := cargarClavesAPI("", filepath.Join(t.TempDir(), "no-existe")); err == nil {
+		t.Error("un archivo de claves inexistente debería ser un error")
+	}
+}
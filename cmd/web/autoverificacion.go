@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// casoAutoverificacion es una identificación conocida con el resultado que debe dar la validación
+type casoAutoverificacion struct {
+	identificacion string
+	valida         bool
+}
+
+// casosAutoverificacion cubren el módulo 10 de las cédulas, el módulo 11 de los RUC de
+// sociedades y entidades públicas, la provincia y el establecimiento
+var casosAutoverificacion = []casoAutoverificacion{
+	{"1710034065", true},
+	{"0912345675", true},
+	{"3000000004", true},
+	{"1710034064", false}, // dígito verificador incorrecto
+	{"2501010108", false}, // provincia inexistente
+	{"1765432107", false}, // tercer dígito de persona jurídica
+	{"1710034065001", true},
+	{"1790000001001", true},
+	{"1760000070001", true},
+	{"1790000002001", false}, // verificador módulo 11 incorrecto
+	{"1710034065000", false}, // establecimiento 000
+}
+
+// identificacionValida valida localmente una cédula de 10 dígitos o un RUC de 13
+func identificacionValida(identificacion string) bool {
+	if len(identificacion) == 13 {
+		return cedula.ValidateRUC(identificacion)
+	}
+	return cedula.ValidateCedula(identificacion)
+}
+
+// autoverificarValidacion comprueba que validar dé el resultado esperado para cada caso conocido.
+// Se ejecuta al arrancar para que un error en el algoritmo del dígito verificador detenga el
+// servidor antes de atender peticiones, en lugar de rechazar (o aceptar) identificaciones en silencio.
+func autoverificarValidacion(validar func(string) bool, casos []casoAutoverificacion) error {
+	for _, caso := range casos {
+		if validar(caso.identificacion) != caso.valida {
+			return fmt.Errorf("la validación de %s debería dar %t", caso.identificacion, caso.valida)
+		}
+	}
+	return nil
+}
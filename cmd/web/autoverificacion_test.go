@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestAutoverificarValidacion(t *testing.T) {
+	if err := autoverificarValidacion(identificacionValida, casosAutoverificacion); err != nil {
+		t.Fatalf("la validación actual no pasa la autoverificación: %v", err)
+	}
+}
+
+func TestAutoverificarValidacionRota(t *testing.T) {
+	// Una validación que solo revisa la longitud acepta dígitos verificadores incorrectos
+	soloLongitud := func(identificacion string) bool {
+		return len(identificacion) == 10 || len(identificacion) == 13
+	}
+	if err := autoverificarValidacion(soloLongitud, casosAutoverificacion); err == nil {
+		t.Error("se esperaba que la autoverificación fallara con una validación rota")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// bloquearBots indica si se rechazan las peticiones con firmas evidentes de automatización.
+// Se configura con BLOCK_BOTS.
+var bloquearBots atomic.Bool
+
+// patronesBotsPorDefecto son fragmentos de User-Agent (sin distinguir mayúsculas) que se
+// rechazan si no se configura BOT_UA_PATTERNS
+var patronesBotsPorDefecto = []string{
+	"python-requests",
+	"python-urllib",
+	"scrapy",
+	"wget",
+	"go-http-client",
+	"libwww-perl",
+	"httpclient",
+	"headlesschrome",
+	"phantomjs",
+}
+
+// patronesBots son los fragmentos de User-Agent que se rechazan. Se pueden reemplazar con
+// BOT_UA_PATTERNS, separados por comas.
+var patronesBots atomic.Pointer[[]string]
+
+func init() {
+	patronesBots.Store(&patronesBotsPorDefecto)
+}
+
+// errAccesoDenegado se devuelve a las peticiones rechazadas por parecer automatizadas
+var errAccesoDenegado = &errorAPI{codigo: CodigoAccesoDenegado, estado: http.StatusForbidden, mensaje: "Acceso denegado"}
+
+// esAgenteBot indica si el User-Agent está vacío o coincide con algún patrón de bots
+func esAgenteBot(agente string) bool {
+	agente = strings.ToLower(strings.TrimSpace(agente))
+	if agente == "" {
+		return true
+	}
+	for _, patron := range *patronesBots.Load() {
+		if strings.Contains(agente, strings.ToLower(patron)) {
+			return true
+		}
+	}
+	return false
+}
+
+// rechazarBots responde 403 a las peticiones cuyo User-Agent parece de un bot mientras
+// BLOCK_BOTS está activo
+func rechazarBots(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bloquearBots.Load() && esAgenteBot(r.UserAgent()) {
+			writeError(w, r, errAccesoDenegado)
+			return
+		}
+		siguiente.ServeHTTP(w, r)
+	})
+}
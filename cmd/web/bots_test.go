@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// activarBloqueoBots activa BLOCK_BOTS durante la prueba
+func activarBloqueoBots(t *testing.T, activo bool) {
+	t.Helper()
+	anterior := bloquearBots.Load()
+	bloquearBots.Store(activo)
+	t.Cleanup(func() { bloquearBots.Store(anterior) })
+}
+
+func TestRechazarBots(t *testing.T) {
+	activarBloqueoBots(t, true)
+
+	casos := []struct {
+		nombre string
+		agente string
+		estado int
+	}{
+		{"navegador", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", http.StatusOK},
+		{"python-requests", "python-requests/2.31.0", http.StatusForbidden},
+		{"curl no es un patrón por defecto", "curl/8.4.0", http.StatusOK},
+		{"cliente de Go", "Go-http-client/1.1", http.StatusForbidden},
+		{"Chrome sin interfaz", "Mozilla/5.0 HeadlessChrome/120.0", http.StatusForbidden},
+		{"sin User-Agent", "", http.StatusForbidden},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+			req.Header.Set("User-Agent", caso.agente)
+			rec := atenderCon(rechazarBots, req)
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			if caso.estado == http.StatusForbidden {
+				var respuesta ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+					t.Fatal(err)
+				}
+				if respuesta.Code != CodigoAccesoDenegado {
+					t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoAccesoDenegado)
+				}
+			}
+		})
+	}
+}
+
+func TestRechazarBotsDesactivado(t *testing.T) {
+	activarBloqueoBots(t, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+	req.Header.Set("User-Agent", "python-requests/2.31.0")
+	if rec := atenderCon(rechazarBots, req); rec.Code != http.StatusOK {
+		t.Errorf("estado = %d, sin BLOCK_BOTS no se rechaza ningún User-Agent", rec.Code)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// Subcomandos de la línea de comandos
+const (
+	comandoServir  = "serve"
+	comandoCedula  = "cedula"
+	comandoNombres = "nombres"
+)
+
+// usoCLI describe los subcomandos disponibles con el nombre con el que se invocó el programa
+func usoCLI(programa string) string {
+	return fmt.Sprintf(`Uso:
+  %[1]s [serve] [-port PUERTO]    inicia el servidor HTTP (por defecto)
+  %[1]s cedula IDENTIFICACION      consulta una cédula o RUC e imprime el resultado en JSON
+  %[1]s nombres NOMBRES APELLIDOS  consulta por nombres y apellidos
+`, programa)
+}
+
+// parsearComando determina el subcomando y sus argumentos. Sin subcomando (o si el primer
+// argumento es un flag) se inicia el servidor, como antes de existir los subcomandos.
+func parsearComando(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return comandoServir, args, nil
+	}
+
+	comando, argumentos := args[0], args[1:]
+	switch comando {
+	case comandoServir:
+		return comando, argumentos, nil
+	case comandoCedula:
+		if len(argumentos) != 1 {
+			return "", nil, fmt.Errorf("el subcomando cedula recibe exactamente una identificación")
+		}
+		return comando, argumentos, nil
+	case comandoNombres:
+		if len(argumentos) != 2 {
+			return "", nil, fmt.Errorf("el subcomando nombres recibe los nombres y los apellidos (use comillas si tienen espacios)")
+		}
+		return comando, argumentos, nil
+	default:
+		return "", nil, fmt.Errorf("subcomando desconocido %q", comando)
+	}
+}
+
+// prepararCLI carga la configuración para una consulta única y devuelve un contexto que se
+// cancela con Ctrl+C. Los logs solo muestran advertencias salvo que LOG_LEVEL indique otra cosa.
+func prepararCLI() (context.Context, context.CancelFunc) {
+	cargarConfigInicial()
+	nivel, err := parsearNivelRegistro(os.Getenv("LOG_LEVEL"))
+	if err != nil || os.Getenv("LOG_LEVEL") == "" {
+		nivel = slog.LevelWarn
+	}
+	nivelRegistro.Set(nivel)
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// imprimirJSON escribe el valor como JSON indentado
+func imprimirJSON(salida io.Writer, valor interface{}) error {
+	codificador := json.NewEncoder(salida)
+	codificador.SetIndent("", "  ")
+	return codificador.Encode(valor)
+}
+
+// mensajeCLI devuelve el mensaje de error para la terminal: el del catálogo de la API o, si es
+// un error interno o del SRI, su detalle (que el servidor solo muestra en los logs)
+func mensajeCLI(err error) string {
+	if apiErr := comoErrorAPI(err); apiErr != errInterno && apiErr != errErrorSRI {
+		return apiErr.mensaje
+	}
+	return err.Error()
+}
+
+// ejecutarConsultaCedula consulta una cédula o RUC en el SRI e imprime el resultado en JSON.
+// Devuelve el código de salida del proceso: 0 si se encontró y 1 en cualquier otro caso.
+func ejecutarConsultaCedula(valor string, salida, errores io.Writer) int {
+	ctx, cancelar := prepararCLI()
+	defer cancelar()
+
+	identificacion, err := validarIdentificacion(ctx, valor)
+	if err != nil {
+		fmt.Fprintln(errores, mensajeCLI(err))
+		return 1
+	}
+
+	configurarProxyUpstream()
+	cliente := &cedula.Client{
+		HTTPClient:  nuevoClienteHTTP(),
+		Hosts:       cedula.NewHosts(os.Getenv("SRI_BASE_URLS")),
+		FallbackURL: cargarFuenteRespaldo(),
+		Detailed:    leerBoolEnv("DETAILED_RESPONSE", false),
+	}
+	cliente.UserAgents, cliente.Headers = cargarCabecerasSRI()
+	resultado, err := cliente.Lookup(ctx, identificacion)
+	if err != nil {
+		fmt.Fprintln(errores, mensajeCLI(err))
+		return 1
+	}
+	if err := imprimirJSON(salida, resultado); err != nil {
+		fmt.Fprintln(errores, err)
+		return 1
+	}
+	return 0
+}
+
+// ejecutarConsultaNombres realiza la consulta por nombres y apellidos e imprime el resultado en
+// JSON. Como no hay una fuente pública gratuita, normalmente informa las alternativas y sale con 1.
+func ejecutarConsultaNombres(nombres, apellidos string, salida, errores io.Writer) int {
+	ctx, cancelar := prepararCLI()
+	defer cancelar()
+
+	if err := validarNombresRequest(NombresRequest{Nombres: nombres, Apellidos: apellidos}); err != nil {
+		fmt.Fprintln(errores, err)
+		return 1
+	}
+
+	resultado, err := cedula.LookupByName(ctx, strings.TrimSpace(nombres), strings.TrimSpace(apellidos))
+	if err != nil {
+		fmt.Fprintln(errores, err)
+		return 1
+	}
+	if err := imprimirJSON(salida, resultado); err != nil {
+		fmt.Fprintln(errores, err)
+		return 1
+	}
+	return 0
+}
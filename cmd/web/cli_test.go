@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsoCLIUsaElNombreDelPrograma(t *testing.T) {
+	uso := usoCLI("cedula-srv")
+	for _, comando := range []string{"cedula-srv [serve]", "cedula-srv cedula IDENTIFICACION", "cedula-srv nombres NOMBRES APELLIDOS"} {
+		if !strings.Contains(uso, comando) {
+			t.Errorf("el uso no incluye %q:\n%s", comando, uso)
+		}
+	}
+}
+
+func TestParsearComando(t *testing.T) {
+	casos := []struct {
+		args       []string
+		comando    string
+		argumentos []string
+		valido     bool
+	}{
+		{nil, comandoServir, nil, true},
+		{[]string{"-port", "9000"}, comandoServir, []string{"-port", "9000"}, true},
+		{[]string{"--port=9000"}, comandoServir, []string{"--port=9000"}, true},
+		{[]string{"serve"}, comandoServir, []string{}, true},
+		{[]string{"serve", "-port", "0"}, comandoServir, []string{"-port", "0"}, true},
+		{[]string{"cedula", "1710034065"}, comandoCedula, []string{"1710034065"}, true},
+		{[]string{"cedula"}, "", nil, false},
+		{[]string{"cedula", "1710034065", "0912345675"}, "", nil, false},
+		{[]string{"nombres", "JUAN CARLOS", "PEREZ LOPEZ"}, comandoNombres, []string{"JUAN CARLOS", "PEREZ LOPEZ"}, true},
+		{[]string{"nombres", "JUAN", "CARLOS", "PEREZ"}, "", nil, false},
+		{[]string{"nombres", "JUAN CARLOS"}, "", nil, false},
+		{[]string{"consultar", "1710034065"}, "", nil, false},
+		// Los subcomandos distinguen mayúsculas, como los flags
+		{[]string{"Cedula", "1710034065"}, "", nil, false},
+	}
+	for _, caso := range casos {
+		comando, argumentos, err := parsearComando(caso.args)
+		if (err == nil) != caso.valido {
+			t.Errorf("parsearComando(%q) error = %v, se esperaba válido = %v", caso.args, err, caso.valido)
+			continue
+		}
+		if comando != caso.comando || strings.Join(argumentos, "|") != strings.Join(caso.argumentos, "|") {
+			t.Errorf("parsearComando(%q) = %q, %q; se esperaba %q, %q", caso.args, comando, argumentos, caso.comando, caso.argumentos)
+		}
+	}
+}
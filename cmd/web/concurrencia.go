@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// concurrenciaPorDefecto es el límite de consultas simultáneas de cada fuente si no se configura otro
+var concurrenciaPorDefecto = map[string]int{
+	"sri":     10,
+	"nombres": 2,
+}
+
+// semaforosFuente limitan las consultas simultáneas de cada fuente de forma independiente
+var semaforosFuente = crearSemaforos(concurrenciaPorDefecto)
+
+// crearSemaforos crea un semáforo por fuente con la capacidad indicada
+func crearSemaforos(limites map[string]int) map[string]chan struct{} {
+	semaforos := make(map[string]chan struct{}, len(limites))
+	for fuente, limite := range limites {
+		semaforos[fuente] = make(chan struct{}, limite)
+	}
+	return semaforos
+}
+
+// parsearConcurrencia lee límites con el formato "fuente=n,fuente=n" (SOURCE_CONCURRENCY) sobre
+// los valores por defecto. Solo se aceptan fuentes conocidas y límites positivos.
+func parsearConcurrencia(valor string) (map[string]int, error) {
+	limites := make(map[string]int, len(concurrenciaPorDefecto))
+	for fuente, limite := range concurrenciaPorDefecto {
+		limites[fuente] = limite
+	}
+
+	for _, par := range parsearListaEnv(valor) {
+		fuente, numero, ok := strings.Cut(par, "=")
+		fuente = strings.ToLower(strings.TrimSpace(fuente))
+		if !ok {
+			return nil, fmt.Errorf("se esperaba fuente=n en %q", par)
+		}
+		if _, conocida := concurrenciaPorDefecto[fuente]; !conocida {
+			return nil, fmt.Errorf("fuente desconocida %q", fuente)
+		}
+		limite, err := strconv.Atoi(strings.TrimSpace(numero))
+		if err != nil || limite <= 0 {
+			return nil, fmt.Errorf("límite inválido para %s: %q", fuente, numero)
+		}
+		limites[fuente] = limite
+	}
+	return limites, nil
+}
+
+// adquirirFuente espera un lugar libre para consultar la fuente, o hasta que se cancele el
+// contexto; se debe llamar a la función devuelta al terminar la consulta
+func adquirirFuente(ctx context.Context, fuente string) (func(), error) {
+	semaforo := semaforosFuente[fuente]
+	select {
+	case semaforo <- struct{}{}:
+		return func() { <-semaforo }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// configurarConcurrencia reemplaza los semáforos de las fuentes durante la prueba
+func configurarConcurrencia(t *testing.T, limites map[string]int) {
+	t.Helper()
+	anteriores := semaforosFuente
+	semaforosFuente = crearSemaforos(limites)
+	t.Cleanup(func() { semaforosFuente = anteriores })
+}
+
+func TestSemaforoLimitaLlamadasSimultaneasAlSRI(t *testing.T) {
+	configurarConcurrencia(t, map[string]int{"sri": 2, "nombres": 1})
+
+	var activas, maximo atomic.Int32
+	liberar := make(chan struct{})
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actuales := activas.Add(1)
+		defer activas.Add(-1)
+		for {
+			anterior := maximo.Load()
+			if actuales <= anterior || maximo.CompareAndSwap(anterior, actuales) {
+				break
+			}
+		}
+		<-liberar
+		w.Write([]byte(`{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS"}}`))
+	}))
+	defer servidor.Close()
+
+	cliente := &cedula.Client{Hosts: cedula.NewHosts(servidor.URL), BeforeCall: antesDeLlamarSRI}
+	// Identificaciones distintas para que no se agrupen en una sola llamada
+	ids := []string{"1710034065", "0912345675", "0102030400", "2401010109", "3000000004"}
+
+	var wg sync.WaitGroup
+	errores := make(chan error, len(ids))
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, err := cliente.Lookup(context.Background(), id)
+			errores <- err
+		}(id)
+	}
+
+	// Esperar a que se ocupen los dos lugares y dar tiempo a que el resto intente entrar
+	limite := time.Now().Add(2 * time.Second)
+	for activas.Load() < 2 && time.Now().Before(limite) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := activas.Load(); got != 2 {
+		t.Errorf("llamadas simultáneas = %d, se esperaban 2", got)
+	}
+
+	close(liberar)
+	wg.Wait()
+	close(errores)
+	for err := range errores {
+		if err != nil {
+			t.Errorf("consulta: %v", err)
+		}
+	}
+	if got := maximo.Load(); got != 2 {
+		t.Errorf("máximo de llamadas simultáneas = %d, se esperaban 2", got)
+	}
+}
+
+func TestAdquirirFuenteRespetaLaCancelacion(t *testing.T) {
+	configurarConcurrencia(t, map[string]int{"sri": 1, "nombres": 1})
+
+	liberar, err := adquirirFuente(context.Background(), "sri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer liberar()
+
+	// Las fuentes son independientes: ocupar el SRI no bloquea la de nombres
+	liberarNombres, err := adquirirFuente(context.Background(), "nombres")
+	if err != nil {
+		t.Fatalf("nombres: %v", err)
+	}
+	liberarNombres()
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelar()
+	if _, err := adquirirFuente(ctx, "sri"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, se esperaba context.DeadlineExceeded con la fuente ocupada", err)
+	}
+}
+
+func TestParsearConcurrencia(t *testing.T) {
+	casos := []struct {
+		valor    string
+		esperado map[string]int
+		valido   bool
+	}{
+		{"", map[string]int{"sri": 10, "nombres": 2}, true},
+		{"sri=3", map[string]int{"sri": 3, "nombres": 2}, true},
+		{" SRI = 4 , nombres=1", map[string]int{"sri": 4, "nombres": 1}, true},
+		{"sri", nil, false},
+		{"registro-civil=2", nil, false},
+		{"sri=0", nil, false},
+		{"sri=-1", nil, false},
+		{"sri=muchos", nil, false},
+	}
+	for _, caso := range casos {
+		limites, err := parsearConcurrencia(caso.valor)
+		if (err == nil) != caso.valido {
+			t.Errorf("parsearConcurrencia(%q) error = %v, se esperaba válido = %v", caso.valor, err, caso.valido)
+			continue
+		}
+		for fuente, limite := range caso.esperado {
+			if limites[fuente] != limite {
+				t.Errorf("parsearConcurrencia(%q)[%s] = %d, se esperaba %d", caso.valor, fuente, limites[fuente], limite)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// rutaConsultar es el prefijo de la consulta con la identificación en la ruta
+const rutaConsultar = "/api/consultar/"
+
+// manejarConsultaPorRuta maneja las peticiones GET a /api/consultar/{cedula}. Es la misma
+// consulta que GET /api/consultar?cedula=, con una URL estable que los CDN y proxies pueden
+// guardar y revalidar con If-Modified-Since.
+func manejarConsultaPorRuta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, errMetodoNoPermitido)
+		return
+	}
+	valor := strings.TrimPrefix(r.URL.Path, rutaConsultar)
+	if valor == "" || strings.Contains(valor, "/") {
+		var validacion ValidationError
+		validacion.Agregar("cedula", "La ruta debe ser /api/consultar/{cedula}")
+		writeError(w, r, validacion.Err())
+		return
+	}
+
+	// Se pasa la identificación como el parámetro cedula, conservando el resto de la query string
+	consulta := r.URL.Query()
+	consulta.Set("cedula", valor)
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = consulta.Encode()
+	manejarConsulta(w, r)
+}
+
+// responderNoModificado agrega Last-Modified con la fecha en que se obtuvieron los datos y, si
+// el If-Modified-Since de la petición no es anterior, responde 304 y devuelve true. Los
+// resultados sin fecha de consulta se responden siempre completos.
+func responderNoModificado(w http.ResponseWriter, r *http.Request, resultado *cedula.Result) bool {
+	if resultado.ConsultadoEn.IsZero() {
+		return false
+	}
+	// Los headers HTTP tienen precisión de segundos
+	modificado := resultado.ConsultadoEn.Truncate(time.Second)
+	w.Header().Set("Last-Modified", modificado.UTC().Format(http.TimeFormat))
+
+	desde, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modificado.After(desde) {
+		return false
+	}
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
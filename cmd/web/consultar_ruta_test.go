@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+func TestManejarConsultaPorRuta(t *testing.T) {
+	usarSRIPrueba(t)
+
+	rec := httptest.NewRecorder()
+	manejarConsultaPorRuta(rec, httptest.NewRequest(http.MethodGet, rutaConsultar+"1710034065?nameFormat=apellidos-nombres", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resultado cedula.Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	// El resto de la query string se respeta igual que en GET /api/consultar?cedula=
+	if resultado.Nombres != "JUAN CARLOS" || resultado.NombreFormateado != "PEREZ LOPEZ, JUAN CARLOS" {
+		t.Errorf("resultado = %+v, se esperaban los datos del SRI con nombreFormateado", resultado)
+	}
+	if rec.Header().Get("Last-Modified") == "" {
+		t.Error("falta Last-Modified en la respuesta 200")
+	}
+}
+
+func TestManejarConsultaPorRutaErrores(t *testing.T) {
+	usarSRIProhibido(t)
+
+	casos := []struct {
+		nombre, metodo, ruta string
+		estado               int
+		codigo               CodigoError
+	}{
+		{"sin cédula", http.MethodGet, rutaConsultar, http.StatusBadRequest, CodigoValidacion},
+		{"ruta con más segmentos", http.MethodGet, rutaConsultar + "1710034065/extra", http.StatusBadRequest, CodigoValidacion},
+		{"cédula inválida", http.MethodGet, rutaConsultar + "1710034064", http.StatusBadRequest, CodigoCedulaInvalida},
+		{"método no permitido", http.MethodPost, rutaConsultar + "1710034065", http.StatusMethodNotAllowed, CodigoMetodoNoPermitido},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			manejarConsultaPorRuta(rec, httptest.NewRequest(caso.metodo, caso.ruta, nil))
+
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			var respuesta ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Code != caso.codigo {
+				t.Errorf("código = %s, se esperaba %s", respuesta.Code, caso.codigo)
+			}
+		})
+	}
+}
+
+func TestConsultaPorRutaConIfModifiedSince(t *testing.T) {
+	usarSRIProhibido(t)
+	sinLimites(t)
+	cache := cedula.NewCache(10)
+	clienteSRI.Cache = cache
+	guardado := &cedula.Result{Nombre: "GUARDADO", Fuente: cedula.SourceSRI, ConsultadoEn: time.Now().Add(-10 * time.Minute).UTC()}
+	cache.Set("1710034065", guardado, time.Hour)
+	mux := nuevoMux()
+
+	consultar := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, rutaConsultar+"1710034065", nil)
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// La primera respuesta lleva la fecha en que se obtuvieron los datos guardados
+	rec := consultar("")
+	ultimaModificacion := rec.Header().Get("Last-Modified")
+	if rec.Code != http.StatusOK || ultimaModificacion != guardado.ConsultadoEn.Format(http.TimeFormat) {
+		t.Fatalf("estado = %d, Last-Modified = %q; se esperaba 200 y %q", rec.Code, ultimaModificacion, guardado.ConsultadoEn.Format(http.TimeFormat))
+	}
+
+	// Mientras la entrada de la caché no cambie, la copia del cliente sigue vigente
+	rec = consultar(ultimaModificacion)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("estado = %d, cuerpo %q; se esperaba 304 sin cuerpo", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Last-Modified") != ultimaModificacion {
+		t.Errorf("Last-Modified del 304 = %q, se esperaba %q", rec.Header().Get("Last-Modified"), ultimaModificacion)
+	}
+
+	// Una fecha inválida se ignora y se responde completo
+	if rec = consultar("ayer"); rec.Code != http.StatusOK {
+		t.Errorf("con If-Modified-Since inválido: estado = %d, se esperaba 200", rec.Code)
+	}
+
+	// Con datos más nuevos se responde completo con la nueva fecha
+	nuevo := &cedula.Result{Nombre: "NUEVO", Fuente: cedula.SourceSRI, ConsultadoEn: time.Now().UTC()}
+	cache.Set("1710034065", nuevo, time.Hour)
+	rec = consultar(ultimaModificacion)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200 con datos más nuevos", rec.Code)
+	}
+	var resultado cedula.Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Nombre != "NUEVO" || rec.Header().Get("Last-Modified") != nuevo.ConsultadoEn.Format(http.TimeFormat) {
+		t.Errorf("nombre = %q, Last-Modified = %q; se esperaban los datos nuevos", resultado.Nombre, rec.Header().Get("Last-Modified"))
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// politicaCORS indica a qué orígenes se les permite leer las respuestas desde un navegador
+type politicaCORS struct {
+	// todos permite cualquier origen (Access-Control-Allow-Origin: *)
+	todos    bool
+	origenes map[string]bool
+}
+
+// corsPermitidos es la política CORS activa (CORS_ALLOWED_ORIGINS)
+var corsPermitidos atomic.Pointer[politicaCORS]
+
+// cargarPoliticaCORS interpreta CORS_ALLOWED_ORIGINS, una lista de orígenes separados por comas.
+// Vacía o con "*" permite cualquier origen, como antes de existir la variable.
+func cargarPoliticaCORS(lista string) *politicaCORS {
+	origenes := cargarOrigenesPermitidos(lista)
+	if origenes == nil || origenes["*"] {
+		return &politicaCORS{todos: true}
+	}
+	return &politicaCORS{origenes: origenes}
+}
+
+// permite indica si el origen puede leer las respuestas
+func (p *politicaCORS) permite(origen string) bool {
+	return p.todos || p.origenes[strings.ToLower(strings.TrimRight(origen, "/"))]
+}
+
+// aplicarCORS agrega los headers CORS solo para los orígenes permitidos: con "*" se responde con
+// el comodín y con una lista se devuelve el Origin de la petición. A los demás no se les agrega
+// ningún header, así que el navegador no les entrega la respuesta. Las peticiones preflight
+// (OPTIONS) se responden aquí: 204 si el origen está permitido y 403 si no.
+func aplicarCORS(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		politica := corsPermitidos.Load()
+		origen := r.Header.Get("Origin")
+		permitido := politica == nil || politica.permite(origen)
+
+		if politica != nil && !politica.todos {
+			// La respuesta depende del origen, así que no se puede reutilizar entre orígenes
+			w.Header().Add("Vary", "Origin")
+		}
+		if permitido {
+			if politica == nil || politica.todos {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origen)
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Request-ID, X-API-Key")
+			w.Header().Set("Access-Control-Expose-Headers", cabeceraIDPeticion)
+		}
+
+		if r.Method == http.MethodOptions {
+			if !permitido {
+				writeError(w, r, errOrigenNoPermitido)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		siguiente.ServeHTTP(w, r)
+	})
+}
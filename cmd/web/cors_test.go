@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// configurarCORS activa durante la prueba la política CORS_ALLOWED_ORIGINS indicada
+func configurarCORS(t *testing.T, lista string) {
+	t.Helper()
+	anterior := corsPermitidos.Load()
+	corsPermitidos.Store(cargarPoliticaCORS(lista))
+	t.Cleanup(func() { corsPermitidos.Store(anterior) })
+}
+
+func TestAplicarCORS(t *testing.T) {
+	casos := []struct {
+		nombre      string
+		lista       string
+		metodo      string
+		origen      string
+		estado      int
+		permitirA   string
+		variaOrigen bool
+	}{
+		{"comodín por defecto", "", http.MethodGet, "https://cualquiera.ejemplo.com", http.StatusOK, "*", false},
+		{"comodín explícito", "*", http.MethodGet, "https://cualquiera.ejemplo.com", http.StatusOK, "*", false},
+		{"origen permitido", "https://app.ejemplo.ec", http.MethodGet, "https://app.ejemplo.ec", http.StatusOK, "https://app.ejemplo.ec", true},
+		{"origen no permitido", "https://app.ejemplo.ec", http.MethodGet, "https://malicioso.ejemplo.com", http.StatusOK, "", true},
+		{"preflight con comodín", "*", http.MethodOptions, "https://cualquiera.ejemplo.com", http.StatusNoContent, "*", false},
+		{"preflight permitido", "https://app.ejemplo.ec", http.MethodOptions, "https://app.ejemplo.ec", http.StatusNoContent, "https://app.ejemplo.ec", true},
+		{"preflight no permitido", "https://app.ejemplo.ec", http.MethodOptions, "https://malicioso.ejemplo.com", http.StatusForbidden, "", true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			configurarCORS(t, caso.lista)
+
+			req := httptest.NewRequest(caso.metodo, "/api/validar?cedula=1710034065", nil)
+			req.Header.Set("Origin", caso.origen)
+			rec := atenderCon(aplicarCORS, req)
+
+			if rec.Code != caso.estado {
+				t.Errorf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != caso.permitirA {
+				t.Errorf("Access-Control-Allow-Origin = %q, se esperaba %q", got, caso.permitirA)
+			}
+			if got := rec.Header().Get("Vary") == "Origin"; got != caso.variaOrigen {
+				t.Errorf("Vary: Origin = %v, se esperaba %v", got, caso.variaOrigen)
+			}
+			if caso.permitirA != "" && rec.Header().Get("Access-Control-Allow-Methods") == "" {
+				t.Error("falta Access-Control-Allow-Methods en un origen permitido")
+			}
+			if caso.permitirA == "" && rec.Header().Get("Access-Control-Allow-Methods") != "" {
+				t.Error("no se deben enviar headers CORS a un origen no permitido")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// limiteCuerpoPorDefecto es el tamaño máximo del cuerpo de las peticiones si no se configura MAX_BODY_BYTES
+const limiteCuerpoPorDefecto = 1 << 20
+
+// limiteCuerpo es el tamaño máximo en bytes del cuerpo JSON de las peticiones (MAX_BODY_BYTES)
+var limiteCuerpo int64 = limiteCuerpoPorDefecto
+
+// errCuerpoDemasiadoGrande se devuelve cuando el cuerpo de la petición supera limiteCuerpo
+var errCuerpoDemasiadoGrande = &errorAPI{codigo: CodigoCuerpoDemasiadoGrande, estado: http.StatusRequestEntityTooLarge, mensaje: "El cuerpo de la petición es demasiado grande"}
+
+// decodificarJSON decodifica el cuerpo JSON de la petición en destino, leyendo como máximo
+// limiteCuerpo bytes y rechazando los campos que destino no conoce
+func decodificarJSON(w http.ResponseWriter, r *http.Request, destino interface{}) error {
+	decodificador := json.NewDecoder(http.MaxBytesReader(w, r.Body, limiteCuerpo))
+	decodificador.DisallowUnknownFields()
+	err := decodificador.Decode(destino)
+	if err == nil {
+		return nil
+	}
+
+	var demasiadoGrande *http.MaxBytesError
+	if errors.As(err, &demasiadoGrande) {
+		return errCuerpoDemasiadoGrande
+	}
+	// encoding/json no tiene un tipo de error para los campos desconocidos
+	if campo, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		campo = strings.Trim(campo, `"`)
+		var validacion ValidationError
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s no está permitido", campo))
+		return validacion.Err()
+	}
+	return errJSONInvalido
+}
+
+// esTextoPlano indica si el cuerpo de la petición se envió como text/plain
+func esTextoPlano(r *http.Request) bool {
+	tipo, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && tipo == "text/plain"
+}
+
+// leerLineas lee un cuerpo text/plain, con el mismo límite de tamaño que el JSON, y devuelve
+// sus líneas sin espacios alrededor, omitiendo las vacías
+func leerLineas(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	cuerpo, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limiteCuerpo))
+	if err != nil {
+		var demasiadoGrande *http.MaxBytesError
+		if errors.As(err, &demasiadoGrande) {
+			return nil, errCuerpoDemasiadoGrande
+		}
+		return nil, err
+	}
+
+	var lineas []string
+	for _, linea := range strings.Split(string(cuerpo), "\n") {
+		if linea = strings.TrimSpace(linea); linea != "" {
+			lineas = append(lineas, linea)
+		}
+	}
+	return lineas, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// manejadoresConCuerpo son los endpoints que leen un cuerpo JSON con decodificarJSON
+var manejadoresConCuerpo = map[string]http.HandlerFunc{
+	"/api/consultar":         manejarConsulta,
+	"/api/consultar-nombres": manejarConsultaPorNombres,
+	"/api/consultar-lote":    manejarConsultaLote,
+}
+
+// enviarCuerpo envía cuerpo por POST al manejador y decodifica el ErrorResponse de la respuesta
+func enviarCuerpo(t *testing.T, ruta string, manejador http.HandlerFunc, cuerpo string) (*httptest.ResponseRecorder, ErrorResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, ruta, strings.NewReader(cuerpo))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	manejador.ServeHTTP(rec, req)
+
+	var respuesta ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatalf("respuesta no es JSON: %v\n%s", err, rec.Body.String())
+	}
+	return rec, respuesta
+}
+
+func TestCuerpoDemasiadoGrande(t *testing.T) {
+	anterior := limiteCuerpo
+	limiteCuerpo = 64
+	t.Cleanup(func() { limiteCuerpo = anterior })
+
+	cuerpo := `{"cedula":"` + strings.Repeat("1", 100) + `"}`
+	for ruta, manejador := range manejadoresConCuerpo {
+		t.Run(ruta, func(t *testing.T) {
+			rec, respuesta := enviarCuerpo(t, ruta, manejador, cuerpo)
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("estado = %d, se esperaba 413", rec.Code)
+			}
+			if respuesta.Code != CodigoCuerpoDemasiadoGrande {
+				t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoCuerpoDemasiadoGrande)
+			}
+		})
+	}
+}
+
+func TestCuerpoConCamposDesconocidos(t *testing.T) {
+	cuerpos := map[string]string{
+		"/api/consultar":         `{"cedula":"1710034065","extra":true}`,
+		"/api/consultar-nombres": `{"nombres":"JUAN","apellidos":"PEREZ","extra":true}`,
+		"/api/consultar-lote":    `{"cedulas":["1710034065"],"extra":true}`,
+	}
+	for ruta, manejador := range manejadoresConCuerpo {
+		t.Run(ruta, func(t *testing.T) {
+			rec, respuesta := enviarCuerpo(t, ruta, manejador, cuerpos[ruta])
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("estado = %d, se esperaba 400", rec.Code)
+			}
+			if respuesta.Code != CodigoValidacion {
+				t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoValidacion)
+			}
+			if len(respuesta.Campos) != 1 || respuesta.Campos[0].Campo != "extra" {
+				t.Errorf("campos = %+v, se esperaba el campo extra", respuesta.Campos)
+			}
+		})
+	}
+}
+
+func TestCuerpoJSONInvalido(t *testing.T) {
+	rec, respuesta := enviarCuerpo(t, "/api/consultar", manejarConsulta, `{"cedula":`)
+	if rec.Code != http.StatusBadRequest || respuesta.Code != CodigoJSONInvalido {
+		t.Errorf("estado = %d, código = %s; se esperaba 400 %s", rec.Code, respuesta.Code, CodigoJSONInvalido)
+	}
+}
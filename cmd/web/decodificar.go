@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// rutaDecodificar es el prefijo del endpoint de decodificación; la identificación va a continuación
+const rutaDecodificar = "/api/decodificar/"
+
+// DecodificacionResponse es la respuesta de /api/decodificar/{cedula}
+type DecodificacionResponse struct {
+	XMLName xml.Name `json:"-" xml:"decodificacionResponse"`
+	Valida  bool     `json:"valid" xml:"valid"`
+	// CodigoProvincia son los dos primeros dígitos; Provincia queda vacía si el código no existe
+	CodigoProvincia         string `json:"province" xml:"province"`
+	Provincia               string `json:"provinceName" xml:"provinceName"`
+	TipoPersona             string `json:"personaType" xml:"personaType"`
+	DigitoVerificadorValido bool   `json:"checkDigitValid" xml:"checkDigitValid"`
+}
+
+// decodificarIdentificacion obtiene localmente todo lo que se puede saber de una cédula o RUC
+// ya normalizados
+func decodificarIdentificacion(identificacion string, normalizada bool) DecodificacionResponse {
+	var respuesta DecodificacionResponse
+	if len(identificacion) == 13 {
+		respuesta.Valida = cedula.ValidateRUC(identificacion)
+	} else {
+		respuesta.Valida = normalizada && cedula.ValidateCedula(identificacion)
+	}
+	if len(identificacion) >= 2 {
+		respuesta.CodigoProvincia = identificacion[:2]
+	}
+	respuesta.Provincia, _ = cedula.Province(identificacion)
+	respuesta.TipoPersona = cedula.PersonType(identificacion)
+	respuesta.DigitoVerificadorValido = cedula.CheckDigitValid(identificacion)
+	return respuesta
+}
+
+// manejarDecodificacion maneja las peticiones GET a /api/decodificar/{cedula}: decodifica sin
+// consultar el SRI la provincia, el tipo de persona y el dígito verificador. Como en
+// /api/validar, una identificación inválida se responde con 200 y valid=false.
+func manejarDecodificacion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, errMetodoNoPermitido)
+		return
+	}
+	valor := strings.TrimPrefix(r.URL.Path, rutaDecodificar)
+	if valor == "" || strings.Contains(valor, "/") {
+		var validacion ValidationError
+		validacion.Agregar("cedula", "La ruta debe ser /api/decodificar/{cedula}")
+		writeError(w, r, validacion.Err())
+		return
+	}
+
+	identificacion, ok := cedula.Normalize(valor)
+	escribirRespuesta(w, r, http.StatusOK, decodificarIdentificacion(identificacion, ok))
+}
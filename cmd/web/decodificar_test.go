@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestManejarDecodificacion(t *testing.T) {
+	casos := []struct {
+		nombre string
+		cedula string
+		espera DecodificacionResponse
+	}{
+		{"válida", "1710034065", DecodificacionResponse{Valida: true, CodigoProvincia: "17", Provincia: "Pichincha", TipoPersona: "N", DigitoVerificadorValido: true}},
+		{"verificador inválido", "1710034064", DecodificacionResponse{CodigoProvincia: "17", Provincia: "Pichincha", TipoPersona: "N"}},
+		{"provincia inexistente", "2501010108", DecodificacionResponse{CodigoProvincia: "25", TipoPersona: "N", DigitoVerificadorValido: true}},
+		{"RUC de sociedad", "1790000001001", DecodificacionResponse{Valida: true, CodigoProvincia: "17", Provincia: "Pichincha", TipoPersona: "J", DigitoVerificadorValido: true}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			manejarDecodificacion(rec, httptest.NewRequest(http.MethodGet, rutaDecodificar+caso.cedula, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("estado = %d", rec.Code)
+			}
+			var respuesta DecodificacionResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta != caso.espera {
+				t.Errorf("respuesta = %+v, se esperaba %+v", respuesta, caso.espera)
+			}
+		})
+	}
+}
+
+func TestManejarDecodificacionSinCedula(t *testing.T) {
+	rec := httptest.NewRecorder()
+	manejarDecodificacion(rec, httptest.NewRequest(http.MethodGet, rutaDecodificar, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("estado = %d, se esperaba 400", rec.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+	"consulta-cedula-app/pkg/cedulapb"
+)
+
+// CodigoError es el código estable de un error de la API. A diferencia del mensaje, no cambia
+// de redacción, así que los clientes pueden compararlo para decidir qué hacer.
+type CodigoError string
+
+// Catálogo de códigos de error de la API
+const (
+	CodigoMetodoNoPermitido     CodigoError = "METHOD_NOT_ALLOWED"
+	CodigoJSONInvalido          CodigoError = "INVALID_JSON"
+	CodigoCuerpoDemasiadoGrande CodigoError = "PAYLOAD_TOO_LARGE"
+	CodigoValidacion            CodigoError = "VALIDATION_ERROR"
+	CodigoCedulaInvalida        CodigoError = "INVALID_CEDULA"
+	CodigoRUCInvalido           CodigoError = "INVALID_RUC"
+	CodigoEstablecimientoRUC    CodigoError = "INVALID_RUC_ESTABLISHMENT"
+	CodigoCedulaSospechosa      CodigoError = "SUSPICIOUS_CEDULA"
+	CodigoFormatoNombreInvalido CodigoError = "INVALID_NAME_FORMAT"
+	CodigoNombreInvalido        CodigoError = "INVALID_NAME"
+	CodigoBusquedaGeneral       CodigoError = "QUERY_TOO_BROAD"
+	CodigoLoteDemasiadoGrande   CodigoError = "BATCH_TOO_LARGE"
+	CodigoNoEncontrada          CodigoError = "NOT_FOUND"
+	CodigoNoAutorizado          CodigoError = "UNAUTHORIZED"
+	CodigoAccesoDenegado        CodigoError = "FORBIDDEN"
+	CodigoOrigenNoPermitido     CodigoError = "ORIGIN_NOT_ALLOWED"
+	CodigoDemasiadasPeticiones  CodigoError = "RATE_LIMITED"
+	CodigoCuotaAgotada          CodigoError = "DAILY_BUDGET_EXHAUSTED"
+	CodigoMantenimiento         CodigoError = "MAINTENANCE"
+	CodigoErrorSRI              CodigoError = "UPSTREAM_ERROR"
+	CodigoSRIInalcanzable       CodigoError = "SRI_UNREACHABLE"
+	CodigoSRINoDisponible       CodigoError = "UPSTREAM_UNAVAILABLE"
+	CodigoTiempoAgotado         CodigoError = "TIMEOUT"
+	CodigoCancelada             CodigoError = "CLIENT_CLOSED_REQUEST"
+	CodigoInterno               CodigoError = "INTERNAL_ERROR"
+)
+
+// catalogoCodigos lista todos los códigos de error, en el orden del catálogo (para el documento OpenAPI)
+var catalogoCodigos = []CodigoError{
+	CodigoMetodoNoPermitido,
+	CodigoJSONInvalido,
+	CodigoCuerpoDemasiadoGrande,
+	CodigoValidacion,
+	CodigoCedulaInvalida,
+	CodigoRUCInvalido,
+	CodigoEstablecimientoRUC,
+	CodigoCedulaSospechosa,
+	CodigoFormatoNombreInvalido,
+	CodigoNombreInvalido,
+	CodigoBusquedaGeneral,
+	CodigoLoteDemasiadoGrande,
+	CodigoNoEncontrada,
+	CodigoNoAutorizado,
+	CodigoAccesoDenegado,
+	CodigoOrigenNoPermitido,
+	CodigoDemasiadasPeticiones,
+	CodigoCuotaAgotada,
+	CodigoMantenimiento,
+	CodigoErrorSRI,
+	CodigoSRIInalcanzable,
+	CodigoSRINoDisponible,
+	CodigoTiempoAgotado,
+	CodigoCancelada,
+	CodigoInterno,
+}
+
+// errorAPI es un error que conoce su código estable, su estado HTTP y el mensaje para el cliente
+type errorAPI struct {
+	codigo  CodigoError
+	estado  int
+	mensaje string
+}
+
+func (e *errorAPI) Error() string {
+	return e.mensaje
+}
+
+// Errores conocidos que pueden devolver los endpoints de la API
+var (
+	errMetodoNoPermitido = &errorAPI{codigo: CodigoMetodoNoPermitido, estado: http.StatusMethodNotAllowed, mensaje: "Método no permitido"}
+	errJSONInvalido      = &errorAPI{codigo: CodigoJSONInvalido, estado: http.StatusBadRequest, mensaje: "JSON inválido"}
+	errCedulaInvalida    = &errorAPI{codigo: CodigoCedulaInvalida, estado: http.StatusBadRequest, mensaje: "Cédula inválida. Debe contener 10 dígitos con provincia y dígito verificador válidos"}
+	errRUCInvalido       = &errorAPI{codigo: CodigoRUCInvalido, estado: http.StatusBadRequest, mensaje: "RUC inválido. Debe contener 13 dígitos con dígito verificador y establecimiento válidos"}
+	errEstablecimiento   = &errorAPI{codigo: CodigoEstablecimientoRUC, estado: http.StatusBadRequest, mensaje: "RUC inválido. El código de establecimiento (últimos dígitos) debe ser 001 o mayor"}
+	errNoEncontrada      = &errorAPI{codigo: CodigoNoEncontrada, estado: http.StatusNotFound, mensaje: "Cédula no encontrada"}
+	errErrorSRI          = &errorAPI{codigo: CodigoErrorSRI, estado: http.StatusBadGateway, mensaje: "Error al consultar el SRI. Intente nuevamente más tarde"}
+	errSRINoDisponible   = &errorAPI{codigo: CodigoSRINoDisponible, estado: http.StatusServiceUnavailable, mensaje: "El SRI no está disponible en este momento. Intente nuevamente más tarde"}
+	errTiempoAgotado     = &errorAPI{codigo: CodigoTiempoAgotado, estado: http.StatusGatewayTimeout, mensaje: "La consulta superó el tiempo máximo. Intente nuevamente más tarde"}
+	errCancelada         = &errorAPI{codigo: CodigoCancelada, estado: estadoClienteCancelo, mensaje: "El cliente canceló la petición"}
+	errInterno           = &errorAPI{codigo: CodigoInterno, estado: http.StatusInternalServerError, mensaje: "Error interno del servidor al consultar"}
+)
+
+// estadoClienteCancelo es el estado (no estándar, el mismo que usa nginx) de las peticiones que el
+// cliente canceló antes de recibir la respuesta; solo llega a los logs y a las métricas
+const estadoClienteCancelo = 499
+
+// ErrorCampo describe el problema de validación de un campo concreto de la petición
+type ErrorCampo struct {
+	Campo   string `json:"campo" xml:"campo,attr"`
+	Mensaje string `json:"mensaje" xml:",chardata"`
+}
+
+// ValidationError acumula los errores de validación de varios campos de una petición
+type ValidationError struct {
+	Campos []ErrorCampo
+	// Codigo reemplaza a CodigoValidacion cuando algún problema tiene un código más específico
+	Codigo CodigoError
+}
+
+// Agregar registra un problema de validación para un campo
+func (e *ValidationError) Agregar(campo, mensaje string) {
+	e.Campos = append(e.Campos, ErrorCampo{Campo: campo, Mensaje: mensaje})
+}
+
+// Err devuelve el error acumulado, o nil si no se registró ningún problema
+func (e *ValidationError) Err() error {
+	if len(e.Campos) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	mensajes := make([]string, len(e.Campos))
+	for i, campo := range e.Campos {
+		mensajes[i] = campo.Mensaje
+	}
+	return strings.Join(mensajes, ". ")
+}
+
+// comoErrorAPI obtiene el errorAPI contenido en err; cualquier otro error se trata como interno
+func comoErrorAPI(err error) *errorAPI {
+	var apiErr *errorAPI
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if errors.Is(err, cedula.ErrNotFound) {
+		return errNoEncontrada
+	}
+	if errors.Is(err, cedula.ErrUnavailable) {
+		return errSRINoDisponible
+	}
+	if errors.Is(err, cedula.ErrUpstream) {
+		return errErrorSRI
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errTiempoAgotado
+	}
+	if errors.Is(err, context.Canceled) {
+		return errCancelada
+	}
+	var validacion *ValidationError
+	if errors.As(err, &validacion) {
+		codigo := CodigoValidacion
+		if validacion.Codigo != "" {
+			codigo = validacion.Codigo
+		}
+		return &errorAPI{codigo: codigo, estado: http.StatusBadRequest, mensaje: validacion.Error()}
+	}
+	return errInterno
+}
+
+// statusForError devuelve el código de estado HTTP correspondiente a un error
+func statusForError(err error) int {
+	return comoErrorAPI(err).estado
+}
+
+// longitudMaximaMensaje acota el largo de los mensajes de error que se envían al cliente
+const longitudMaximaMensaje = 300
+
+// patronURL detecta URLs dentro de un mensaje para que nunca lleguen al cliente
+var patronURL = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://\S+`)
+
+// sanitizarMensaje quita las URLs de un mensaje para el cliente y lo recorta a longitudMaximaMensaje
+func sanitizarMensaje(mensaje string) string {
+	mensaje = patronURL.ReplaceAllString(mensaje, "[url]")
+	if runas := []rune(mensaje); len(runas) > longitudMaximaMensaje {
+		mensaje = string(runas[:longitudMaximaMensaje]) + "…"
+	}
+	return mensaje
+}
+
+// writeError escribe la respuesta de error estándar de la API para err. Al cliente solo le llega
+// el mensaje del catálogo; el detalle de los errores no catalogados queda únicamente en los logs.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := comoErrorAPI(err)
+	if apiErr == errInterno && err != errInterno {
+		cedula.LoggerFrom(r.Context()).Error("Error interno", "metodo", r.Method, "ruta", r.URL.Path, "error", err)
+	}
+
+	respuesta := ErrorResponse{
+		Error:     sanitizarMensaje(apiErr.mensaje),
+		Code:      apiErr.codigo,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		RequestID: w.Header().Get(cabeceraIDPeticion),
+	}
+	var validacion *ValidationError
+	if errors.As(err, &validacion) {
+		for _, campo := range validacion.Campos {
+			respuesta.Campos = append(respuesta.Campos, ErrorCampo{Campo: campo.Campo, Mensaje: sanitizarMensaje(campo.Mensaje)})
+		}
+	}
+
+	if aceptaProtobuf(r) {
+		escribirProtobuf(w, statusForError(err), &cedulapb.ErrorResponse{
+			Error:     respuesta.Error,
+			Code:      string(respuesta.Code),
+			Timestamp: respuesta.Timestamp,
+			Campos:    camposAProto(respuesta.Campos),
+			RequestId: respuesta.RequestID,
+		})
+		return
+	}
+
+	escribirRespuesta(w, r, statusForError(err), respuesta)
+}
+
+// camposAProto convierte los errores de validación por campo a sus mensajes protobuf
+func camposAProto(campos []ErrorCampo) []*cedulapb.ErrorCampo {
+	var mensajes []*cedulapb.ErrorCampo
+	for _, campo := range campos {
+		mensajes = append(mensajes, &cedulapb.ErrorCampo{Campo: campo.Campo, Mensaje: campo.Mensaje})
+	}
+	return mensajes
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// exigirNombresEspecificos indica si se rechazan las búsquedas por nombres demasiado generales.
+// Se configura con REQUIRE_SPECIFIC_NAMES.
+var exigirNombresEspecificos atomic.Bool
+
+// errBusquedaGeneral se devuelve cuando la búsqueda por nombres es demasiado amplia para ser útil
+var errBusquedaGeneral = &errorAPI{
+	codigo:  CodigoBusquedaGeneral,
+	estado:  http.StatusBadRequest,
+	mensaje: "La búsqueda es demasiado general. Incluya el segundo nombre o el segundo apellido",
+}
+
+// apellidosComunes son apellidos muy frecuentes en Ecuador que por sí solos no identifican a nadie
+var apellidosComunes = map[string]bool{
+	"ZAMBRANO": true, "MENDOZA": true, "CEDEÑO": true, "CEDENO": true, "VERA": true,
+	"TORRES": true, "SANCHEZ": true, "SÁNCHEZ": true, "GARCIA": true, "GARCÍA": true,
+	"LOPEZ": true, "LÓPEZ": true, "RODRIGUEZ": true, "RODRÍGUEZ": true, "PEREZ": true,
+	"PÉREZ": true, "GONZALEZ": true, "GONZÁLEZ": true, "MORENO": true, "CASTILLO": true,
+	"JIMENEZ": true, "JIMÉNEZ": true, "RAMIREZ": true, "RAMÍREZ": true, "ORTIZ": true,
+	"CHAVEZ": true, "CHÁVEZ": true, "MORALES": true, "VARGAS": true, "HERRERA": true,
+	"ALVARADO": true, "FLORES": true, "REYES": true, "RUIZ": true, "ROMERO": true,
+}
+
+// busquedaDemasiadoGeneral indica si la combinación de nombres y apellidos es tan común que
+// devolvería demasiados resultados: un solo nombre junto a un solo apellido frecuente
+func busquedaDemasiadoGeneral(nombres, apellidos string) bool {
+	palabrasNombres := strings.Fields(strings.ToUpper(nombres))
+	palabrasApellidos := strings.Fields(strings.ToUpper(apellidos))
+
+	if len(palabrasNombres) > 1 || len(palabrasApellidos) > 1 {
+		return false
+	}
+	return len(palabrasApellidos) == 1 && apellidosComunes[palabrasApellidos[0]]
+}
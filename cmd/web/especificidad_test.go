@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// activarNombresEspecificos fija REQUIRE_SPECIFIC_NAMES durante la prueba
+func activarNombresEspecificos(t *testing.T, activo bool) {
+	t.Helper()
+	anterior := exigirNombresEspecificos.Load()
+	exigirNombresEspecificos.Store(activo)
+	t.Cleanup(func() { exigirNombresEspecificos.Store(anterior) })
+}
+
+func TestBusquedaDemasiadoGeneral(t *testing.T) {
+	casos := []struct {
+		nombres, apellidos string
+		general            bool
+	}{
+		{"Juan", "Pérez", true},
+		{"MARIA", "zambrano", true},
+		{"  Luis ", " CEDEÑO ", true},
+		{"Juan Carlos", "Pérez", false},
+		{"Juan", "Pérez López", false},
+		{"Juan", "Quishpe", false},
+		{"Juan", "", false},
+	}
+	for _, caso := range casos {
+		if got := busquedaDemasiadoGeneral(caso.nombres, caso.apellidos); got != caso.general {
+			t.Errorf("busquedaDemasiadoGeneral(%q, %q) = %v, se esperaba %v", caso.nombres, caso.apellidos, got, caso.general)
+		}
+	}
+}
+
+// buscarPorNombres envía una búsqueda en modo dryRun, para no esperar a la fuente de nombres
+func buscarPorNombres(t *testing.T, nombres, apellidos string) *httptest.ResponseRecorder {
+	t.Helper()
+	cuerpo, _ := json.Marshal(NombresRequest{Nombres: nombres, Apellidos: apellidos})
+	req := httptest.NewRequest(http.MethodPost, "/api/consultar-nombres?dryRun=true", bytes.NewReader(cuerpo))
+	rec := httptest.NewRecorder()
+	manejarConsultaPorNombres(rec, req)
+	return rec
+}
+
+func TestConsultaPorNombresRechazaNombresComunes(t *testing.T) {
+	activarNombresEspecificos(t, true)
+
+	rec := buscarPorNombres(t, "Juan", "Pérez")
+	var respuesta ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest || respuesta.Code != CodigoBusquedaGeneral {
+		t.Errorf("respuesta = %d %s, se esperaba 400 %s", rec.Code, respuesta.Code, CodigoBusquedaGeneral)
+	}
+
+	if rec := buscarPorNombres(t, "Juan Carlos", "Pérez"); rec.Code != http.StatusOK {
+		t.Errorf("una búsqueda específica: estado = %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestConsultaPorNombresComunesSinExigirEspecificos(t *testing.T) {
+	activarNombresEspecificos(t, false)
+
+	if rec := buscarPorNombres(t, "Juan", "Pérez"); rec.Code != http.StatusOK {
+		t.Errorf("sin REQUIRE_SPECIFIC_NAMES: estado = %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// claveFirma es la clave con la que se firman las respuestas cuando SIGN_RESPONSES está activo
+var claveFirma ed25519.PrivateKey
+
+// cargarClaveFirma obtiene la clave de firma desde una semilla Ed25519 en base64 (SIGNING_KEY_SEED).
+// Sin semilla se genera una clave efímera que cambia en cada reinicio.
+func cargarClaveFirma(semillaBase64 string) (ed25519.PrivateKey, error) {
+	if semillaBase64 == "" {
+		_, clave, err := ed25519.GenerateKey(rand.Reader)
+		return clave, err
+	}
+
+	semilla, err := base64.StdEncoding.DecodeString(semillaBase64)
+	if err != nil {
+		return nil, fmt.Errorf("semilla de firma inválida: %v", err)
+	}
+	if len(semilla) != ed25519.SeedSize {
+		return nil, fmt.Errorf("semilla de firma inválida: se esperaban %d bytes", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(semilla), nil
+}
+
+// respuestaEnBuffer retiene el estado y el cuerpo de la respuesta para poder firmarlos
+type respuestaEnBuffer struct {
+	http.ResponseWriter
+	estado int
+	cuerpo bytes.Buffer
+}
+
+func (b *respuestaEnBuffer) WriteHeader(estado int) {
+	b.estado = estado
+}
+
+func (b *respuestaEnBuffer) Write(datos []byte) (int, error) {
+	return b.cuerpo.Write(datos)
+}
+
+// firmarRespuestas agrega el header X-Response-Signature con la firma Ed25519 (en base64) del cuerpo
+func firmarRespuestas(clave ed25519.PrivateKey, siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buffer := &respuestaEnBuffer{ResponseWriter: w, estado: http.StatusOK}
+		siguiente.ServeHTTP(buffer, r)
+
+		firma := ed25519.Sign(clave, buffer.cuerpo.Bytes())
+		w.Header().Set("X-Response-Signature", base64.StdEncoding.EncodeToString(firma))
+		w.WriteHeader(buffer.estado)
+		w.Write(buffer.cuerpo.Bytes())
+	})
+}
+
+// manejarClavePublica publica en /.well-known/pubkey la clave pública para verificar las firmas
+func manejarClavePublica(clave ed25519.PrivateKey) http.HandlerFunc {
+	publica := clave.Public().(ed25519.PublicKey)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"algoritmo":    "Ed25519",
+			"clavePublica": base64.StdEncoding.EncodeToString(publica),
+		})
+	}
+}
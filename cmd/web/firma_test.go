@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFirmarRespuestasVerificaConLaClavePublica(t *testing.T) {
+	semilla := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	clave, err := cargarClaveFirma(semilla)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// La clave pública se obtiene como la obtendría un cliente, desde /.well-known/pubkey
+	rec := httptest.NewRecorder()
+	manejarClavePublica(clave)(rec, httptest.NewRequest(http.MethodGet, "/.well-known/pubkey", nil))
+	var publicada map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &publicada); err != nil {
+		t.Fatal(err)
+	}
+	publica, err := base64.StdEncoding.DecodeString(publicada["clavePublica"])
+	if err != nil || len(publica) != ed25519.PublicKeySize || publicada["algoritmo"] != "Ed25519" {
+		t.Fatalf("clave publicada = %v (%v)", publicada, err)
+	}
+
+	rec = httptest.NewRecorder()
+	firmarRespuestas(clave, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"no encontrada"}`))
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("estado = %d, la firma debería conservar el estado original", rec.Code)
+	}
+	firma, err := base64.StdEncoding.DecodeString(rec.Header().Get("X-Response-Signature"))
+	if err != nil {
+		t.Fatalf("X-Response-Signature inválida: %v", err)
+	}
+	if !ed25519.Verify(publica, rec.Body.Bytes(), firma) {
+		t.Error("la firma no verifica con la clave pública publicada")
+	}
+	if ed25519.Verify(publica, append(rec.Body.Bytes(), ' '), firma) {
+		t.Error("la firma no debería verificar un cuerpo modificado")
+	}
+}
+
+func TestCargarClaveFirma(t *testing.T) {
+	semilla := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	primera, err := cargarClaveFirma(semilla)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segunda, _ := cargarClaveFirma(semilla)
+	if !primera.Equal(segunda) {
+		t.Error("la misma semilla debería dar la misma clave")
+	}
+
+	for _, invalida := range []string{"no es base64!", base64.StdEncoding.EncodeToString([]byte("corta"))} {
+		if _, err := cargarClaveFirma(invalida); err == nil {
+			t.Errorf("cargarClaveFirma(%q) debería fallar", invalida)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// capacidadMuestrasLatencia es la cantidad máxima de muestras que se guardan por fuente
+const capacidadMuestrasLatencia = 1024
+
+// muestrasLatencia guarda las últimas latencias de una fuente en un buffer circular acotado
+type muestrasLatencia struct {
+	mu        sync.Mutex
+	valores   []time.Duration
+	siguiente int
+}
+
+// registrar agrega una latencia, reemplazando la más antigua cuando el buffer está lleno
+func (m *muestrasLatencia) registrar(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.valores) < capacidadMuestrasLatencia {
+		m.valores = append(m.valores, d)
+		return
+	}
+	m.valores[m.siguiente] = d
+	m.siguiente = (m.siguiente + 1) % capacidadMuestrasLatencia
+}
+
+// EstadisticasLatencia resume las latencias de una fuente en milisegundos
+type EstadisticasLatencia struct {
+	Muestras int     `json:"muestras"`
+	P50Ms    float64 `json:"p50Ms"`
+	P95Ms    float64 `json:"p95Ms"`
+	P99Ms    float64 `json:"p99Ms"`
+}
+
+// estadisticas calcula los percentiles 50, 95 y 99 de las muestras actuales
+func (m *muestrasLatencia) estadisticas() EstadisticasLatencia {
+	m.mu.Lock()
+	ordenados := append([]time.Duration(nil), m.valores...)
+	m.mu.Unlock()
+
+	sort.Slice(ordenados, func(i, j int) bool { return ordenados[i] < ordenados[j] })
+	return EstadisticasLatencia{
+		Muestras: len(ordenados),
+		P50Ms:    percentil(ordenados, 50),
+		P95Ms:    percentil(ordenados, 95),
+		P99Ms:    percentil(ordenados, 99),
+	}
+}
+
+// percentil calcula el percentil p (método del rango más cercano) de latencias ordenadas, en milisegundos
+func percentil(ordenados []time.Duration, p float64) float64 {
+	if len(ordenados) == 0 {
+		return 0
+	}
+	rango := int(math.Ceil(p / 100 * float64(len(ordenados))))
+	if rango < 1 {
+		rango = 1
+	}
+	return float64(ordenados[rango-1]) / float64(time.Millisecond)
+}
+
+// latencias guarda las muestras de latencia por fuente consultada
+var latencias = map[string]*muestrasLatencia{
+	"sri":     {},
+	"nombres": {},
+}
+
+// manejarEstadisticasLatencia responde en /stats/latency con los percentiles de cada fuente
+func manejarEstadisticasLatencia(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, r, errMetodoNoPermitido)
+		return
+	}
+
+	respuesta := make(map[string]EstadisticasLatencia, len(latencias))
+	for fuente, muestras := range latencias {
+		respuesta[fuente] = muestras.estadisticas()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(respuesta)
+}
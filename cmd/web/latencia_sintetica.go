@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// latenciaSintetica describe el retardo artificial que se agrega a las respuestas para que los
+// integradores prueben la resiliencia de sus clientes. Solo se activa fuera de producción.
+type latenciaSintetica struct {
+	retardo    time.Duration
+	porcentaje int
+	cedulas    map[string]bool
+}
+
+// inyeccionLatencia es la configuración activa; nil si la inyección está desactivada
+var inyeccionLatencia atomic.Pointer[latenciaSintetica]
+
+// cargarLatenciaSintetica lee la configuración de ENABLE_LATENCY_INJECTION, LATENCY_INJECTION_MS,
+// LATENCY_INJECTION_PERCENT y LATENCY_INJECTION_CEDULAS. Se niega a activarse con APP_ENV=production.
+func cargarLatenciaSintetica() *latenciaSintetica {
+	if !leerBoolEnv("ENABLE_LATENCY_INJECTION", false) {
+		return nil
+	}
+	if os.Getenv("APP_ENV") == "production" {
+		slog.Warn("La inyección de latencia no está permitida con APP_ENV=production")
+		return nil
+	}
+
+	milisegundos, err := strconv.Atoi(os.Getenv("LATENCY_INJECTION_MS"))
+	if err != nil || milisegundos <= 0 {
+		slog.Warn("LATENCY_INJECTION_MS inválido; la inyección de latencia no se habilitó")
+		return nil
+	}
+
+	config := &latenciaSintetica{
+		retardo:    time.Duration(milisegundos) * time.Millisecond,
+		porcentaje: 100,
+	}
+	if valor := os.Getenv("LATENCY_INJECTION_PERCENT"); valor != "" {
+		porcentaje, err := strconv.Atoi(valor)
+		if err != nil || porcentaje < 0 || porcentaje > 100 {
+			slog.Warn("LATENCY_INJECTION_PERCENT inválido, usando 100", "valor", valor)
+		} else {
+			config.porcentaje = porcentaje
+		}
+	}
+	if cedulas := parsearListaEnv(os.Getenv("LATENCY_INJECTION_CEDULAS")); len(cedulas) > 0 {
+		config.cedulas = make(map[string]bool, len(cedulas))
+		for _, cedula := range cedulas {
+			config.cedulas[cedula] = true
+		}
+	}
+
+	slog.Info("Inyección de latencia sintética habilitada", "retardo", config.retardo.String(), "porcentaje", config.porcentaje)
+	return config
+}
+
+// aplica indica si la petición para la cédula dada debe recibir el retardo
+func (l *latenciaSintetica) aplica(cedula string) bool {
+	if l.cedulas != nil && !l.cedulas[cedula] {
+		return false
+	}
+	return l.porcentaje >= 100 || rand.Intn(100) < l.porcentaje
+}
+
+// inyectarLatencia espera el retardo configurado si corresponde a la cédula dada (vacía en las
+// consultas por nombres), terminando antes si el contexto se cancela
+func inyectarLatencia(ctx context.Context, cedula string) {
+	config := inyeccionLatencia.Load()
+	if config == nil || !config.aplica(cedula) {
+		return
+	}
+
+	temporizador := time.NewTimer(config.retardo)
+	defer temporizador.Stop()
+	select {
+	case <-temporizador.C:
+	case <-ctx.Done():
+	}
+}
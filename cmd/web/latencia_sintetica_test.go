@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// retardoPrueba es el retardo sintético que usan las pruebas: corto para no alargar la suite
+// pero medible frente al tiempo de una consulta contra el SRI simulado
+const retardoPrueba = 80 * time.Millisecond
+
+// configurarLatencia activa la configuración dada (nil la desactiva) y restaura la anterior al terminar
+func configurarLatencia(t *testing.T, config *latenciaSintetica) {
+	t.Helper()
+	anterior := inyeccionLatencia.Load()
+	inyeccionLatencia.Store(config)
+	t.Cleanup(func() { inyeccionLatencia.Store(anterior) })
+}
+
+// duracion mide cuánto tarda en ejecutarse f
+func duracion(f func()) time.Duration {
+	inicio := time.Now()
+	f()
+	return time.Since(inicio)
+}
+
+func TestInyectarLatenciaAplicaElRetardo(t *testing.T) {
+	configurarLatencia(t, &latenciaSintetica{retardo: retardoPrueba, porcentaje: 100})
+
+	if d := duracion(func() { inyectarLatencia(context.Background(), "1710034065") }); d < retardoPrueba {
+		t.Errorf("inyectarLatencia tardó %v, se esperaba al menos %v", d, retardoPrueba)
+	}
+	if d := duracion(func() { inyectarLatencia(context.Background(), "") }); d < retardoPrueba {
+		t.Errorf("sin lista de cédulas, la consulta por nombres tardó %v, se esperaba al menos %v", d, retardoPrueba)
+	}
+}
+
+func TestInyectarLatenciaSoloALasCedulasConfiguradas(t *testing.T) {
+	configurarLatencia(t, &latenciaSintetica{
+		retardo:    retardoPrueba,
+		porcentaje: 100,
+		cedulas:    map[string]bool{"1710034065": true},
+	})
+
+	casos := []struct {
+		nombre    string
+		cedula    string
+		retrasada bool
+	}{
+		{"cédula configurada", "1710034065", true},
+		{"otra cédula", "0912345675", false},
+		{"consulta por nombres", "", false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			d := duracion(func() { inyectarLatencia(context.Background(), caso.cedula) })
+			if caso.retrasada && d < retardoPrueba {
+				t.Errorf("tardó %v, se esperaba al menos %v", d, retardoPrueba)
+			}
+			if !caso.retrasada && d >= retardoPrueba {
+				t.Errorf("tardó %v, no debía recibir el retardo", d)
+			}
+		})
+	}
+}
+
+func TestInyectarLatenciaSinRetardo(t *testing.T) {
+	casos := []struct {
+		nombre string
+		config *latenciaSintetica
+	}{
+		{"desactivada", nil},
+		{"porcentaje cero", &latenciaSintetica{retardo: retardoPrueba, porcentaje: 0}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			configurarLatencia(t, caso.config)
+			for i := 0; i < 5; i++ {
+				if d := duracion(func() { inyectarLatencia(context.Background(), "1710034065") }); d >= retardoPrueba {
+					t.Fatalf("tardó %v, no debía recibir el retardo", d)
+				}
+			}
+		})
+	}
+}
+
+func TestInyectarLatenciaTerminaAlCancelarElContexto(t *testing.T) {
+	configurarLatencia(t, &latenciaSintetica{retardo: time.Minute, porcentaje: 100})
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelar()
+	if d := duracion(func() { inyectarLatencia(ctx, "1710034065") }); d >= time.Second {
+		t.Errorf("tardó %v, debía terminar al cancelarse el contexto", d)
+	}
+}
+
+func TestConsultaConLatenciaSintetica(t *testing.T) {
+	usarSRIPrueba(t)
+	configurarLatencia(t, &latenciaSintetica{
+		retardo:    retardoPrueba,
+		porcentaje: 100,
+		cedulas:    map[string]bool{"1710034065": true},
+	})
+
+	if d := duracionConsulta(t, "1710034065"); d < retardoPrueba {
+		t.Errorf("la consulta de la cédula configurada tardó %v, se esperaba al menos %v", d, retardoPrueba)
+	}
+	if d := duracionConsulta(t, "0912345675"); d >= retardoPrueba {
+		t.Errorf("la consulta de otra cédula tardó %v, no debía recibir el retardo", d)
+	}
+}
+
+// duracionConsulta mide cuánto tarda GET /api/consultar para la cédula dada y exige que responda 200
+func duracionConsulta(t *testing.T, numero string) time.Duration {
+	t.Helper()
+	var codigo int
+	d := duracion(func() {
+		rec, _, _ := consultarCedula(t, http.MethodGet, "/api/consultar?cedula="+numero, "")
+		codigo = rec.Code
+	})
+	if codigo != http.StatusOK {
+		t.Fatalf("la consulta de %s respondió %d, se esperaba 200", numero, codigo)
+	}
+	return d
+}
+
+func TestCargarLatenciaSintetica(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		env        map[string]string
+		activa     bool
+		retardo    time.Duration
+		porcentaje int
+		cedulas    []string
+	}{
+		{
+			nombre: "desactivada por defecto",
+			env:    map[string]string{"LATENCY_INJECTION_MS": "500"},
+		},
+		{
+			nombre: "rechazada en producción",
+			env:    map[string]string{"ENABLE_LATENCY_INJECTION": "true", "APP_ENV": "production", "LATENCY_INJECTION_MS": "500"},
+		},
+		{
+			nombre: "retardo inválido",
+			env:    map[string]string{"ENABLE_LATENCY_INJECTION": "true", "LATENCY_INJECTION_MS": "lento"},
+		},
+		{
+			nombre: "retardo cero",
+			env:    map[string]string{"ENABLE_LATENCY_INJECTION": "true", "LATENCY_INJECTION_MS": "0"},
+		},
+		{
+			nombre:     "solo retardo",
+			env:        map[string]string{"ENABLE_LATENCY_INJECTION": "true", "LATENCY_INJECTION_MS": "500"},
+			activa:     true,
+			retardo:    500 * time.Millisecond,
+			porcentaje: 100,
+		},
+		{
+			nombre: "porcentaje y cédulas",
+			env: map[string]string{
+				"ENABLE_LATENCY_INJECTION":  "true",
+				"LATENCY_INJECTION_MS":      "250",
+				"LATENCY_INJECTION_PERCENT": "30",
+				"LATENCY_INJECTION_CEDULAS": "1710034065, 0912345675",
+			},
+			activa:     true,
+			retardo:    250 * time.Millisecond,
+			porcentaje: 30,
+			cedulas:    []string{"1710034065", "0912345675"},
+		},
+		{
+			nombre: "porcentaje inválido usa 100",
+			env: map[string]string{
+				"ENABLE_LATENCY_INJECTION":  "true",
+				"LATENCY_INJECTION_MS":      "250",
+				"LATENCY_INJECTION_PERCENT": "150",
+			},
+			activa:     true,
+			retardo:    250 * time.Millisecond,
+			porcentaje: 100,
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			for _, clave := range []string{"ENABLE_LATENCY_INJECTION", "APP_ENV", "LATENCY_INJECTION_MS", "LATENCY_INJECTION_PERCENT", "LATENCY_INJECTION_CEDULAS"} {
+				t.Setenv(clave, caso.env[clave])
+			}
+
+			config := cargarLatenciaSintetica()
+			if !caso.activa {
+				if config != nil {
+					t.Fatalf("se esperaba la inyección desactivada, se obtuvo %+v", config)
+				}
+				return
+			}
+			if config == nil {
+				t.Fatal("se esperaba la inyección activada")
+			}
+			if config.retardo != caso.retardo || config.porcentaje != caso.porcentaje {
+				t.Errorf("retardo %v y porcentaje %d, se esperaban %v y %d", config.retardo, config.porcentaje, caso.retardo, caso.porcentaje)
+			}
+			if len(config.cedulas) != len(caso.cedulas) {
+				t.Fatalf("cédulas %v, se esperaban %v", config.cedulas, caso.cedulas)
+			}
+			for _, numero := range caso.cedulas {
+				if !config.cedulas[numero] {
+					t.Errorf("falta la cédula %s en %v", numero, config.cedulas)
+				}
+			}
+		})
+	}
+}
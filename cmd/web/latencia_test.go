@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// milisegundos convierte una lista de milisegundos en latencias
+func milisegundos(valores ...int) []time.Duration {
+	latencias := make([]time.Duration, len(valores))
+	for i, v := range valores {
+		latencias[i] = time.Duration(v) * time.Millisecond
+	}
+	return latencias
+}
+
+func TestPercentil(t *testing.T) {
+	cien := make([]int, 100)
+	for i := range cien {
+		cien[i] = i + 1
+	}
+
+	casos := []struct {
+		nombre    string
+		ordenados []time.Duration
+		p         float64
+		esperado  float64
+	}{
+		{"sin muestras", nil, 50, 0},
+		{"una muestra", milisegundos(7), 99, 7},
+		{"p50 de 1..100", milisegundos(cien...), 50, 50},
+		{"p95 de 1..100", milisegundos(cien...), 95, 95},
+		{"p99 de 1..100", milisegundos(cien...), 99, 99},
+		// Rango más cercano: ceil(0.5*4) = 2, sin interpolar entre muestras
+		{"p50 de cuatro", milisegundos(10, 20, 30, 40), 50, 20},
+		{"p95 de cuatro", milisegundos(10, 20, 30, 40), 95, 40},
+		{"p0 usa la menor", milisegundos(10, 20, 30, 40), 0, 10},
+		{"fracciones de milisegundo", []time.Duration{1500 * time.Microsecond}, 50, 1.5},
+	}
+	for _, caso := range casos {
+		if got := percentil(caso.ordenados, caso.p); got != caso.esperado {
+			t.Errorf("%s: percentil(p%v) = %v, se esperaba %v", caso.nombre, caso.p, got, caso.esperado)
+		}
+	}
+}
+
+func TestEstadisticasLatencia(t *testing.T) {
+	var muestras muestrasLatencia
+	// Se registran desordenadas para comprobar que se ordenan antes de calcular
+	for i := 100; i >= 1; i-- {
+		muestras.registrar(time.Duration(i) * time.Millisecond)
+	}
+
+	estadisticas := muestras.estadisticas()
+	esperadas := EstadisticasLatencia{Muestras: 100, P50Ms: 50, P95Ms: 95, P99Ms: 99}
+	if estadisticas != esperadas {
+		t.Errorf("estadisticas = %+v, se esperaba %+v", estadisticas, esperadas)
+	}
+}
+
+func TestMuestrasLatenciaReemplazaLasMasAntiguas(t *testing.T) {
+	var muestras muestrasLatencia
+	for i := 0; i < capacidadMuestrasLatencia; i++ {
+		muestras.registrar(time.Second)
+	}
+	// Las nuevas muestras reemplazan a las más antiguas sin crecer el buffer
+	for i := 0; i < capacidadMuestrasLatencia; i++ {
+		muestras.registrar(time.Millisecond)
+	}
+
+	estadisticas := muestras.estadisticas()
+	if estadisticas.Muestras != capacidadMuestrasLatencia || estadisticas.P99Ms != 1 {
+		t.Errorf("estadisticas = %+v, se esperaban %d muestras de 1 ms", estadisticas, capacidadMuestrasLatencia)
+	}
+}
+
+func TestEstadisticasLatenciaUsaMiddlewaresDeLaAPI(t *testing.T) {
+	configurarClavesAPI(t, true, "clave-valida")
+	mux := nuevoMux()
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/latency", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("sin clave: estado = %d, se esperaba %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/stats/latency", nil)
+	req.Header.Set("X-API-Key", "clave-valida")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("con clave: estado = %d: %s", rec.Code, rec.Body.String())
+	}
+	var respuesta map[string]EstadisticasLatencia
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := respuesta["sri"]; !ok {
+		t.Errorf("falta la fuente sri en %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Ráfagas por defecto del límite de peticiones por IP (RATE_LIMIT_BURST) y del límite de
+// identificaciones de los lotes (RATE_LIMIT_BATCH_BURST). Los dos límites están desactivados
+// salvo que se configure su tasa por segundo (RATE_LIMIT_RPS y RATE_LIMIT_BATCH_RPS).
+const (
+	limiteRafagaPorDefecto     = 5
+	limiteRafagaLotePorDefecto = tamanoMaximoLote
+)
+
+// inactividadLimite es el tiempo sin peticiones tras el cual se olvida el balde de una IP
+const inactividadLimite = 3 * time.Minute
+
+// errDemasiadasPeticiones se devuelve cuando una IP supera su límite de peticiones
+var errDemasiadasPeticiones = &errorAPI{codigo: CodigoDemasiadasPeticiones, estado: http.StatusTooManyRequests, mensaje: "Demasiadas peticiones. Intente nuevamente más tarde"}
+
+// visitante es el balde de tokens de una IP y el momento de su última petición
+type visitante struct {
+	limitador *rate.Limiter
+	ultimaVez time.Time
+}
+
+// limitadorIP aplica un balde de tokens independiente a cada IP de cliente
+type limitadorIP struct {
+	mu             sync.Mutex
+	porSegundo     rate.Limit
+	rafaga         int
+	visitantes     map[string]*visitante
+	ultimaLimpieza time.Time
+}
+
+// nuevoLimitadorIP crea un limitador con la tasa (peticiones por segundo) y la ráfaga indicadas
+func nuevoLimitadorIP(porSegundo float64, rafaga int) *limitadorIP {
+	return &limitadorIP{
+		porSegundo: rate.Limit(porSegundo),
+		rafaga:     rafaga,
+		visitantes: make(map[string]*visitante),
+	}
+}
+
+// reservar consume un token de la IP. Si no hay disponible devuelve false y cuánto falta
+// para el siguiente.
+func (l *limitadorIP) reservar(ip string, ahora time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Olvidar de vez en cuando las IPs inactivas para que el mapa no crezca sin límite
+	if ahora.Sub(l.ultimaLimpieza) > time.Minute {
+		for clave, v := range l.visitantes {
+			if ahora.Sub(v.ultimaVez) > inactividadLimite {
+				delete(l.visitantes, clave)
+			}
+		}
+		l.ultimaLimpieza = ahora
+	}
+
+	v, ok := l.visitantes[ip]
+	if !ok {
+		v = &visitante{limitador: rate.NewLimiter(l.porSegundo, l.rafaga)}
+		l.visitantes[ip] = v
+	}
+	v.ultimaVez = ahora
+
+	reserva := v.limitador.ReserveN(ahora, 1)
+	if !reserva.OK() {
+		return false, time.Second
+	}
+	if espera := reserva.DelayFrom(ahora); espera > 0 {
+		reserva.CancelAt(ahora)
+		return false, espera
+	}
+	return true, 0
+}
+
+// limitePorIP es el limitador de peticiones activo; nil si el límite está desactivado
+var limitePorIP atomic.Pointer[limitadorIP]
+
+// limiteLotePorIP es el limitador de las identificaciones válidas de los lotes, aparte del de
+// peticiones; nil si está desactivado
+var limiteLotePorIP atomic.Pointer[limitadorIP]
+
+// proxiesConfiables son las redes de los proxies cuyo X-Forwarded-For se respeta (TRUSTED_PROXIES)
+var proxiesConfiables atomic.Pointer[[]*net.IPNet]
+
+// cargarLimite lee la tasa por segundo y la ráfaga de un limitador de las variables indicadas;
+// devuelve nil si la tasa no está configurada o es 0, que desactivan el límite
+func cargarLimite(variableRPS, variableRafaga string, rafagaPorDefecto int) (*limitadorIP, error) {
+	var porSegundo float64
+	if valorRPS := os.Getenv(variableRPS); valorRPS != "" {
+		valor, err := strconv.ParseFloat(strings.TrimSpace(valorRPS), 64)
+		if err != nil || valor < 0 || math.IsInf(valor, 0) || math.IsNaN(valor) {
+			return nil, fmt.Errorf("%s inválido (%q)", variableRPS, valorRPS)
+		}
+		porSegundo = valor
+	}
+	rafaga := rafagaPorDefecto
+	if valorRafaga := os.Getenv(variableRafaga); valorRafaga != "" {
+		valor, err := strconv.Atoi(strings.TrimSpace(valorRafaga))
+		if err != nil || valor <= 0 {
+			return nil, fmt.Errorf("%s inválido (%q)", variableRafaga, valorRafaga)
+		}
+		rafaga = valor
+	}
+	if porSegundo == 0 {
+		return nil, nil
+	}
+	return nuevoLimitadorIP(porSegundo, rafaga), nil
+}
+
+// parsearRedes interpreta una lista de IPs o rangos CIDR separados por comas
+func parsearRedes(valor string) ([]*net.IPNet, error) {
+	var redes []*net.IPNet
+	for _, elemento := range parsearListaEnv(valor) {
+		if !strings.Contains(elemento, "/") {
+			ip := net.ParseIP(elemento)
+			if ip == nil {
+				return nil, fmt.Errorf("IP inválida %q", elemento)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			redes = append(redes, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, red, err := net.ParseCIDR(elemento)
+		if err != nil {
+			return nil, fmt.Errorf("rango inválido %q", elemento)
+		}
+		redes = append(redes, red)
+	}
+	return redes, nil
+}
+
+// esProxyConfiable indica si la IP pertenece a alguna de las redes de proxies configuradas
+func esProxyConfiable(ip net.IP, redes []*net.IPNet) bool {
+	for _, red := range redes {
+		if red.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipCliente devuelve la IP real del cliente. X-Forwarded-For solo se respeta si la conexión
+// viene de un proxy confiable; en ese caso se recorre de derecha a izquierda y se toma la
+// primera IP que no es de un proxy confiable, para que el cliente no pueda falsificarla.
+func ipCliente(r *http.Request) string {
+	remota, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remota = r.RemoteAddr
+	}
+
+	var redes []*net.IPNet
+	if configuradas := proxiesConfiables.Load(); configuradas != nil {
+		redes = *configuradas
+	}
+	ip := net.ParseIP(remota)
+	if ip == nil || !esProxyConfiable(ip, redes) {
+		return remota
+	}
+
+	var saltos []string
+	for _, cabecera := range r.Header.Values("X-Forwarded-For") {
+		saltos = append(saltos, strings.Split(cabecera, ",")...)
+	}
+	for i := len(saltos) - 1; i >= 0; i-- {
+		salto := net.ParseIP(strings.TrimSpace(saltos[i]))
+		if salto == nil {
+			break
+		}
+		remota = salto.String()
+		if !esProxyConfiable(salto, redes) {
+			break
+		}
+	}
+	return remota
+}
+
+// limitarLote cobra al cliente un token del límite de lotes por cada identificación válida del
+// lote (las inválidas no llegan al SRI) y devuelve cuáles se quedaron sin token. La petición en
+// sí ya la cobró limitarPorIP una sola vez.
+func limitarLote(r *http.Request, validas []bool) []bool {
+	limitadas := make([]bool, len(validas))
+	limitador := limiteLotePorIP.Load()
+	if limitador == nil {
+		return limitadas
+	}
+
+	ip, ahora := ipCliente(r), time.Now()
+	for i, valida := range validas {
+		if valida {
+			ok, _ := limitador.reservar(ip, ahora)
+			limitadas[i] = !ok
+		}
+	}
+	return limitadas
+}
+
+// limitarPorIP responde 429 con Retry-After a las IPs que superan su límite de peticiones
+func limitarPorIP(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limitador := limitePorIP.Load()
+		// Las peticiones preflight no cuentan: el navegador las envía antes de cada POST
+		if limitador == nil || r.Method == http.MethodOptions {
+			siguiente.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, espera := limitador.reservar(ipCliente(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(espera.Seconds()))))
+			writeError(w, r, errDemasiadasPeticiones)
+			return
+		}
+		siguiente.ServeHTTP(w, r)
+	})
+}
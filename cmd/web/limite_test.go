@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// configurarLimite activa durante la prueba un limitador con la tasa y la ráfaga indicadas y
+// los proxies confiables de la lista
+func configurarLimite(t *testing.T, porSegundo float64, rafaga int, proxies string) {
+	t.Helper()
+	redes, err := parsearRedes(proxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiteAnterior, proxiesAnteriores := limitePorIP.Load(), proxiesConfiables.Load()
+	limitePorIP.Store(nuevoLimitadorIP(porSegundo, rafaga))
+	proxiesConfiables.Store(&redes)
+	t.Cleanup(func() {
+		limitePorIP.Store(limiteAnterior)
+		proxiesConfiables.Store(proxiesAnteriores)
+	})
+}
+
+// peticionLimitada envía una petición a un handler detrás de limitarPorIP desde remota, con
+// X-Forwarded-For si no está vacío
+func peticionLimitada(remota, reenviadoPor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+	req.RemoteAddr = remota
+	if reenviadoPor != "" {
+		req.Header.Set("X-Forwarded-For", reenviadoPor)
+	}
+	rec := httptest.NewRecorder()
+	limitarPorIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLimitarPorIPAgotaElBalde(t *testing.T) {
+	configurarLimite(t, 0.5, 2, "")
+
+	for i := 0; i < 2; i++ {
+		if rec := peticionLimitada("192.0.2.1:5000", ""); rec.Code != http.StatusOK {
+			t.Fatalf("petición %d: estado = %d, se esperaba 200", i, rec.Code)
+		}
+	}
+
+	rec := peticionLimitada("192.0.2.1:5000", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("estado = %d, se esperaba 429", rec.Code)
+	}
+	// A 0,5 peticiones por segundo el siguiente token tarda 2 segundos
+	if reintento := rec.Header().Get("Retry-After"); reintento != "2" {
+		t.Errorf("Retry-After = %q, se esperaba 2", reintento)
+	}
+	var respuesta ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	if respuesta.Code != CodigoDemasiadasPeticiones {
+		t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoDemasiadasPeticiones)
+	}
+
+	// Otra IP tiene su propio balde
+	if rec := peticionLimitada("192.0.2.2:5000", ""); rec.Code != http.StatusOK {
+		t.Errorf("otra IP: estado = %d, se esperaba 200", rec.Code)
+	}
+}
+
+func TestLimitarPorIPIgnoraForwardedSinProxyConfiable(t *testing.T) {
+	configurarLimite(t, 0.5, 1, "")
+
+	peticionLimitada("192.0.2.1:5000", "198.51.100.1")
+	// Cambiar X-Forwarded-For no da un balde nuevo si la conexión no viene de un proxy confiable
+	if rec := peticionLimitada("192.0.2.1:5000", "198.51.100.2"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("estado = %d, se esperaba 429", rec.Code)
+	}
+}
+
+func TestLimitarPorIPRespetaForwardedDeProxyConfiable(t *testing.T) {
+	configurarLimite(t, 0.5, 1, "10.0.0.0/8")
+
+	if rec := peticionLimitada("10.0.0.5:5000", "198.51.100.1"); rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200", rec.Code)
+	}
+	// Detrás del mismo proxy, cada cliente tiene su propio balde
+	if rec := peticionLimitada("10.0.0.5:5000", "198.51.100.2"); rec.Code != http.StatusOK {
+		t.Errorf("otro cliente: estado = %d, se esperaba 200", rec.Code)
+	}
+	if rec := peticionLimitada("10.0.0.5:5000", "198.51.100.1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("mismo cliente: estado = %d, se esperaba 429", rec.Code)
+	}
+}
+
+func TestIPCliente(t *testing.T) {
+	redes, err := parsearRedes("10.0.0.0/8, 192.0.2.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anteriores := proxiesConfiables.Load()
+	proxiesConfiables.Store(&redes)
+	t.Cleanup(func() { proxiesConfiables.Store(anteriores) })
+
+	casos := []struct {
+		nombre       string
+		remota       string
+		reenviadoPor string
+		ip           string
+	}{
+		{"sin proxy", "198.51.100.7:1234", "", "198.51.100.7"},
+		{"cliente no confiable con X-Forwarded-For", "198.51.100.7:1234", "203.0.113.9", "198.51.100.7"},
+		{"proxy confiable", "10.1.2.3:1234", "203.0.113.9", "203.0.113.9"},
+		{"cadena de proxies confiables", "10.1.2.3:1234", "203.0.113.9, 192.0.2.10", "203.0.113.9"},
+		// El cliente antepone una IP falsa; se toma la primera no confiable desde la derecha
+		{"IP falsificada por el cliente", "10.1.2.3:1234", "1.2.3.4, 203.0.113.9", "203.0.113.9"},
+		{"proxy confiable sin X-Forwarded-For", "10.1.2.3:1234", "", "10.1.2.3"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = caso.remota
+			if caso.reenviadoPor != "" {
+				req.Header.Set("X-Forwarded-For", caso.reenviadoPor)
+			}
+			if ip := ipCliente(req); ip != caso.ip {
+				t.Errorf("ipCliente = %q, se esperaba %q", ip, caso.ip)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// tamanoMaximoLote es la cantidad máxima de identificaciones por petición a /api/consultar-lote
+const tamanoMaximoLote = 50
+
+// trabajadoresLote es la cantidad de consultas simultáneas de un lote (BATCH_WORKERS)
+var trabajadoresLote = 5
+
+// errLoteDemasiadoGrande se devuelve cuando el lote supera tamanoMaximoLote
+var errLoteDemasiadoGrande = &errorAPI{
+	codigo:  CodigoLoteDemasiadoGrande,
+	estado:  http.StatusBadRequest,
+	mensaje: fmt.Sprintf("El lote no puede tener más de %d cédulas", tamanoMaximoLote),
+}
+
+// LoteRequest representa la petición de consulta de varias cédulas o RUC
+type LoteRequest struct {
+	Cedulas []string `json:"cedulas"`
+}
+
+// ResultadoLote es el resultado de una identificación del lote: los datos si se encontró
+// o el código y el mensaje de error en caso contrario. Indice y Entrada permiten relacionarlo
+// con la identificación de la petición aunque el cliente reordene o filtre los resultados.
+type ResultadoLote struct {
+	// Indice es la posición de la identificación en la petición, desde 0
+	Indice int `json:"indice" xml:"indice,attr"`
+	// Entrada es la identificación tal como llegó en la petición; Cedula es la normalizada
+	Entrada string         `json:"entrada" xml:"entrada,attr"`
+	Cedula  string         `json:"cedula" xml:"cedula,attr"`
+	Success bool           `json:"success" xml:"success,attr"`
+	Datos   *cedula.Result `json:"datos,omitempty" xml:"cedulaResponse,omitempty"`
+	Code    CodigoError    `json:"code,omitempty" xml:"code,omitempty"`
+	Error   string         `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// LoteResponse representa la respuesta de la consulta por lote, en el orden de la petición
+type LoteResponse struct {
+	XMLName    xml.Name        `json:"-" xml:"loteResponse"`
+	Resultados []ResultadoLote `json:"resultados" xml:"resultado"`
+}
+
+// consultarIdentificacionLote valida y consulta una identificación del lote
+func consultarIdentificacionLote(r *http.Request, valor string) ResultadoLote {
+	resultadoLote := ResultadoLote{Cedula: valor}
+
+	identificacion, err := validarIdentificacion(r.Context(), valor)
+	if err == nil {
+		resultadoLote.Cedula = identificacion
+		var resultado *cedula.Result
+		resultado, err = clienteSRI.Lookup(r.Context(), identificacion)
+		contarConsulta("consultar-lote", fuenteConsulta(resultado), err)
+		if err == nil {
+			if enmascararPII.Load() && !esClientePrivilegiado(r) {
+				resultado = enmascararResultado(resultado)
+			}
+			resultadoLote.Success = true
+			resultadoLote.Datos = resultado
+			return resultadoLote
+		}
+	}
+
+	return resultadoLoteConError(r, resultadoLote, err)
+}
+
+// resultadoLoteConError completa el resultado de una identificación del lote con el código y el
+// mensaje de err. Las cancelaciones y los timeouts tienen su propio código y no se registran
+// como errores internos.
+func resultadoLoteConError(r *http.Request, resultadoLote ResultadoLote, err error) ResultadoLote {
+	apiErr := comoErrorAPI(err)
+	if apiErr == errInterno && err != errInterno {
+		cedula.LoggerFrom(r.Context()).Error("Error interno en el lote", "cedula", cedula.Redact(resultadoLote.Cedula), "error", err)
+	}
+	resultadoLote.Code = apiErr.codigo
+	resultadoLote.Error = sanitizarMensaje(apiErr.mensaje)
+	return resultadoLote
+}
+
+// manejarConsultaLote maneja las peticiones POST al endpoint /api/consultar-lote, con un
+// LoteRequest en JSON o una identificación por línea en text/plain. Cada identificación se
+// consulta por separado, con a lo sumo trabajadoresLote a la vez, y su error (si lo hay) se
+// informa en su propio resultado sin afectar a las demás.
+func manejarConsultaLote(w http.ResponseWriter, r *http.Request) {
+	// Los headers CORS y las peticiones preflight OPTIONS los maneja aplicarCORS
+	w.Header().Set("Content-Type", "application/json")
+
+	// Verificar que sea una petición POST
+	if r.Method != "POST" {
+		writeError(w, r, errMetodoNoPermitido)
+		return
+	}
+
+	// Leer las identificaciones: una por línea si el cuerpo es text/plain, JSON en otro caso
+	var req LoteRequest
+	if esTextoPlano(r) {
+		cedulas, err := leerLineas(w, r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req.Cedulas = cedulas
+	} else if err := decodificarJSON(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Validar el tamaño del lote
+	if len(req.Cedulas) == 0 {
+		var validacion ValidationError
+		validacion.Agregar("cedulas", "El campo cedulas debe incluir al menos una cédula")
+		writeError(w, r, validacion.Err())
+		return
+	}
+	if len(req.Cedulas) > tamanoMaximoLote {
+		writeError(w, r, errLoteDemasiadoGrande)
+		return
+	}
+
+	// Cada identificación válida cuenta para el límite de lotes (si está activo); las que no
+	// alcanzan token se informan como RATE_LIMITED en su propio resultado
+	validas := make([]bool, len(req.Cedulas))
+	for i, valor := range req.Cedulas {
+		_, err := validarIdentificacion(r.Context(), valor)
+		validas[i] = err == nil
+	}
+	limitadas := limitarLote(r, validas)
+
+	// Repartir las consultas entre un número acotado de trabajadores; todas respetan la
+	// antigüedad máxima que acepte el cliente
+	r = conEdadMaxima(r)
+	respuesta := LoteResponse{Resultados: make([]ResultadoLote, len(req.Cedulas))}
+	pendientes := make(chan int)
+	var grupo sync.WaitGroup
+	for t := 0; t < min(trabajadoresLote, len(req.Cedulas)); t++ {
+		grupo.Add(1)
+		go func() {
+			defer grupo.Done()
+			for i := range pendientes {
+				var resultado ResultadoLote
+				if limitadas[i] {
+					resultado = resultadoLoteConError(r, ResultadoLote{Cedula: req.Cedulas[i]}, errDemasiadasPeticiones)
+				} else {
+					resultado = consultarIdentificacionLote(r, req.Cedulas[i])
+				}
+				resultado.Indice, resultado.Entrada = i, req.Cedulas[i]
+				respuesta.Resultados[i] = resultado
+			}
+		}()
+	}
+	for i := range req.Cedulas {
+		pendientes <- i
+	}
+	close(pendientes)
+	grupo.Wait()
+
+	escribirRespuesta(w, r, http.StatusOK, respuesta)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// cedulasPrueba son identificaciones válidas para armar lotes
+var cedulasPrueba = []string{"1710034065", "0912345675", "0102030400", "2401010109", "3000000004"}
+
+// cedulaInexistente es una cédula válida para la que el SRI de prueba no tiene datos
+const cedulaInexistente = "1712345675"
+
+// usarSRIPrueba reemplaza durante la prueba el cliente del SRI por uno que consulta un servidor
+// de prueba con una respuesta fija (404 para cedulaInexistente)
+func usarSRIPrueba(t *testing.T) {
+	t.Helper()
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, cedulaInexistente) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS"}}`))
+	}))
+	anterior := clienteSRI
+	clienteSRI = &cedula.Client{Hosts: cedula.NewHosts(servidor.URL)}
+	t.Cleanup(func() {
+		clienteSRI = anterior
+		servidor.Close()
+	})
+}
+
+// consultarLote envía un lote a manejarConsultaLote detrás de limitarPorIP con el contexto indicado
+func consultarLote(t *testing.T, ctx context.Context, cedulas []string) (*httptest.ResponseRecorder, LoteResponse) {
+	t.Helper()
+	cuerpo, _ := json.Marshal(LoteRequest{Cedulas: cedulas})
+	req := httptest.NewRequest(http.MethodPost, "/api/consultar-lote", bytes.NewReader(cuerpo)).WithContext(ctx)
+	req.RemoteAddr = "192.0.2.1:5000"
+	rec := httptest.NewRecorder()
+	limitarPorIP(http.HandlerFunc(manejarConsultaLote)).ServeHTTP(rec, req)
+
+	var respuesta LoteResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec, respuesta
+}
+
+// configurarLimiteLote activa durante la prueba el límite de identificaciones de los lotes
+func configurarLimiteLote(t *testing.T, porSegundo float64, rafaga int) {
+	t.Helper()
+	anterior := limiteLotePorIP.Load()
+	limiteLotePorIP.Store(nuevoLimitadorIP(porSegundo, rafaga))
+	t.Cleanup(func() { limiteLotePorIP.Store(anterior) })
+}
+
+// sinLimites desactiva durante la prueba el límite por IP y el de lotes, como por defecto
+func sinLimites(t *testing.T) {
+	t.Helper()
+	anterior, anteriorLote := limitePorIP.Load(), limiteLotePorIP.Load()
+	limitePorIP.Store(nil)
+	limiteLotePorIP.Store(nil)
+	t.Cleanup(func() {
+		limitePorIP.Store(anterior)
+		limiteLotePorIP.Store(anteriorLote)
+	})
+}
+
+func TestConsultaLoteMixta(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	casos := []struct {
+		entrada string
+		cedula  string
+		codigo  CodigoError
+	}{
+		{"1710034065", "1710034065", ""},
+		{"1710034064", "1710034064", CodigoCedulaInvalida},
+		{cedulaInexistente, cedulaInexistente, CodigoNoEncontrada},
+		{"0912345675", "0912345675", ""},
+		{"abc", "abc", CodigoCedulaInvalida},
+		{"2401010109", "2401010109", ""},
+	}
+	entradas := make([]string, len(casos))
+	for i, caso := range casos {
+		entradas[i] = caso.entrada
+	}
+
+	rec, respuesta := consultarLote(t, context.Background(), entradas)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200", rec.Code)
+	}
+	if len(respuesta.Resultados) != len(casos) {
+		t.Fatalf("%d resultados, se esperaban %d", len(respuesta.Resultados), len(casos))
+	}
+	// Cada resultado queda en la posición de su identificación en la petición
+	for i, caso := range casos {
+		resultado := respuesta.Resultados[i]
+		if resultado.Cedula != caso.cedula {
+			t.Errorf("resultado %d: cédula = %q, se esperaba %q", i, resultado.Cedula, caso.cedula)
+		}
+		if caso.codigo == "" && (!resultado.Success || resultado.Datos == nil) {
+			t.Errorf("resultado %d: %+v, se esperaba éxito", i, resultado)
+		}
+		if caso.codigo != "" && (resultado.Success || resultado.Code != caso.codigo) {
+			t.Errorf("resultado %d: %+v, se esperaba %s", i, resultado, caso.codigo)
+		}
+	}
+}
+
+func TestConsultaLoteDemasiadoGrande(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	cedulas := make([]string, tamanoMaximoLote+1)
+	for i := range cedulas {
+		cedulas[i] = cedulasPrueba[i%len(cedulasPrueba)]
+	}
+	rec, _ := consultarLote(t, context.Background(), cedulas)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("estado = %d, se esperaba 400", rec.Code)
+	}
+	var respuesta ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	if respuesta.Code != CodigoLoteDemasiadoGrande {
+		t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoLoteDemasiadoGrande)
+	}
+
+	// El lote de tamaño máximo se atiende entero con la configuración por defecto
+	rec, lote := consultarLote(t, context.Background(), cedulas[:tamanoMaximoLote])
+	if rec.Code != http.StatusOK {
+		t.Fatalf("lote máximo: estado = %d, se esperaba 200", rec.Code)
+	}
+	for i, resultado := range lote.Resultados {
+		if !resultado.Success {
+			t.Errorf("resultado %d: %+v, se esperaba éxito", i, resultado)
+		}
+	}
+}
+
+func TestConsultaLoteCobraLasValidasAparte(t *testing.T) {
+	usarSRIPrueba(t)
+	configurarLimite(t, 0.001, 2, "")
+	configurarLimiteLote(t, 0.001, 3)
+
+	// Las inválidas no consumen tokens del límite de lotes
+	entradas := []string{"1710034065", "1710034064", "0912345675", "abc", "0102030400", "2401010109"}
+	rec, respuesta := consultarLote(t, context.Background(), entradas)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200", rec.Code)
+	}
+	codigos := make([]CodigoError, len(respuesta.Resultados))
+	for i, resultado := range respuesta.Resultados {
+		codigos[i] = resultado.Code
+	}
+	esperados := []CodigoError{"", CodigoCedulaInvalida, "", CodigoCedulaInvalida, "", CodigoDemasiadasPeticiones}
+	if fmt.Sprint(codigos) != fmt.Sprint(esperados) {
+		t.Errorf("códigos = %v, se esperaba %v", codigos, esperados)
+	}
+
+	// El límite por IP cobra el lote una sola vez: la segunda petición pasa y la tercera no
+	if rec, _ := consultarLote(t, context.Background(), cedulasPrueba[:1]); rec.Code != http.StatusOK {
+		t.Errorf("segundo lote: estado = %d, se esperaba 200", rec.Code)
+	}
+	if rec, _ := consultarLote(t, context.Background(), cedulasPrueba[:1]); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("tercer lote: estado = %d, se esperaba 429", rec.Code)
+	}
+}
+
+func TestConsultaLoteCancelacionYTimeout(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	cancelado, cancelar := context.WithCancel(context.Background())
+	cancelar()
+	vencido, cancelarVencido := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelarVencido()
+
+	casos := []struct {
+		nombre string
+		ctx    context.Context
+		codigo CodigoError
+	}{
+		{"cancelado", cancelado, CodigoCancelada},
+		{"vencido", vencido, CodigoTiempoAgotado},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			var registros bytes.Buffer
+			ctx := cedula.WithLogger(caso.ctx, slog.New(slog.NewJSONHandler(&registros, nil)))
+
+			_, respuesta := consultarLote(t, ctx, cedulasPrueba[:2])
+			for i, resultado := range respuesta.Resultados {
+				if resultado.Success || resultado.Code != caso.codigo {
+					t.Errorf("resultado %d: %+v, se esperaba %s", i, resultado, caso.codigo)
+				}
+			}
+			if strings.Contains(registros.String(), "Error interno") {
+				t.Errorf("la cancelación no debería registrarse como error interno:\n%s", registros.String())
+			}
+		})
+	}
+}
+
+func TestConsultaLoteIndicaIndiceYEntrada(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	// Las entradas repetidas o con formato se distinguen por su índice y su entrada original
+	entradas := []string{"171003406-5", "1710034064", "0912345675", "1710034065", " 0912345675 "}
+	_, respuesta := consultarLote(t, context.Background(), entradas)
+	if len(respuesta.Resultados) != len(entradas) {
+		t.Fatalf("%d resultados, se esperaban %d", len(respuesta.Resultados), len(entradas))
+	}
+
+	// El cliente puede reordenar los resultados y seguir relacionándolos con la petición
+	resultados := append([]ResultadoLote(nil), respuesta.Resultados...)
+	slices.Reverse(resultados)
+	vistos := make(map[int]bool)
+	for _, resultado := range resultados {
+		if resultado.Indice < 0 || resultado.Indice >= len(entradas) || vistos[resultado.Indice] {
+			t.Fatalf("índice %d fuera de rango o repetido en %+v", resultado.Indice, resultado)
+		}
+		vistos[resultado.Indice] = true
+		if resultado.Entrada != entradas[resultado.Indice] {
+			t.Errorf("resultado %d: entrada = %q, se esperaba %q", resultado.Indice, resultado.Entrada, entradas[resultado.Indice])
+		}
+	}
+	if normalizada := respuesta.Resultados[0]; normalizada.Cedula != "1710034065" || normalizada.Entrada != "171003406-5" {
+		t.Errorf("resultado 0: cédula = %q, entrada = %q; se esperaba la cédula normalizada y la entrada original", normalizada.Cedula, normalizada.Entrada)
+	}
+}
+
+// consultarLoteTexto envía a manejarConsultaLote un cuerpo text/plain con el Content-Type indicado
+func consultarLoteTexto(t *testing.T, tipo, cuerpo string) (*httptest.ResponseRecorder, LoteResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/consultar-lote", strings.NewReader(cuerpo))
+	req.Header.Set("Content-Type", tipo)
+	rec := httptest.NewRecorder()
+	manejarConsultaLote(rec, req)
+
+	var respuesta LoteResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec, respuesta
+}
+
+func TestConsultaLoteTextoPlano(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	cuerpo := "1710034065\n\n   \n  0912345675  \r\n1710034064\n\n" + cedulaInexistente
+	rec, respuesta := consultarLoteTexto(t, "text/plain; charset=utf-8", cuerpo)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200: %s", rec.Code, rec.Body.String())
+	}
+
+	esperados := []struct {
+		cedula string
+		codigo CodigoError
+	}{
+		{"1710034065", ""},
+		{"0912345675", ""},
+		{"1710034064", CodigoCedulaInvalida},
+		{cedulaInexistente, CodigoNoEncontrada},
+	}
+	if len(respuesta.Resultados) != len(esperados) {
+		t.Fatalf("%d resultados, se esperaban %d: %+v", len(respuesta.Resultados), len(esperados), respuesta.Resultados)
+	}
+	for i, esperado := range esperados {
+		resultado := respuesta.Resultados[i]
+		if resultado.Indice != i || resultado.Entrada != esperado.cedula || resultado.Cedula != esperado.cedula {
+			t.Errorf("resultado %d: %+v, se esperaba la entrada %q", i, resultado, esperado.cedula)
+		}
+		if resultado.Success != (esperado.codigo == "") || resultado.Code != esperado.codigo {
+			t.Errorf("resultado %d: éxito = %v, código = %s; se esperaba %q", i, resultado.Success, resultado.Code, esperado.codigo)
+		}
+	}
+}
+
+func TestConsultaLoteTextoPlanoInvalido(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+
+	casos := []struct {
+		nombre, cuerpo string
+		estado         int
+		codigo         CodigoError
+	}{
+		{"solo líneas vacías", "\n  \n\r\n", http.StatusBadRequest, CodigoValidacion},
+		{"demasiadas líneas", strings.Repeat("1710034065\n", tamanoMaximoLote+1), http.StatusBadRequest, CodigoLoteDemasiadoGrande},
+		{"cuerpo demasiado grande", strings.Repeat("1710034065\n", int(limiteCuerpo)/11+1), http.StatusRequestEntityTooLarge, CodigoCuerpoDemasiadoGrande},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec, _ := consultarLoteTexto(t, "text/plain", caso.cuerpo)
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			var respuesta ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Code != caso.codigo {
+				t.Errorf("código = %s, se esperaba %s", respuesta.Code, caso.codigo)
+			}
+		})
+	}
+}
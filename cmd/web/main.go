@@ -1,14 +1,31 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"regexp"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"consulta-cedula-app/pkg/cedula"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // CedulaRequest representa la estructura de la petición de consulta por cédula
@@ -22,322 +39,857 @@ type NombresRequest struct {
 	Apellidos string `json:"apellidos"`
 }
 
-// CedulaResponse representa la respuesta exitosa con los datos
-type CedulaResponse struct {
-	Nombre   string `json:"nombre"`
-	Apellido string `json:"apellido"`
+// AlternativasResponse es la respuesta informativa de la consulta por nombres cuando no hay
+// una fuente pública gratuita, con las alternativas legales disponibles
+type AlternativasResponse struct {
+	XMLName          xml.Name `json:"-" xml:"alternativasResponse"`
+	Success          bool     `json:"success" xml:"success"`
+	Nombres          string   `json:"nombres" xml:"nombres"`
+	Apellidos        string   `json:"apellidos" xml:"apellidos"`
+	Message          string   `json:"message" xml:"message"`
+	AlternativesInfo bool     `json:"alternatives_info" xml:"alternatives_info"`
+	ErrorDetails     string   `json:"error_details" xml:"error_details"`
 }
 
-// NombresResponse representa la respuesta exitosa con la cédula encontrada
-type NombresResponse struct {
-	Cedula    string `json:"cedula"`
-	Nombres   string `json:"nombres"`
-	Apellidos string `json:"apellidos"`
+// PlanConsulta describe lo que se consultaría en modo dryRun, sin llamar a ninguna fuente externa
+type PlanConsulta struct {
+	DryRun      bool                  `json:"dryRun"`
+	Fuente      string                `json:"fuente"`
+	TipoPersona string                `json:"tipoPersona,omitempty"`
+	Peticiones  []PeticionPlanificada `json:"peticiones"`
 }
 
-// ErrorResponse representa la respuesta de error
-type ErrorResponse struct {
-	Error string `json:"error"`
+// PeticionPlanificada representa una petición HTTP que se realizaría hacia una fuente externa
+type PeticionPlanificada struct {
+	Metodo  string            `json:"metodo"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
 }
 
-// validarCedula valida que la cédula sea un número de 10 dígitos
-func validarCedula(cedula string) bool {
-	// Verificar que tenga exactamente 10 dígitos
-	if len(cedula) != 10 {
-		return false
-	}
-
-	// Verificar que todos los caracteres sean números
-	match, _ := regexp.MatchString("^[0-9]+$", cedula)
-	return match
+// ErrorResponse representa la respuesta de error estándar de todos los endpoints
+type ErrorResponse struct {
+	XMLName   xml.Name     `json:"-" xml:"errorResponse"`
+	Error     string       `json:"error" xml:"error"`
+	Code      CodigoError  `json:"code" xml:"code"`
+	Timestamp string       `json:"timestamp" xml:"timestamp"`
+	Campos    []ErrorCampo `json:"campos,omitempty" xml:"campos>campo,omitempty"`
+	// RequestID es el X-Request-ID de la petición, para correlacionar el error con los logs
+	RequestID string `json:"requestId" xml:"requestId"`
 }
 
-// consultarCedula realiza la consulta a la API del SRI para obtener los datos de la cédula
-func consultarCedula(cedula string) (*CedulaResponse, error) {
-	// Construir la URL de la API del SRI
-	timestamp := time.Now().UnixMilli()
-	url := fmt.Sprintf("https://srienlinea.sri.gob.ec/movil-servicios/api/v1.0/deudas/porIdentificacion/%s/?tipoPersona=N&_=%d", cedula, timestamp)
+// erroresSRI agrupa en los logs los errores repetidos del SRI (ventana configurable con ERROR_LOG_WINDOW_SECONDS)
+var erroresSRI = nuevoRegistroAgrupado(10 * time.Second)
 
-	log.Printf("Consultando API del SRI: %s", url)
+// timeoutSRI es el timeout de las peticiones a las fuentes externas (SRI_TIMEOUT_SECONDS)
+var timeoutSRI = cedula.DefaultTimeout
 
-	// Crear cliente HTTP con timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+// parsearSegundos interpreta una duración en segundos, que debe ser un entero positivo
+func parsearSegundos(valor string) (time.Duration, error) {
+	segundos, err := strconv.Atoi(strings.TrimSpace(valor))
+	if err != nil {
+		return 0, err
+	}
+	if segundos <= 0 {
+		return 0, fmt.Errorf("debe ser un número positivo de segundos")
 	}
+	return time.Duration(segundos) * time.Second, nil
+}
 
-	// Crear petición HTTP
-	req, err := http.NewRequest("GET", url, nil)
+// leerTimeoutSRI lee SRI_TIMEOUT_SECONDS; si no está definido o no es un entero positivo se usa
+// cedula.DefaultTimeout
+func leerTimeoutSRI() time.Duration {
+	valor := os.Getenv("SRI_TIMEOUT_SECONDS")
+	if valor == "" {
+		return cedula.DefaultTimeout
+	}
+	timeout, err := parsearSegundos(valor)
 	if err != nil {
-		return nil, fmt.Errorf("error al crear la petición: %v", err)
+		slog.Warn("Valor inválido para SRI_TIMEOUT_SECONDS, usando el valor por defecto", "valor", valor, "error", err, "porDefecto", cedula.DefaultTimeout.String())
+		return cedula.DefaultTimeout
 	}
+	return timeout
+}
 
-	// Configurar headers para simular un navegador real
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	req.Header.Set("Accept", "application/json, text/plain, */*")
-	req.Header.Set("Accept-Language", "es-ES,es;q=0.9,en;q=0.8")
-	req.Header.Set("Referer", "https://srienlinea.sri.gob.ec/")
+// proxyUpstream, si no es nil, es el proxy por el que salen las peticiones a las fuentes
+// externas (UPSTREAM_PROXY_URL)
+var proxyUpstream *url.URL
 
-	// Realizar la petición
-	resp, err := client.Do(req)
+// esquemasProxy son los tipos de proxy que admite http.Transport
+var esquemasProxy = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// parsearProxyUpstream valida la URL de UPSTREAM_PROXY_URL: http://, https://, socks5:// o
+// socks5h://, con host y, opcionalmente, usuario y contraseña
+func parsearProxyUpstream(valor string) (*url.URL, error) {
+	proxy, err := url.Parse(strings.TrimSpace(valor))
 	if err != nil {
-		return nil, fmt.Errorf("error al realizar la petición: %v", err)
+		return nil, fmt.Errorf("UPSTREAM_PROXY_URL inválida: %v", err)
+	}
+	if !esquemasProxy[strings.ToLower(proxy.Scheme)] {
+		return nil, fmt.Errorf("UPSTREAM_PROXY_URL debe usar http, https, socks5 o socks5h (se recibió %q)", proxy.Scheme)
+	}
+	if proxy.Hostname() == "" {
+		return nil, fmt.Errorf("UPSTREAM_PROXY_URL no indica el host del proxy")
 	}
-	defer resp.Body.Close()
+	return proxy, nil
+}
 
-	// Leer la respuesta
-	body, err := io.ReadAll(resp.Body)
+// configurarProxyUpstream lee UPSTREAM_PROXY_URL; una URL inválida detiene el arranque para no
+// salir directamente a internet cuando se esperaba pasar por el proxy
+func configurarProxyUpstream() {
+	valor := os.Getenv("UPSTREAM_PROXY_URL")
+	if strings.TrimSpace(valor) == "" {
+		return
+	}
+	proxy, err := parsearProxyUpstream(valor)
 	if err != nil {
-		return nil, fmt.Errorf("error al leer la respuesta: %v", err)
+		terminar("Error de configuración", err)
 	}
+	proxyUpstream = proxy
+	slog.Info("Las peticiones a las fuentes externas saldrán por el proxy", "proxy", proxy.Redacted())
+}
 
-	log.Printf("Respuesta de la API (primeros 500 caracteres): %s", string(body)[:min(500, len(body))])
-
-	// Verificar el código de estado HTTP
-	if resp.StatusCode != 200 {
-		log.Printf("Código de estado HTTP: %d", resp.StatusCode)
-		return nil, fmt.Errorf("cédula no encontrada")
+// nuevoClienteHTTP crea el cliente HTTP para las fuentes externas con el timeout y el proxy configurados
+func nuevoClienteHTTP() *http.Client {
+	cliente := &http.Client{
+		Timeout: timeoutSRI,
 	}
-
-	// Estructura para parsear la respuesta JSON del SRI
-	type SRIResponse struct {
-		Contribuyente struct {
-			Identificacion  string `json:"identificacion"`
-			Denominacion    string `json:"denominacion"`
-			NombreComercial string `json:"nombreComercial"`
-			Clase           string `json:"clase"`
-		} `json:"contribuyente"`
+	if proxyUpstream != nil {
+		transporte := http.DefaultTransport.(*http.Transport).Clone()
+		transporte.Proxy = http.ProxyURL(proxyUpstream)
+		cliente.Transport = transporte
 	}
+	return cliente
+}
 
-	var sriData SRIResponse
-	if err := json.Unmarshal(body, &sriData); err != nil {
-		log.Printf("Error al parsear JSON: %v", err)
-		log.Printf("Respuesta completa: %s", string(body))
-		return nil, fmt.Errorf("error al procesar la respuesta del servidor")
-	}
+// clienteSRI realiza las consultas al SRI con la configuración del servidor (se arma en main)
+var clienteSRI = &cedula.Client{}
 
-	// Verificar que se encontraron datos
-	nombreCompleto := ""
-	if sriData.Contribuyente.Denominacion != "" {
-		nombreCompleto = sriData.Contribuyente.Denominacion
-	} else if sriData.Contribuyente.NombreComercial != "" {
-		nombreCompleto = sriData.Contribuyente.NombreComercial
+// antesDeLlamarSRI descuenta cada intento del presupuesto diario y respeta la concurrencia de la fuente
+func antesDeLlamarSRI(ctx context.Context) (func(), error) {
+	if !presupuestoUpstream.consumir() {
+		cedula.LoggerFrom(ctx).Warn("Presupuesto diario de llamadas al SRI agotado")
+		return nil, errCuotaAgotada
 	}
+	return adquirirFuente(ctx, "sri")
+}
 
-	if nombreCompleto == "" {
-		log.Printf("No se encontró información del nombre en la respuesta")
-		return nil, fmt.Errorf("cédula no encontrada")
+// despuesDeLlamarSRI registra la latencia de cada llamada al SRI, agrupa en los logs sus fallos
+// y recuerda el último éxito para /readyz
+func despuesDeLlamarSRI(base string, duracion time.Duration, err error) {
+	latencias["sri"].registrar(duracion)
+	latenciaSRI.Observe(duracion.Seconds())
+	if err != nil {
+		erroresSRI.Printf("Fallo del host del SRI %s: %v", base, err)
+		return
 	}
+	ultimoExitoSRI.Store(time.Now().UnixNano())
+}
 
-	log.Printf("Datos encontrados - Identificación: %s, Nombre: %s, Clase: %s",
-		sriData.Contribuyente.Identificacion, nombreCompleto, sriData.Contribuyente.Clase)
-
-	// Procesar el nombre completo para separar nombre y apellido
-	nombreCompleto = strings.TrimSpace(nombreCompleto)
-	palabras := strings.Fields(nombreCompleto)
+// formatoApellidosNombres es el valor de ?nameFormat= que pide el nombre como "APELLIDOS, NOMBRES"
+const formatoApellidosNombres = "apellidos-nombres"
 
-	var nombre, apellido string
+// errFormatoNombreInvalido se devuelve cuando ?nameFormat= tiene un valor desconocido
+var errFormatoNombreInvalido = &errorAPI{codigo: CodigoFormatoNombreInvalido, estado: http.StatusBadRequest, mensaje: "Formato de nombre inválido. Valores permitidos: " + formatoApellidosNombres}
 
-	if len(palabras) >= 2 {
-		// Asumimos que las primeras palabras son nombres y las últimas apellidos
-		// Para nombres ecuatorianos, generalmente: PRIMER_NOMBRE SEGUNDO_NOMBRE PRIMER_APELLIDO SEGUNDO_APELLIDO
-		if len(palabras) == 2 {
-			nombre = palabras[0]
-			apellido = palabras[1]
-		} else if len(palabras) == 3 {
-			nombre = palabras[0]
-			apellido = strings.Join(palabras[1:], " ")
-		} else {
-			// 4 o más palabras
-			mitad := len(palabras) / 2
-			nombre = strings.Join(palabras[:mitad], " ")
-			apellido = strings.Join(palabras[mitad:], " ")
-		}
-	} else {
-		nombre = nombreCompleto
-		apellido = ""
+// formatearApellidosNombres arma el nombre en orden alfabético de directorio ("APELLIDOS, NOMBRES").
+// Sin apellido (un solo nombre o una razón social) se devuelve el nombre tal cual.
+func formatearApellidosNombres(nombre, apellido string) string {
+	nombre = strings.Join(strings.Fields(nombre), " ")
+	apellido = strings.Join(strings.Fields(apellido), " ")
+	if apellido == "" {
+		return nombre
 	}
-
-	return &CedulaResponse{
-		Nombre:   nombre,
-		Apellido: apellido,
-	}, nil
+	if nombre == "" {
+		return apellido
+	}
+	return apellido + ", " + nombre
 }
 
-// Función auxiliar para min
-func min(a, b int) int {
-	if a < b {
-		return a
+// leerBoolEnv lee una variable de entorno booleana, usando el valor por defecto
+// si no está definida o no se puede interpretar
+func leerBoolEnv(nombre string, porDefecto bool) bool {
+	valor := os.Getenv(nombre)
+	if valor == "" {
+		return porDefecto
+	}
+	b, err := strconv.ParseBool(valor)
+	if err != nil {
+		slog.Warn("Valor inválido, usando el valor por defecto", "variable", nombre, "valor", valor, "porDefecto", porDefecto)
+		return porDefecto
 	}
 	return b
 }
 
-// consultarPorNombres informa sobre las alternativas legales disponibles para búsqueda por nombres
-func consultarPorNombres(nombres, apellidos string) (*NombresResponse, error) {
-	log.Printf("Consulta por nombres solicitada: %s %s", nombres, apellidos)
+// headersSensibles son los headers cuyo valor nunca se muestra en un plan de consulta
+var headersSensibles = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"X-Api-Key":     true,
+}
 
-	// En lugar de intentar scraping no autorizado, informamos sobre las alternativas legales
-	log.Printf("INFORMACIÓN: Existen alternativas legales oficiales para consultas por nombres en Ecuador")
+// planificarPeticion describe una petición HTTP ocultando los headers sensibles
+func planificarPeticion(req *http.Request) PeticionPlanificada {
+	headers := make(map[string]string, len(req.Header))
+	for nombre := range req.Header {
+		if headersSensibles[http.CanonicalHeaderKey(nombre)] {
+			headers[nombre] = "[REDACTADO]"
+			continue
+		}
+		headers[nombre] = req.Header.Get(nombre)
+	}
+	return PeticionPlanificada{
+		Metodo:  req.Method,
+		URL:     req.URL.String(),
+		Headers: headers,
+	}
+}
 
-	// Simular un tiempo de procesamiento mientras "evaluamos" las opciones
-	time.Sleep(2 * time.Second)
+// esDryRun indica si la petición solicita el modo dryRun (?dryRun=true)
+func esDryRun(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	return dryRun
+}
 
-	// Retornar error con información educativa sobre las alternativas legales
-	return nil, fmt.Errorf(`consulta por nombres no disponible a través de APIs públicas gratuitas.
+// edadMaximaTope es el mayor max-age que se considera; RFC 9111 pide tratar los valores mayores como 2^31
+const edadMaximaTope = 1 << 31
+
+// conEdadMaxima devuelve la petición con la antigüedad máxima del header Cache-Control: max-age=N
+// en su contexto, para que las consultas no usen resultados de la caché con más de N segundos.
+// Sin max-age, o si es inválido, la petición se devuelve sin cambios.
+func conEdadMaxima(r *http.Request) *http.Request {
+	for _, directiva := range strings.Split(strings.Join(r.Header.Values("Cache-Control"), ","), ",") {
+		nombre, valor, _ := strings.Cut(strings.TrimSpace(directiva), "=")
+		if !strings.EqualFold(nombre, "max-age") {
+			continue
+		}
+		segundos, err := strconv.Atoi(strings.Trim(valor, `"`))
+		if err != nil || segundos < 0 {
+			return r
+		}
+		edad := time.Duration(min(segundos, edadMaximaTope)) * time.Second
+		return r.WithContext(cedula.WithMaxAge(r.Context(), edad))
+	}
+	return r
+}
 
-ALTERNATIVAS LEGALES DISPONIBLES:
+// Cantidad mínima y máxima de caracteres de los nombres y de los apellidos
+const (
+	longitudMinimaNombre = 2
+	longitudMaximaNombre = 100
+)
 
-🏛️ FUNCIÓN JUDICIAL (SATJE)
-• Consulta de procesos judiciales por nombre
-• URL: https://procesosjudiciales.funcionjudicial.gob.ec/busqueda
-• Permite buscar si una persona tiene procesos judiciales registrados
+// validarNombresRequest revisa cada campo de la consulta por nombres y acumula los problemas
+func validarNombresRequest(req NombresRequest) error {
+	var validacion ValidationError
+	validarCampoNombre(&validacion, "nombres", req.Nombres)
+	validarCampoNombre(&validacion, "apellidos", req.Apellidos)
+	return validacion.Err()
+}
 
-🗳️ CONSEJO NACIONAL ELECTORAL (CNE) 
-• Consulta de personas registradas para votar
-• Búsqueda por nombre y apellido
-• Solo para ciudadanos habilitados para elecciones
+// validarCampoNombre registra si un campo de nombre falta, es demasiado corto o demasiado largo,
+// o contiene caracteres de control o de marcado HTML. Estos dos últimos casos no son errores de
+// un usuario legítimo, así que se informan con CodigoNombreInvalido.
+func validarCampoNombre(validacion *ValidationError, campo, valor string) {
+	valor = strings.TrimSpace(valor)
+	switch longitud := utf8.RuneCountInString(valor); {
+	case valor == "":
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s es obligatorio", campo))
+	case longitud < longitudMinimaNombre:
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s debe tener al menos %d caracteres", campo, longitudMinimaNombre))
+	case longitud > longitudMaximaNombre:
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s no puede tener más de %d caracteres", campo, longitudMaximaNombre))
+		validacion.Codigo = CodigoNombreInvalido
+	case !utf8.ValidString(valor) || strings.IndexFunc(valor, unicode.IsControl) >= 0:
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s contiene caracteres no permitidos", campo))
+		validacion.Codigo = CodigoNombreInvalido
+	case strings.ContainsAny(valor, "<>"):
+		validacion.Agregar(campo, fmt.Sprintf("El campo %s no puede contener HTML", campo))
+		validacion.Codigo = CodigoNombreInvalido
+	}
+}
 
-🏥 IESS (Instituto Ecuatoriano de Seguridad Social)
-• Consulta de afiliados (protegida con captcha)
-• No tiene API pública abierta
-• URL: https://www.iess.gob.ec/
+// validarIdentificacion normaliza y valida una identificación, que puede ser un RUC de 13
+// dígitos o una cédula de 10, y revisa los patrones sospechosos (rechazados solo con
+// STRICT_VALIDATION). Devuelve la identificación normalizada.
+func validarIdentificacion(ctx context.Context, valor string) (string, error) {
+	identificacion, ok := cedula.Normalize(valor)
+	if len(identificacion) == 13 {
+		if err := cedula.CheckRUC(identificacion); errors.Is(err, cedula.ErrRUCEstablishment) {
+			return "", errEstablecimiento
+		} else if err != nil {
+			return "", errRUCInvalido
+		}
+	} else if !ok || !cedula.ValidateCedula(identificacion) {
+		return "", errCedulaInvalida
+	}
 
-💰 SERVICIOS DE PAGO DISPONIBLES:
-• EcuadorLegalOnline: Consulta por nombres y apellidos
-• URL: https://tramites.ecuadorlegalonline.com/
-• Incluye datos completos: cédula, estado civil, profesión, etc.
-• Servicio de pago con garantía
+	if err := revisarCedulaSospechosa(ctx, identificacion); err != nil {
+		return "", err
+	}
+	return identificacion, nil
+}
 
-RECOMENDACIÓN: Use el servicio de consulta por cédula que funciona con datos oficiales del SRI (gratuito y confiable)`)
-} // manejarConsulta maneja las peticiones POST al endpoint /api/consultar
+// manejarConsulta maneja las peticiones al endpoint /api/consultar: POST con la cédula en el
+// cuerpo JSON o GET con la cédula en el parámetro cedula (?cedula=1712345678). Las respuestas
+// GET llevan Last-Modified y respetan If-Modified-Since.
 func manejarConsulta(w http.ResponseWriter, r *http.Request) {
-	// Configurar headers CORS
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	// Los headers CORS y las peticiones preflight OPTIONS los maneja aplicarCORS
 	w.Header().Set("Content-Type", "application/json")
 
-	// Manejar preflight OPTIONS request
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
+	// Leer la cédula del cuerpo JSON (POST) o de la query string (GET)
+	var req CedulaRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := decodificarJSON(w, r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if strings.TrimSpace(req.Cedula) == "" {
+			var validacion ValidationError
+			validacion.Agregar("cedula", "El campo cedula es obligatorio")
+			writeError(w, r, validacion.Err())
+			return
+		}
+	case http.MethodGet:
+		if !r.URL.Query().Has("cedula") {
+			var validacion ValidationError
+			validacion.Agregar("cedula", "El parámetro cedula es obligatorio")
+			writeError(w, r, validacion.Err())
+			return
+		}
+		req.Cedula = r.URL.Query().Get("cedula")
+	default:
+		writeError(w, r, errMetodoNoPermitido)
 		return
 	}
 
-	// Verificar que sea una petición POST
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Método no permitido"})
+	// Normalizar y validar la identificación
+	identificacion, err := validarIdentificacion(r.Context(), req.Cedula)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	req.Cedula = identificacion
 
-	// Decodificar el JSON de la petición
-	var req CedulaRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "JSON inválido"})
+	// Validar el formato de nombre solicitado antes de consultar
+	formatoNombre := r.URL.Query().Get("nameFormat")
+	if formatoNombre != "" && formatoNombre != formatoApellidosNombres {
+		writeError(w, r, errFormatoNombreInvalido)
 		return
 	}
 
-	// Validar la cédula
-	if !validarCedula(req.Cedula) {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Cédula inválida. Debe contener exactamente 10 dígitos"})
+	// Agregar la latencia sintética configurada para pruebas de resiliencia
+	inyectarLatencia(r.Context(), req.Cedula)
+
+	// En modo dryRun se devuelve la petición planificada sin llamar al SRI
+	if esDryRun(r) {
+		// Se listan todas las URLs base en el orden en que se intentarían
+		peticiones, err := clienteSRI.Plan(r.Context(), req.Cedula)
+		if err != nil {
+			writeError(w, r, errInterno)
+			return
+		}
+		plan := PlanConsulta{DryRun: true, Fuente: "SRI", TipoPersona: cedula.PersonType(req.Cedula)}
+		for _, peticion := range peticiones {
+			plan.Peticiones = append(plan.Peticiones, planificarPeticion(peticion))
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(plan)
 		return
 	}
 
-	// Realizar la consulta
-	resultado, err := consultarCedula(req.Cedula)
+	// Realizar la consulta, respetando la antigüedad máxima que acepte el cliente
+	resultado, err := clienteSRI.Lookup(conEdadMaxima(r).Context(), req.Cedula)
+	contarConsulta("consultar", fuenteConsulta(resultado), err)
 	if err != nil {
-		if strings.Contains(err.Error(), "no encontrada") {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Cédula no encontrada"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Error interno del servidor al consultar"})
-		}
+		writeError(w, r, err)
+		return
+	}
+
+	// Agregar el nombre en el formato solicitado
+	if formatoNombre == formatoApellidosNombres {
+		resultado.NombreFormateado = formatearApellidosNombres(resultado.Nombre, resultado.Apellido)
+	}
+
+	// Recortar los nombres para los clientes no privilegiados si está configurado
+	if enmascararPII.Load() && !esClientePrivilegiado(r) {
+		resultado = enmascararResultado(resultado)
+	}
+
+	// Las consultas GET se pueden revalidar con If-Modified-Since
+	if r.Method == http.MethodGet && responderNoModificado(w, r, resultado) {
 		return
 	}
 
-	// Responder con los datos encontrados
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resultado)
+	// Responder con los datos encontrados en el formato negociado
+	escribirResultadoCedula(w, r, resultado)
+}
+
+// envolverAPI aplica a un endpoint de la API los middlewares configurados. El bloqueo de bots,
+// la validación de origen, el límite por IP, el mantenimiento y CORS revisan su ajuste en cada petición
+// para poder cambiarse en caliente; la firma solo se configura al arrancar.
+func envolverAPI(h http.Handler) http.Handler {
+	h = rechazarBots(h)
+	h = validarOrigen(h)
+	h = requerirClaveAPI(h)
+	h = limitarPorIP(h)
+	h = responderMantenimiento(h)
+	if claveFirma != nil {
+		h = firmarRespuestas(claveFirma, h)
+	}
+	// CORS va por fuera para que las respuestas de error de los middlewares también lo lleven,
+	// y el logger de la petición por fuera de todo para que lo usen todos los middlewares
+	h = aplicarCORS(h)
+	return registrarPeticion(h)
 }
 
 // manejarConsultaPorNombres maneja las peticiones POST al endpoint /api/consultar-nombres
 func manejarConsultaPorNombres(w http.ResponseWriter, r *http.Request) {
-	// Configurar headers CORS
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	// Los headers CORS y las peticiones preflight OPTIONS los maneja aplicarCORS
 	w.Header().Set("Content-Type", "application/json")
 
-	// Manejar preflight OPTIONS request
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
 	// Verificar que sea una petición POST
 	if r.Method != "POST" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Método no permitido"})
+		writeError(w, r, errMetodoNoPermitido)
 		return
 	}
 
 	// Decodificar el JSON de la petición
 	var req NombresRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "JSON inválido"})
+	if err := decodificarJSON(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Validar que se proporcionen nombres y apellidos, indicando qué campo falla
+	if err := validarNombresRequest(req); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	// Validar que se proporcionen nombres y apellidos
-	if strings.TrimSpace(req.Nombres) == "" || strings.TrimSpace(req.Apellidos) == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Se requieren nombres y apellidos"})
+	// Rechazar búsquedas demasiado generales si está configurado
+	if exigirNombresEspecificos.Load() && busquedaDemasiadoGeneral(req.Nombres, req.Apellidos) {
+		writeError(w, r, errBusquedaGeneral)
+		return
+	}
+
+	// Agregar la latencia sintética configurada para pruebas de resiliencia
+	inyectarLatencia(r.Context(), "")
+
+	// En modo dryRun se informa que la consulta por nombres no llama a ninguna fuente externa
+	if esDryRun(r) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(PlanConsulta{
+			DryRun:     true,
+			Fuente:     "alternativas-legales",
+			Peticiones: []PeticionPlanificada{},
+		})
 		return
 	}
 
 	// Realizar la "consulta" (que en realidad retorna información sobre alternativas legales)
-	resultado, err := consultarPorNombres(req.Nombres, req.Apellidos)
+	liberar, err := adquirirFuente(r.Context(), "nombres")
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	inicio := time.Now()
+	resultado, err := cedula.LookupByName(r.Context(), req.Nombres, req.Apellidos)
+	latencias["nombres"].registrar(time.Since(inicio))
+	liberar()
+	contarConsulta("consultar-nombres", fuenteMetricaNombres, err)
+	if errors.Is(err, cedula.ErrNameLookupUnavailable) {
 		// En lugar de retornar error, enviamos una respuesta informativa
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":           false,
-			"nombres":           req.Nombres,
-			"apellidos":         req.Apellidos,
-			"message":           "Consulta por nombres no disponible a través de APIs públicas gratuitas",
-			"alternatives_info": true,
-			"error_details":     err.Error(),
+		escribirRespuesta(w, r, http.StatusOK, AlternativasResponse{
+			Success:          false,
+			Nombres:          req.Nombres,
+			Apellidos:        req.Apellidos,
+			Message:          "Consulta por nombres no disponible a través de APIs públicas gratuitas",
+			AlternativesInfo: true,
+			ErrorDetails:     err.Error(),
 		})
 		return
 	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
 	// Responder con los datos encontrados (si alguna vez funcionara)
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resultado)
+	escribirRespuesta(w, r, http.StatusOK, resultado)
+}
+
+// cargarFuenteRespaldo devuelve la URL de la fuente de respaldo (FALLBACK_LOOKUP_URL) si está
+// habilitada con ENABLE_FALLBACK, o vacía si no
+func cargarFuenteRespaldo() string {
+	if !leerBoolEnv("ENABLE_FALLBACK", false) {
+		return ""
+	}
+	direccion := strings.TrimSpace(os.Getenv("FALLBACK_LOOKUP_URL"))
+	if direccion == "" {
+		slog.Warn("ENABLE_FALLBACK requiere FALLBACK_LOOKUP_URL; la fuente de respaldo no se habilitó")
+		return ""
+	}
+	if u, err := url.Parse(direccion); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		slog.Warn("FALLBACK_LOOKUP_URL inválida; la fuente de respaldo no se habilitó", "valor", direccion)
+		return ""
+	}
+	return direccion
+}
+
+// cargarCabecerasSRI lee las cabeceras de las peticiones al SRI: SRI_USER_AGENT reemplaza los
+// User-Agent por defecto (varios separados por "|" se rotan) y SRI_HEADERS agrega o reemplaza
+// cabeceras con entradas "Nombre: valor" separadas por "|"
+func cargarCabecerasSRI() ([]string, http.Header) {
+	var agentes []string
+	for _, agente := range strings.Split(os.Getenv("SRI_USER_AGENT"), "|") {
+		if agente = strings.TrimSpace(agente); agente != "" {
+			agentes = append(agentes, agente)
+		}
+	}
+
+	var cabeceras http.Header
+	for _, entrada := range strings.Split(os.Getenv("SRI_HEADERS"), "|") {
+		if strings.TrimSpace(entrada) == "" {
+			continue
+		}
+		nombre, valor, ok := strings.Cut(entrada, ":")
+		nombre, valor = strings.TrimSpace(nombre), strings.TrimSpace(valor)
+		if !ok || nombre == "" {
+			slog.Warn("Entrada inválida en SRI_HEADERS, se ignora", "entrada", entrada)
+			continue
+		}
+		if cabeceras == nil {
+			cabeceras = http.Header{}
+		}
+		cabeceras.Set(nombre, valor)
+	}
+	return agentes, cabeceras
+}
+
+// cargarCircuito configura el circuit breaker del SRI: se abre tras CIRCUIT_BREAKER_THRESHOLD
+// consultas fallidas seguidas (0 lo desactiva) y vuelve a probar pasados
+// CIRCUIT_BREAKER_COOLDOWN_SECONDS
+func cargarCircuito() *cedula.Breaker {
+	umbral := cedula.DefaultBreakerThreshold
+	if valor := os.Getenv("CIRCUIT_BREAKER_THRESHOLD"); valor != "" {
+		n, err := strconv.Atoi(valor)
+		if err != nil || n < 0 {
+			slog.Warn("Valor inválido para CIRCUIT_BREAKER_THRESHOLD, usando el valor por defecto", "valor", valor, "porDefecto", umbral)
+		} else {
+			umbral = n
+		}
+	}
+	if umbral == 0 {
+		return nil
+	}
+
+	enfriamiento := cedula.DefaultBreakerCooldown
+	if valor := os.Getenv("CIRCUIT_BREAKER_COOLDOWN_SECONDS"); valor != "" {
+		segundos, err := parsearSegundos(valor)
+		if err != nil {
+			slog.Warn("Valor inválido para CIRCUIT_BREAKER_COOLDOWN_SECONDS, usando el valor por defecto", "valor", valor, "error", err, "porDefecto", enfriamiento.String())
+		} else {
+			enfriamiento = segundos
+		}
+	}
+	return cedula.NewBreaker(umbral, enfriamiento)
+}
+
+// puertoPorDefecto es el puerto en el que escucha el servidor si no se indica otro
+const puertoPorDefecto = "8085"
+
+// resolverPuerto elige el puerto del flag -port, luego de la variable PORT y finalmente el
+// puerto por defecto. El valor 0 deja que el sistema operativo elija un puerto libre.
+func resolverPuerto(flagPuerto, envPuerto string) (int, error) {
+	valor := puertoPorDefecto
+	if flagPuerto != "" {
+		valor = flagPuerto
+	} else if envPuerto != "" {
+		valor = envPuerto
+	}
+
+	puerto, err := strconv.Atoi(strings.TrimSpace(valor))
+	if err != nil || puerto < 0 || puerto > 65535 {
+		return 0, fmt.Errorf("puerto inválido %q: debe ser un número entre 0 y 65535", valor)
+	}
+	return puerto, nil
+}
+
+// abrirListener escucha en el puerto indicado y devuelve el puerto real, que con 0 es el que
+// eligió el sistema operativo
+func abrirListener(puerto int) (net.Listener, int, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", puerto))
+	if err != nil {
+		return nil, 0, err
+	}
+	return listener, listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// anunciarServidor escribe el mensaje de inicio con el puerto real para que los scripts lo descubran
+func anunciarServidor(w io.Writer, puerto int) {
+	fmt.Fprintf(w, "🚀 Servidor iniciado en http://localhost:%d\n", puerto)
+	fmt.Fprintln(w, "📁 Sirviendo archivos estáticos desde ./ui/static/")
+	fmt.Fprintln(w, "🔍 Endpoint de consulta por cédula disponible en /api/consultar")
+	fmt.Fprintln(w, "👤 Endpoint de consulta por nombres disponible en /api/consultar-nombres")
+	fmt.Fprintln(w, "📋 Endpoint de consulta por lote disponible en /api/consultar-lote")
 }
 
 func main() {
+	// Registrar en JSON por la salida de errores; el nivel (LOG_LEVEL) se aplica con los ajustes recargables
+	slog.SetDefault(nuevoRegistro(os.Stderr))
+
+	comando, argumentos, err := parsearComando(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, usoCLI(filepath.Base(os.Args[0])))
+		os.Exit(2)
+	}
+	switch comando {
+	case comandoServir:
+		servir(argumentos)
+	case comandoCedula:
+		os.Exit(ejecutarConsultaCedula(argumentos[0], os.Stdout, os.Stderr))
+	case comandoNombres:
+		os.Exit(ejecutarConsultaNombres(argumentos[0], argumentos[1], os.Stdout, os.Stderr))
+	}
+}
+
+// cargarConfigInicial carga el archivo de configuración (CONFIG_FILE) y devuelve su ruta.
+// Las variables de entorno del proceso tienen prioridad sobre las del archivo.
+func cargarConfigInicial() string {
+	registrarEntorno()
+	archivoConfig := os.Getenv("CONFIG_FILE")
+	if archivoConfig != "" {
+		if err := cargarArchivoConfig(archivoConfig); err != nil {
+			terminar("Error al cargar el archivo de configuración", err)
+		}
+	}
+	return archivoConfig
+}
+
+// nuevoMux registra las rutas del servidor según la configuración cargada. Se usa un mux propio
+// para que net/http/pprof no quede expuesto en el mux por defecto.
+func nuevoMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
 	// Configurar el servidor de archivos estáticos
-	fs := http.FileServer(http.Dir("./ui/static/"))
-	http.Handle("/", fs)
+	// (con un índice JSON de la API en / si la interfaz no está presente)
+	mux.Handle("/", manejarRaiz("./ui/static/"))
 
 	// Configurar los endpoints de la API
-	http.HandleFunc("/api/consultar", manejarConsulta)
-	http.HandleFunc("/api/consultar-nombres", manejarConsultaPorNombres)
+	mux.Handle("/api/consultar", envolverAPI(http.HandlerFunc(manejarConsulta)))
+	mux.Handle(rutaConsultar, envolverAPI(http.HandlerFunc(manejarConsultaPorRuta)))
+	mux.Handle("/api/consultar-nombres", envolverAPI(http.HandlerFunc(manejarConsultaPorNombres)))
+	mux.Handle("/api/consultar-lote", envolverAPI(http.HandlerFunc(manejarConsultaLote)))
+	mux.Handle("/api/validar", envolverAPI(http.HandlerFunc(manejarValidacion)))
+	mux.Handle(rutaDecodificar, envolverAPI(http.HandlerFunc(manejarDecodificacion)))
+	mux.Handle("/stats/latency", envolverAPI(http.HandlerFunc(manejarEstadisticasLatencia)))
+	mux.Handle("/openapi.json", aplicarCORS(http.HandlerFunc(manejarOpenAPI)))
+
+	// Configurar las métricas de Prometheus
+	registroMetricas := prometheus.NewRegistry()
+	registrarMetricas(registroMetricas)
+	mux.Handle("/metrics", promhttp.HandlerFor(registroMetricas, promhttp.HandlerOpts{}))
+
+	// Configurar las comprobaciones de salud (liveness y readiness)
+	mux.HandleFunc("/healthz", manejarSalud)
+	mux.HandleFunc("/readyz", manejarPreparacion)
+
+	// Publicar la clave pública si las respuestas se firman
+	if claveFirma != nil {
+		mux.HandleFunc("/.well-known/pubkey", manejarClavePublica(claveFirma))
+	}
+
+	// Configurar el perfilado (solo con ENABLE_PPROF y protegido con ADMIN_API_KEY)
+	if leerBoolEnv("ENABLE_PPROF", false) {
+		registrarPprof(mux, os.Getenv("ADMIN_API_KEY"))
+	}
 
-	// Configurar el puerto
-	puerto := ":8085"
+	return mux
+}
+
+// servir arranca el servidor HTTP (subcomando serve) con los flags indicados
+func servir(argumentos []string) {
+	// Cargar el archivo de configuración, que se relee al recibir SIGHUP
+	archivoConfig := cargarConfigInicial()
 
-	fmt.Printf("🚀 Servidor iniciado en http://localhost%s\n", puerto)
-	fmt.Println("📁 Sirviendo archivos estáticos desde ./ui/static/")
-	fmt.Println("🔍 Endpoint de consulta por cédula disponible en /api/consultar")
-	fmt.Println("👤 Endpoint de consulta por nombres disponible en /api/consultar-nombres")
+	// Verificar el algoritmo del dígito verificador antes de atender peticiones
+	if err := autoverificarValidacion(identificacionValida, casosAutoverificacion); err != nil {
+		terminar("La autoverificación de la validación de cédulas falló", err)
+	}
 
-	// Iniciar el servidor
-	if err := http.ListenAndServe(puerto, nil); err != nil {
-		log.Fatal("Error al iniciar el servidor: ", err)
+	// Configurar el puerto (flag -port, variable PORT o 8085; 0 elige un puerto libre)
+	flags := flag.NewFlagSet(comandoServir, flag.ExitOnError)
+	flagPuerto := flags.String("port", "", "puerto en el que escucha el servidor (por defecto $PORT o "+puertoPorDefecto+")")
+	flags.Parse(argumentos)
+
+	puerto, err := resolverPuerto(*flagPuerto, os.Getenv("PORT"))
+	if err != nil {
+		terminar("Error de configuración", err)
+	}
+
+	// Configurar la firma de respuestas (SIGN_RESPONSES, con semilla opcional en SIGNING_KEY_SEED)
+	if leerBoolEnv("SIGN_RESPONSES", false) {
+		clave, err := cargarClaveFirma(os.Getenv("SIGNING_KEY_SEED"))
+		if err != nil {
+			terminar("Error al cargar la clave de firma", err)
+		}
+		claveFirma = clave
+	}
+
+	// Configurar la concurrencia máxima de cada fuente (SOURCE_CONCURRENCY="sri=10,nombres=2")
+	if valor := os.Getenv("SOURCE_CONCURRENCY"); valor != "" {
+		limites, err := parsearConcurrencia(valor)
+		if err != nil {
+			slog.Warn("SOURCE_CONCURRENCY inválido, usando los valores por defecto", "error", err)
+		} else {
+			semaforosFuente = crearSemaforos(limites)
+		}
+	}
+
+	// Configurar el timeout de las peticiones al SRI
+	timeoutSRI = leerTimeoutSRI()
+
+	// Configurar el tamaño máximo del cuerpo de las peticiones (MAX_BODY_BYTES)
+	if valor := os.Getenv("MAX_BODY_BYTES"); valor != "" {
+		limite, err := strconv.ParseInt(valor, 10, 64)
+		if err != nil || limite <= 0 {
+			slog.Warn("Valor inválido para MAX_BODY_BYTES, usando el valor por defecto", "valor", valor, "porDefecto", limiteCuerpo)
+		} else {
+			limiteCuerpo = limite
+		}
+	}
+
+	// Configurar el proxy de salida hacia las fuentes externas (UPSTREAM_PROXY_URL)
+	configurarProxyUpstream()
+
+	// Configurar el presupuesto diario de llamadas al SRI (DAILY_UPSTREAM_BUDGET, 0 sin límite),
+	// que se reinicia a la medianoche de BUDGET_TIMEZONE (por defecto America/Guayaquil)
+	if valor := os.Getenv("DAILY_UPSTREAM_BUDGET"); valor != "" {
+		limite, err := strconv.Atoi(valor)
+		if err != nil || limite < 0 {
+			terminar("Error de configuración", fmt.Errorf("DAILY_UPSTREAM_BUDGET inválido (%q)", valor))
+		}
+		if limite > 0 {
+			nombreZona := os.Getenv("BUDGET_TIMEZONE")
+			if nombreZona == "" {
+				nombreZona = "America/Guayaquil"
+			}
+			zona, err := time.LoadLocation(nombreZona)
+			if err != nil {
+				slog.Warn("Zona horaria inválida, usando la hora local", "zona", nombreZona, "error", err)
+				zona = time.Local
+			}
+			presupuestoUpstream = nuevoPresupuestoDiario(limite, zona)
+		}
+	}
+
+	// Configurar la caché de resultados del SRI (CACHE_SIZE entradas, 0 la desactiva, y
+	// CACHE_COMPACT para guardarlas serializadas, CACHE_PATH para guardarlas en disco o REDIS_URL
+	// para compartirlas entre réplicas; las vigencias CACHE_TTL_SECONDS, CACHE_TTL_DEBT_SECONDS y
+	// CACHE_TTL_RUC_SECONDS, su variación CACHE_TTL_JITTER_PERCENT y el refresco anticipado
+	// CACHE_REFRESH_AHEAD_PERCENT se aplican con los ajustes recargables)
+	var cache cedula.Cache
+	tamanoCache := cedula.DefaultCacheSize
+	if valor := os.Getenv("CACHE_SIZE"); valor != "" {
+		tamano, err := strconv.Atoi(valor)
+		if err != nil || tamano < 0 {
+			slog.Warn("Valor inválido para CACHE_SIZE, usando el valor por defecto", "valor", valor, "porDefecto", tamanoCache)
+		} else {
+			tamanoCache = tamano
+		}
+	}
+	var cacheDisco *cedula.DiskCache
+	var cacheRedis *cedula.RedisCache
+	if direccion := os.Getenv("REDIS_URL"); direccion != "" && tamanoCache > 0 {
+		cacheRedis, err = cedula.NewRedisCache(direccion)
+		if err != nil {
+			terminar("Error de configuración", err)
+		}
+		// Si Redis no responde al arrancar se sigue igual: cada consulta lo trata como un fallo de la caché
+		ctx, cancelar := context.WithTimeout(context.Background(), 2*time.Second)
+		if err := cacheRedis.Ping(ctx); err != nil {
+			slog.Warn("Redis no responde; las consultas seguirán sin caché hasta que vuelva", "error", err)
+		}
+		cancelar()
+		cache = cacheRedis
+	} else if ruta := os.Getenv("CACHE_PATH"); ruta != "" && tamanoCache > 0 {
+		cacheDisco, err = cedula.NewDiskCache(ruta)
+		if err != nil {
+			terminar("Error al abrir la caché en disco", err)
+		}
+		cache = cacheDisco
+	} else if tamanoCache > 0 && leerBoolEnv("CACHE_COMPACT", false) {
+		cache = cedula.NewCompactCache(tamanoCache)
+	} else if tamanoCache > 0 {
+		cache = cedula.NewCache(tamanoCache)
+	}
+
+	// Configurar el cliente del SRI: URLs base (espejos o proxies separados por comas en
+	// SRI_BASE_URLS) y respuesta detallada con datos adicionales del contribuyente (DETAILED_RESPONSE)
+	clienteSRI = &cedula.Client{
+		HTTPClient:  nuevoClienteHTTP(),
+		Hosts:       cedula.NewHosts(os.Getenv("SRI_BASE_URLS")),
+		Cache:       cache,
+		FallbackURL: cargarFuenteRespaldo(),
+		Detailed:    leerBoolEnv("DETAILED_RESPONSE", false),
+		BeforeCall:  antesDeLlamarSRI,
+		AfterCall:   despuesDeLlamarSRI,
+		Breaker:     cargarCircuito(),
+	}
+	clienteSRI.UserAgents, clienteSRI.Headers = cargarCabecerasSRI()
+
+	// Aplicar los ajustes que se pueden recargar en caliente con SIGHUP
+	aplicarAjustesRecargables()
+
+	// Configurar la cantidad de consultas simultáneas de cada lote (BATCH_WORKERS)
+	if valor := os.Getenv("BATCH_WORKERS"); valor != "" {
+		trabajadores, err := strconv.Atoi(valor)
+		if err != nil || trabajadores <= 0 {
+			slog.Warn("Valor inválido para BATCH_WORKERS, usando el valor por defecto", "valor", valor, "porDefecto", trabajadoresLote)
+		} else {
+			trabajadoresLote = trabajadores
+		}
+	}
+
+	// Configurar la ventana de agrupación de errores repetidos del SRI (0 la desactiva)
+	if valor := os.Getenv("ERROR_LOG_WINDOW_SECONDS"); valor != "" {
+		segundos, err := strconv.Atoi(valor)
+		if err != nil || segundos < 0 {
+			slog.Warn("Valor inválido para ERROR_LOG_WINDOW_SECONDS, usando el valor por defecto", "valor", valor, "porDefecto", erroresSRI.ventana.String())
+		} else {
+			erroresSRI = nuevoRegistroAgrupado(time.Duration(segundos) * time.Second)
+		}
+	}
+
+	mux := nuevoMux()
+
+	// Abrir el listener antes de anunciar el servidor para conocer el puerto real
+	listener, puertoReal, err := abrirListener(puerto)
+	if err != nil {
+		terminar("Error al iniciar el servidor", err)
+	}
+	anunciarServidor(os.Stdout, puertoReal)
+
+	// Iniciar el servidor y cerrarlo ordenadamente al recibir SIGINT o SIGTERM
+	senales := make(chan os.Signal, 1)
+	signal.Notify(senales, os.Interrupt, syscall.SIGTERM)
+
+	// Recargar la configuración al recibir SIGHUP, sin interrumpir las peticiones en curso
+	recargas := make(chan os.Signal, 1)
+	signal.Notify(recargas, syscall.SIGHUP)
+	go atenderRecargas(recargas, archivoConfig)
+
+	servidor := &http.Server{Handler: mux}
+	if err := ejecutarServidor(servidor, listener, senales); err != nil {
+		terminar("Error en el servidor", err)
+	}
+	if cacheDisco != nil {
+		if err := cacheDisco.Close(); err != nil {
+			slog.Warn("Error al cerrar la caché en disco", "error", err)
+		}
+	}
+	if cacheRedis != nil {
+		cacheRedis.Close()
 	}
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// estadoMantenimiento describe el modo mantenimiento activo de la API
+type estadoMantenimiento struct {
+	activo      bool
+	mensaje     string
+	reintentoEn time.Duration
+}
+
+// mensajeMantenimientoPorDefecto se usa si MAINTENANCE_MESSAGE no está configurado
+const mensajeMantenimientoPorDefecto = "Servicio en mantenimiento. Intente nuevamente más tarde"
+
+// mantenimiento guarda el estado actual; se reemplaza de forma atómica para poder cambiarlo
+// en caliente sin afectar las peticiones en curso
+var mantenimiento atomic.Pointer[estadoMantenimiento]
+
+func init() {
+	mantenimiento.Store(&estadoMantenimiento{mensaje: mensajeMantenimientoPorDefecto, reintentoEn: 5 * time.Minute})
+}
+
+// configurarMantenimiento activa o desactiva el modo mantenimiento con su mensaje y Retry-After
+func configurarMantenimiento(activo bool, mensaje string, reintentoEn time.Duration) {
+	if mensaje == "" {
+		mensaje = mensajeMantenimientoPorDefecto
+	}
+	mantenimiento.Store(&estadoMantenimiento{activo: activo, mensaje: mensaje, reintentoEn: reintentoEn})
+}
+
+// responderMantenimiento devuelve 503 con el mensaje configurado mientras el modo mantenimiento
+// está activo; en caso contrario deja pasar la petición
+func responderMantenimiento(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		estado := mantenimiento.Load()
+		if !estado.activo {
+			siguiente.ServeHTTP(w, r)
+			return
+		}
+
+		escribirMantenimiento(w, r, estado)
+	})
+}
+
+// escribirMantenimiento responde 503 con el mensaje y el Retry-After del modo mantenimiento
+func escribirMantenimiento(w http.ResponseWriter, r *http.Request, estado *estadoMantenimiento) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(estado.reintentoEn.Seconds())))
+	writeError(w, r, &errorAPI{
+		codigo:  CodigoMantenimiento,
+		estado:  http.StatusServiceUnavailable,
+		mensaje: estado.mensaje,
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// configurarMantenimientoPrueba activa el modo mantenimiento con el mensaje y Retry-After dados
+// y lo desactiva al terminar la prueba
+func configurarMantenimientoPrueba(t *testing.T, mensaje string, reintentoEn time.Duration) {
+	t.Helper()
+	configurarMantenimiento(true, mensaje, reintentoEn)
+	t.Cleanup(func() { configurarMantenimiento(false, "", time.Minute) })
+}
+
+func TestResponderMantenimientoDejaPasarSiEstaInactivo(t *testing.T) {
+	configurarMantenimiento(false, "", time.Minute)
+
+	llamado := false
+	h := responderMantenimiento(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		llamado = true
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil))
+
+	if !llamado || rec.Code != http.StatusOK {
+		t.Errorf("llamado = %v, estado = %d; se esperaba que la petición pasara", llamado, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "" {
+		t.Errorf("Retry-After = %q fuera de mantenimiento", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestResponderMantenimientoDevuelve503(t *testing.T) {
+	casos := []struct {
+		nombre, mensaje, mensajeEsperado string
+		reintentoEn                      time.Duration
+		retryAfter                       string
+	}{
+		{"mensaje configurado", "Actualizando la base de datos", "Actualizando la base de datos", 90 * time.Second, "90"},
+		{"mensaje por defecto", "", mensajeMantenimientoPorDefecto, 5 * time.Minute, "300"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			configurarMantenimientoPrueba(t, caso.mensaje, caso.reintentoEn)
+
+			h := responderMantenimiento(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("la petición no debía llegar al handler durante el mantenimiento")
+			}))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil))
+
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Fatalf("estado = %d, se esperaba 503", rec.Code)
+			}
+			if retryAfter := rec.Header().Get("Retry-After"); retryAfter != caso.retryAfter {
+				t.Errorf("Retry-After = %q, se esperaba %q", retryAfter, caso.retryAfter)
+			}
+			var respuesta ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Code != CodigoMantenimiento || respuesta.Error != caso.mensajeEsperado {
+				t.Errorf("respuesta = %+v, se esperaba código %s y mensaje %q", respuesta, CodigoMantenimiento, caso.mensajeEsperado)
+			}
+		})
+	}
+}
+
+func TestMantenimientoSeAplicaALaAPIYNoALaSalud(t *testing.T) {
+	usarSRIProhibido(t)
+	configurarMantenimientoPrueba(t, "", time.Minute)
+	mux := nuevoMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("/api/consultar: estado = %d, Retry-After = %q; se esperaba 503 y 60", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// La sonda de vida sigue respondiendo para que el orquestador no reinicie el contenedor
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz: estado = %d, se esperaba 200", rec.Code)
+	}
+
+	// Al desactivarlo en caliente la API vuelve a responder sin reiniciar
+	configurarMantenimiento(false, "", time.Minute)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065&dryRun=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/api/consultar tras desactivar el mantenimiento: estado = %d, se esperaba 200", rec.Code)
+	}
+}
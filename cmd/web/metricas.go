@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"consulta-cedula-app/pkg/cedula"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Resultados posibles de una consulta en consultasTotales
+const (
+	resultadoExito        = "success"
+	resultadoNoEncontrada = "not_found"
+	resultadoError        = "error"
+)
+
+// Fuentes posibles de una consulta en consultasTotales
+const (
+	fuenteMetricaSRI      = "sri"
+	fuenteMetricaRespaldo = "fallback"
+	fuenteMetricaNombres  = "names"
+)
+
+// valorFueraDeCatalogo reemplaza en las etiquetas cualquier valor que no esté en etiquetasAcotadas
+const valorFueraDeCatalogo = "other"
+
+// etiquetasAcotadas son los únicos valores permitidos de cada etiqueta de consultasTotales. Cada
+// valor distinto crea una serie nueva en Prometheus, así que nunca deben llegar a las etiquetas
+// valores abiertos como cédulas o nombres.
+var etiquetasAcotadas = map[string]map[string]bool{
+	"endpoint": {"consultar": true, "consultar-nombres": true, "consultar-lote": true},
+	"source":   {fuenteMetricaSRI: true, fuenteMetricaRespaldo: true, fuenteMetricaNombres: true},
+	"outcome":  {resultadoExito: true, resultadoNoEncontrada: true, resultadoError: true},
+}
+
+// consultasTotales cuenta las consultas realizadas por endpoint, fuente y resultado
+var consultasTotales = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cedula_lookups_total",
+	Help: "Consultas realizadas, por endpoint, fuente (sri, fallback, names) y resultado (success, not_found, error).",
+}, []string{"endpoint", "source", "outcome"})
+
+// latenciaSRI mide la duración de cada llamada a un host del SRI
+var latenciaSRI = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    "cedula_sri_request_duration_seconds",
+	Help:    "Duración de las llamadas a la API del SRI, en segundos.",
+	Buckets: prometheus.DefBuckets,
+})
+
+// registrarMetricas registra los colectores de la aplicación y los del proceso y el runtime de Go
+func registrarMetricas(registro prometheus.Registerer) {
+	registro.MustRegister(
+		consultasTotales,
+		latenciaSRI,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// valorEtiqueta devuelve el valor si está en el catálogo de la etiqueta y valorFueraDeCatalogo en
+// caso contrario. El valor rechazado no se registra porque puede ser un dato personal.
+func valorEtiqueta(etiqueta, valor string) string {
+	if etiquetasAcotadas[etiqueta][valor] {
+		return valor
+	}
+	slog.Warn("Valor fuera del catálogo para una etiqueta de métricas", "etiqueta", etiqueta)
+	return valorFueraDeCatalogo
+}
+
+// fuenteConsulta devuelve la fuente de una consulta por cédula para las métricas: la de respaldo
+// si los datos vinieron de ella y el SRI en cualquier otro caso, incluidos los errores
+func fuenteConsulta(resultado *cedula.Result) string {
+	if resultado != nil && resultado.Fuente == cedula.SourceFallback {
+		return fuenteMetricaRespaldo
+	}
+	return fuenteMetricaSRI
+}
+
+// contarConsulta registra en consultasTotales el resultado de una consulta del endpoint a la fuente
+func contarConsulta(endpoint, fuente string, err error) {
+	resultado := resultadoExito
+	switch {
+	case errors.Is(err, cedula.ErrNotFound), errors.Is(err, cedula.ErrNameLookupUnavailable):
+		resultado = resultadoNoEncontrada
+	case err != nil:
+		resultado = resultadoError
+	}
+	consultasTotales.WithLabelValues(
+		valorEtiqueta("endpoint", endpoint),
+		valorEtiqueta("source", fuente),
+		valorEtiqueta("outcome", resultado),
+	).Inc()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"consulta-cedula-app/pkg/cedula"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// leerMetrica consulta /metrics en el mux y devuelve el valor de la serie indicada (nombre con
+// sus etiquetas, tal como aparece en el formato de texto), o 0 si todavía no existe
+func leerMetrica(t *testing.T, mux http.Handler, serie string) float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/metrics: estado = %d", rec.Code)
+	}
+
+	lector := bufio.NewScanner(rec.Body)
+	for lector.Scan() {
+		nombre, valor, ok := strings.Cut(lector.Text(), " ")
+		if !ok || nombre != serie {
+			continue
+		}
+		numero, err := strconv.ParseFloat(valor, 64)
+		if err != nil {
+			t.Fatalf("valor inválido para %s: %q", serie, valor)
+		}
+		return numero
+	}
+	return 0
+}
+
+func TestMetricasCuentanLasConsultas(t *testing.T) {
+	usarSRIPrueba(t)
+	mux := nuevoMux()
+
+	exitos := `cedula_lookups_total{endpoint="consultar",outcome="success",source="sri"}`
+	noEncontradas := `cedula_lookups_total{endpoint="consultar",outcome="not_found",source="sri"}`
+	exitosAntes, noEncontradasAntes := leerMetrica(t, mux, exitos), leerMetrica(t, mux, noEncontradas)
+
+	for _, id := range []string{"1710034065", "0912345675", cedulaInexistente} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula="+id, nil))
+		if id != cedulaInexistente && rec.Code != http.StatusOK {
+			t.Fatalf("%s: estado = %d: %s", id, rec.Code, rec.Body.String())
+		}
+	}
+
+	if got := leerMetrica(t, mux, exitos) - exitosAntes; got != 2 {
+		t.Errorf("consultas exitosas contadas = %v, se esperaban 2", got)
+	}
+	if got := leerMetrica(t, mux, noEncontradas) - noEncontradasAntes; got != 1 {
+		t.Errorf("consultas no encontradas contadas = %v, se esperaba 1", got)
+	}
+}
+
+func TestMetricasIncluyenLaLatenciaDelSRI(t *testing.T) {
+	usarSRIPrueba(t)
+	clienteSRI.AfterCall = despuesDeLlamarSRI
+	ultimoExito := ultimoExitoSRI.Load()
+	t.Cleanup(func() { ultimoExitoSRI.Store(ultimoExito) })
+	mux := nuevoMux()
+
+	antes := leerMetrica(t, mux, "cedula_sri_request_duration_seconds_count")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if got := leerMetrica(t, mux, "cedula_sri_request_duration_seconds_count") - antes; got != 1 {
+		t.Errorf("llamadas al SRI medidas = %v, se esperaba 1", got)
+	}
+}
+
+func TestMetricasConEtiquetasAcotadas(t *testing.T) {
+	usarSRIPrueba(t)
+	sinLimites(t)
+	mux := nuevoMux()
+
+	// Consultas con muchas cédulas distintas, válidas, inválidas e inexistentes
+	for _, id := range append([]string{"1710034064", cedulaInexistente, "abc"}, cedulasPrueba...) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/consultar?cedula="+id, nil))
+	}
+	consultarLote(t, context.Background(), cedulasPrueba)
+
+	// Un valor abierto pasado por error como etiqueta se reemplaza por el valor fuera de catálogo
+	contarConsulta("1710034065", "PEREZ LOPEZ JUAN CARLOS", nil)
+
+	registro := prometheus.NewRegistry()
+	registro.MustRegister(consultasTotales)
+	familias, err := registro.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fueraDeCatalogo := false
+	for _, familia := range familias {
+		for _, metrica := range familia.GetMetric() {
+			for _, par := range metrica.GetLabel() {
+				valor := par.GetValue()
+				if valor == valorFueraDeCatalogo {
+					fueraDeCatalogo = true
+					continue
+				}
+				if !etiquetasAcotadas[par.GetName()][valor] {
+					t.Errorf("la etiqueta %s tiene el valor %q, que no está en su catálogo", par.GetName(), valor)
+				}
+			}
+		}
+	}
+	if !fueraDeCatalogo {
+		t.Error("el valor abierto no se contó con el valor fuera de catálogo")
+	}
+}
+
+func TestValorEtiqueta(t *testing.T) {
+	casos := []struct {
+		etiqueta, valor, esperado string
+	}{
+		{"endpoint", "consultar-lote", "consultar-lote"},
+		{"source", fuenteMetricaRespaldo, fuenteMetricaRespaldo},
+		{"outcome", resultadoNoEncontrada, resultadoNoEncontrada},
+		{"endpoint", "/api/consultar?cedula=1710034065", valorFueraDeCatalogo},
+		{"source", "1710034065", valorFueraDeCatalogo},
+		{"outcome", "PEREZ LOPEZ", valorFueraDeCatalogo},
+		{"cedula", "1710034065", valorFueraDeCatalogo},
+	}
+	for _, caso := range casos {
+		if obtenido := valorEtiqueta(caso.etiqueta, caso.valor); obtenido != caso.esperado {
+			t.Errorf("valorEtiqueta(%q, %q) = %q, se esperaba %q", caso.etiqueta, caso.valor, obtenido, caso.esperado)
+		}
+	}
+}
+
+func TestFuenteConsulta(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		resultado *cedula.Result
+		esperada  string
+	}{
+		{"error", nil, fuenteMetricaSRI},
+		{"SRI", &cedula.Result{Fuente: cedula.SourceSRI}, fuenteMetricaSRI},
+		{"respaldo", &cedula.Result{Fuente: cedula.SourceFallback}, fuenteMetricaRespaldo},
+	}
+	for _, caso := range casos {
+		if obtenida := fuenteConsulta(caso.resultado); obtenida != caso.esperada {
+			t.Errorf("%s: fuenteConsulta = %q, se esperaba %q", caso.nombre, obtenida, caso.esperada)
+		}
+	}
+}
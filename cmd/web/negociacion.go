@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+	"consulta-cedula-app/pkg/cedulapb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// tipoProtobuf es el Content-Type con el que se piden y envían respuestas protobuf
+const tipoProtobuf = "application/x-protobuf"
+
+// Formatos de respuesta que se negocian con el header Accept
+const (
+	formatoJSON     = "json"
+	formatoXML      = "xml"
+	formatoProtobuf = "protobuf"
+)
+
+// tiposFormato son los tipos de medio con los que un cliente puede pedir cada formato
+var tiposFormato = map[string][]string{
+	formatoJSON:     {"application/json"},
+	formatoXML:      {"application/xml", "text/xml"},
+	formatoProtobuf: {tipoProtobuf},
+}
+
+// rangoAccept es un rango de tipos de medio del header Accept con su calidad (q)
+type rangoAccept struct {
+	tipo, subtipo string
+	calidad       float64
+}
+
+// parsearAccept separa el header Accept en rangos de tipos de medio. Los rangos mal formados se
+// ignoran y un q inválido cuenta como 0
+func parsearAccept(accept string) []rangoAccept {
+	var rangos []rangoAccept
+	for _, elemento := range strings.Split(accept, ",") {
+		partes := strings.Split(elemento, ";")
+		tipo, subtipo, ok := strings.Cut(strings.ToLower(strings.TrimSpace(partes[0])), "/")
+		if !ok || tipo == "" || subtipo == "" {
+			continue
+		}
+		rango := rangoAccept{tipo: tipo, subtipo: subtipo, calidad: 1}
+		for _, parametro := range partes[1:] {
+			nombre, valor, _ := strings.Cut(parametro, "=")
+			if !strings.EqualFold(strings.TrimSpace(nombre), "q") {
+				continue
+			}
+			calidad, err := strconv.ParseFloat(strings.TrimSpace(valor), 64)
+			if err != nil || calidad < 0 || calidad > 1 {
+				calidad = 0
+			}
+			rango.calidad = calidad
+		}
+		rangos = append(rangos, rango)
+	}
+	return rangos
+}
+
+// calidadTipo devuelve la calidad que le da el cliente a un tipo de medio: la del rango más
+// específico que lo incluye, o 0 si ninguno lo incluye
+func calidadTipo(rangos []rangoAccept, tipoMedio string) float64 {
+	tipo, subtipo, _ := strings.Cut(tipoMedio, "/")
+	calidad, especificidad := 0.0, 0
+	for _, rango := range rangos {
+		var e int
+		switch {
+		case rango.tipo == tipo && rango.subtipo == subtipo:
+			e = 3
+		case rango.tipo == tipo && rango.subtipo == "*":
+			e = 2
+		case rango.tipo == "*" && rango.subtipo == "*":
+			e = 1
+		default:
+			continue
+		}
+		if e > especificidad {
+			calidad, especificidad = rango.calidad, e
+		}
+	}
+	return calidad
+}
+
+// negociarFormato elige, entre los formatos ofrecidos, el de mayor calidad según el header Accept.
+// A igual calidad gana el primero ofrecido; sin Accept o si ninguno es aceptable se usa JSON
+func negociarFormato(r *http.Request, ofrecidos ...string) string {
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return formatoJSON
+	}
+	rangos := parsearAccept(accept)
+	elegido, mejor := formatoJSON, 0.0
+	for _, formato := range ofrecidos {
+		for _, tipo := range tiposFormato[formato] {
+			if calidad := calidadTipo(rangos, tipo); calidad > mejor {
+				elegido, mejor = formato, calidad
+			}
+		}
+	}
+	return elegido
+}
+
+// aceptaProtobuf indica si protobuf es el formato preferido por el cliente según el header Accept
+func aceptaProtobuf(r *http.Request) bool {
+	return negociarFormato(r, formatoJSON, formatoXML, formatoProtobuf) == formatoProtobuf
+}
+
+// escribirProtobuf serializa un mensaje protobuf y lo escribe con el estado indicado
+func escribirProtobuf(w http.ResponseWriter, estado int, mensaje proto.Message) error {
+	datos, err := proto.Marshal(mensaje)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", tipoProtobuf)
+	w.WriteHeader(estado)
+	_, err = w.Write(datos)
+	return err
+}
+
+// aceptaXML indica si el cliente prefiere XML a JSON según el header Accept
+func aceptaXML(r *http.Request) bool {
+	return negociarFormato(r, formatoJSON, formatoXML) == formatoXML
+}
+
+// escribirRespuesta codifica v en XML si el cliente lo pidió y en JSON en cualquier otro caso
+func escribirRespuesta(w http.ResponseWriter, r *http.Request, estado int, v interface{}) {
+	if aceptaXML(r) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(estado)
+		io.WriteString(w, xml.Header)
+		xml.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(estado)
+	json.NewEncoder(w).Encode(v)
+}
+
+// escribirResultadoCedula responde con el resultado de la consulta por cédula en el formato
+// negociado: protobuf o XML si el cliente los prefiere, JSON en cualquier otro caso
+func escribirResultadoCedula(w http.ResponseWriter, r *http.Request, resultado *cedula.Result) {
+	if aceptaProtobuf(r) {
+		if err := escribirProtobuf(w, http.StatusOK, cedulaAProto(resultado)); err != nil {
+			writeError(w, r, errInterno)
+		}
+		return
+	}
+
+	escribirRespuesta(w, r, http.StatusOK, resultado)
+}
+
+// cedulaAProto convierte la respuesta de la consulta por cédula a su mensaje protobuf
+func cedulaAProto(resultado *cedula.Result) *cedulapb.CedulaResponse {
+	mensaje := &cedulapb.CedulaResponse{
+		Nombre:                 resultado.Nombre,
+		Apellido:               resultado.Apellido,
+		Nombres:                resultado.Nombres,
+		Apellidos:              resultado.Apellidos,
+		PrimerNombre:           resultado.PrimerNombre,
+		SegundoNombre:          resultado.SegundoNombre,
+		PrimerApellido:         resultado.PrimerApellido,
+		SegundoApellido:        resultado.SegundoApellido,
+		NombreFormateado:       resultado.NombreFormateado,
+		FechaInicioActividades: resultado.FechaInicioActividades,
+		Provincia:              resultado.Provincia,
+		Fuente:                 resultado.Fuente,
+		FromFallback:           resultado.DeRespaldo,
+		TieneDeudas:            resultado.TieneDeudas,
+		MontoTotal:             resultado.MontoTotal,
+		CheckDigitValid:        resultado.DigitoVerificadorValido,
+	}
+	for _, actividad := range resultado.Actividades {
+		mensaje.Actividades = append(mensaje.Actividades, &cedulapb.ActividadEconomica{
+			Ciiu:        actividad.Ciiu,
+			Descripcion: actividad.Descripcion,
+		})
+	}
+	if resultado.NombresAnteriores != nil {
+		mensaje.NombresAnteriores = *resultado.NombresAnteriores
+	}
+	if !resultado.ConsultadoEn.IsZero() {
+		mensaje.RetrievedAt = resultado.ConsultadoEn.Format(time.RFC3339Nano)
+	}
+	return mensaje
+}
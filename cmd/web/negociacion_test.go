@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+	"consulta-cedula-app/pkg/cedulapb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// consultarConAccept consulta 1710034065 en el SRI de prueba con el header Accept indicado
+func consultarConAccept(t *testing.T, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	manejarConsulta(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Accept %q: estado = %d: %s", accept, rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+// resultadoJSON devuelve el resultado de la consulta de 1710034065 en JSON, para comparar con
+// los demás formatos
+func resultadoJSON(t *testing.T) cedula.Result {
+	t.Helper()
+	var resultado cedula.Result
+	if err := json.Unmarshal(consultarConAccept(t, "application/json").Body.Bytes(), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	return resultado
+}
+
+func TestNegociarFormato(t *testing.T) {
+	casos := []struct {
+		accept   string
+		protobuf bool
+		xml      bool
+	}{
+		{"", false, false},
+		{"application/json", false, false},
+		{"application/xml", false, true},
+		{"text/xml", false, true},
+		{tipoProtobuf, true, false},
+		// Un tipo aceptado con menor calidad no le gana a JSON
+		{"application/json, application/xml;q=0.1", false, false},
+		{"application/json;q=0.5, application/xml", false, true},
+		{"application/xml;q=0.8, application/x-protobuf;q=0.9", true, true},
+		// q=0 excluye el tipo aunque otro rango más general lo incluya
+		{"application/xml;q=0, */*", false, false},
+		{"application/json;q=0, application/*;q=0.5", false, true},
+		// Con comodines y a igual calidad se prefiere JSON
+		{"*/*", false, false},
+		{"text/*", false, true},
+		{"application/xml, application/json", false, false},
+		{"APPLICATION/XML; Q=0.7, text/html", false, true},
+		// Rangos mal formados o q inválidos no cuentan
+		{"xml, application/xml;q=abc", false, false},
+		{"text/html", false, false},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(http.MethodGet, "/api/consultar", nil)
+		if caso.accept != "" {
+			req.Header.Set("Accept", caso.accept)
+		}
+		if protobuf := aceptaProtobuf(req); protobuf != caso.protobuf {
+			t.Errorf("Accept %q: aceptaProtobuf = %v, se esperaba %v", caso.accept, protobuf, caso.protobuf)
+		}
+		if xml := aceptaXML(req); xml != caso.xml {
+			t.Errorf("Accept %q: aceptaXML = %v, se esperaba %v", caso.accept, xml, caso.xml)
+		}
+	}
+}
+
+func TestEscribirRespuestaSegunAccept(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/validar", nil)
+	req.Header.Set("Accept", "application/json, application/xml;q=0.1")
+	rec := httptest.NewRecorder()
+	escribirRespuesta(rec, req, http.StatusOK, EstadoSalud{Status: estadoSaludOK})
+
+	if tipo := rec.Header().Get("Content-Type"); tipo != "application/json" {
+		t.Errorf("Content-Type = %q, se esperaba application/json", tipo)
+	}
+}
+
+func TestConsultaProtobufIdaYVuelta(t *testing.T) {
+	usarSRIPrueba(t)
+	esperado := resultadoJSON(t)
+
+	rec := consultarConAccept(t, tipoProtobuf)
+	if tipo := rec.Header().Get("Content-Type"); tipo != tipoProtobuf {
+		t.Fatalf("Content-Type = %q, se esperaba %s", tipo, tipoProtobuf)
+	}
+	var mensaje cedulapb.CedulaResponse
+	if err := proto.Unmarshal(rec.Body.Bytes(), &mensaje); err != nil {
+		t.Fatal(err)
+	}
+	// Cada consulta lleva su propia fecha de consulta
+	consultadoEn, err := time.Parse(time.RFC3339Nano, mensaje.GetRetrievedAt())
+	if err != nil {
+		t.Errorf("retrievedAt = %q: %v", mensaje.GetRetrievedAt(), err)
+	}
+	esperado.ConsultadoEn = consultadoEn
+	if !proto.Equal(&mensaje, cedulaAProto(&esperado)) {
+		t.Errorf("protobuf = %v\nse esperaba %v", &mensaje, cedulaAProto(&esperado))
+	}
+	if mensaje.GetNombre() != "JUAN CARLOS" || mensaje.GetApellido() != "PEREZ LOPEZ" || !mensaje.GetCheckDigitValid() {
+		t.Errorf("protobuf = %v", &mensaje)
+	}
+}
+
+func TestCedulaAProtoCompleto(t *testing.T) {
+	anteriores := []string{"PEREZ JUAN"}
+	resultado := &cedula.Result{
+		Nombre:            "JUAN CARLOS",
+		Actividades:       []cedula.Activity{{Ciiu: "G4711", Descripcion: "VENTA"}},
+		NombresAnteriores: &anteriores,
+		TieneDeudas:       true,
+		MontoTotal:        12.5,
+	}
+	datos, err := proto.Marshal(cedulaAProto(resultado))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mensaje cedulapb.CedulaResponse
+	if err := proto.Unmarshal(datos, &mensaje); err != nil {
+		t.Fatal(err)
+	}
+	if len(mensaje.GetActividades()) != 1 || mensaje.GetActividades()[0].GetCiiu() != "G4711" ||
+		len(mensaje.GetNombresAnteriores()) != 1 || !mensaje.GetTieneDeudas() || mensaje.GetMontoTotal() != 12.5 {
+		t.Errorf("protobuf = %v", &mensaje)
+	}
+}
+
+func TestConsultaXMLIdaYVuelta(t *testing.T) {
+	usarSRIPrueba(t)
+	esperado := resultadoJSON(t)
+
+	rec := consultarConAccept(t, "application/xml")
+	if tipo := rec.Header().Get("Content-Type"); !strings.HasPrefix(tipo, "application/xml") {
+		t.Fatalf("Content-Type = %q, se esperaba application/xml", tipo)
+	}
+	if !strings.HasPrefix(rec.Body.String(), xml.Header) {
+		t.Errorf("falta la declaración XML: %.60s", rec.Body.String())
+	}
+	var resultado cedula.Result
+	if err := xml.Unmarshal(rec.Body.Bytes(), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	if resultado.XMLName.Local != "cedulaResponse" {
+		t.Errorf("elemento raíz = %q, se esperaba cedulaResponse", resultado.XMLName.Local)
+	}
+	resultado.XMLName = esperado.XMLName
+	// Cada consulta lleva su propia fecha de consulta
+	if resultado.ConsultadoEn.IsZero() {
+		t.Error("falta retrievedAt en el XML")
+	}
+	resultado.ConsultadoEn = esperado.ConsultadoEn
+	if !reflect.DeepEqual(resultado, esperado) {
+		t.Errorf("XML = %+v\nse esperaba %+v", resultado, esperado)
+	}
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// nombresEsquema da a los tipos de pkg/cedula el nombre con el que se publican en la API
+var nombresEsquema = map[reflect.Type]string{
+	reflect.TypeOf(cedula.Result{}):     "CedulaResponse",
+	reflect.TypeOf(cedula.Activity{}):   "ActividadEconomica",
+	reflect.TypeOf(cedula.NameResult{}): "NombresResponse",
+}
+
+// generadorEsquemas arma los esquemas OpenAPI de los tipos de la API a partir de sus etiquetas
+// json, para que el documento no se desincronice de las estructuras que realmente se serializan
+type generadorEsquemas struct {
+	esquemas map[string]interface{}
+}
+
+// referencia devuelve una referencia al esquema del struct, generándolo la primera vez
+func (g *generadorEsquemas) referencia(t reflect.Type) map[string]interface{} {
+	nombre, ok := nombresEsquema[t]
+	if !ok {
+		nombre = t.Name()
+	}
+	if _, existe := g.esquemas[nombre]; !existe {
+		// Se reserva el nombre antes de recorrer los campos por si el tipo es recursivo
+		g.esquemas[nombre] = nil
+		g.esquemas[nombre] = g.objeto(t)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + nombre}
+}
+
+// esquema devuelve el esquema de un tipo cualquiera
+func (g *generadorEsquemas) esquema(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(CodigoError("")) {
+		return map[string]interface{}{"type": "string", "enum": catalogoCodigos}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.esquema(t.Elem())
+	case reflect.Struct:
+		return g.referencia(t)
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.esquema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.esquema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// objeto genera el esquema de un struct: una propiedad por cada campo exportado con su nombre
+// json; los campos sin omitempty son obligatorios
+func (g *generadorEsquemas) objeto(t reflect.Type) map[string]interface{} {
+	propiedades := map[string]interface{}{}
+	var obligatorios []string
+	for i := 0; i < t.NumField(); i++ {
+		campo := t.Field(i)
+		etiqueta := campo.Tag.Get("json")
+		if !campo.IsExported() || etiqueta == "-" {
+			continue
+		}
+		nombre, opciones, _ := strings.Cut(etiqueta, ",")
+		if nombre == "" {
+			nombre = campo.Name
+		}
+		propiedades[nombre] = g.esquema(campo.Type)
+		if !strings.Contains(opciones, "omitempty") {
+			obligatorios = append(obligatorios, nombre)
+		}
+	}
+
+	esquema := map[string]interface{}{"type": "object", "properties": propiedades}
+	if len(obligatorios) > 0 {
+		esquema["required"] = obligatorios
+	}
+	return esquema
+}
+
+// respuestaOpenAPI describe una respuesta cuyo cuerpo es de alguno de los tipos indicados
+func (g *generadorEsquemas) respuestaOpenAPI(descripcion string, tipos ...reflect.Type) map[string]interface{} {
+	esquema := g.esquema(tipos[0])
+	if len(tipos) > 1 {
+		alternativas := make([]interface{}, len(tipos))
+		for i, t := range tipos {
+			alternativas[i] = g.esquema(t)
+		}
+		esquema = map[string]interface{}{"oneOf": alternativas}
+	}
+	return map[string]interface{}{
+		"description": descripcion,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": esquema},
+		},
+	}
+}
+
+// operacion describe una operación con su cuerpo JSON (ninguno si peticion es nil), sus
+// respuestas exitosas y los errores estándar que puede devolver
+func (g *generadorEsquemas) operacion(resumen string, peticion reflect.Type, parametros []interface{}, respuestas map[string]interface{}, errores map[string]string) map[string]interface{} {
+	tipoError := reflect.TypeOf(ErrorResponse{})
+	for estado, descripcion := range errores {
+		respuestas[estado] = g.respuestaOpenAPI(descripcion, tipoError)
+	}
+	operacion := map[string]interface{}{
+		"summary":   resumen,
+		"responses": respuestas,
+	}
+	if peticion != nil {
+		operacion["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.esquema(peticion)},
+			},
+		}
+	}
+	if len(parametros) > 0 {
+		operacion["parameters"] = parametros
+	}
+	return operacion
+}
+
+// parametroQuery describe un parámetro de la query string
+func parametroQuery(nombre, descripcion string, obligatorio bool, esquema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        nombre,
+		"in":          "query",
+		"required":    obligatorio,
+		"description": descripcion,
+		"schema":      esquema,
+	}
+}
+
+// parametroRuta describe un parámetro que forma parte de la ruta; siempre es obligatorio
+func parametroRuta(nombre, descripcion string, esquema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        nombre,
+		"in":          "path",
+		"required":    true,
+		"description": descripcion,
+		"schema":      esquema,
+	}
+}
+
+// erroresComunes son los errores que cualquier endpoint de la API puede devolver por sus middlewares
+var erroresComunes = map[string]string{
+	"401": "Falta la clave del header X-API-Key o no es válida, con REQUIRE_API_KEY (UNAUTHORIZED)",
+	"403": "Origen no permitido o cliente bloqueado (ORIGIN_NOT_ALLOWED, FORBIDDEN)",
+	"405": "Método no permitido (METHOD_NOT_ALLOWED)",
+	"429": "Demasiadas peticiones (RATE_LIMITED)",
+	"500": "Error interno (INTERNAL_ERROR)",
+	"502": "El SRI respondió con un error (UPSTREAM_ERROR)",
+	"503": "Servicio en mantenimiento, presupuesto agotado o SRI no disponible (MAINTENANCE, DAILY_BUDGET_EXHAUSTED, UPSTREAM_UNAVAILABLE)",
+	"504": "La consulta superó el tiempo máximo (TIMEOUT)",
+}
+
+// errorCuerpoGrande es el error de las operaciones con cuerpo JSON cuando este supera MAX_BODY_BYTES
+const errorCuerpoGrande = "El cuerpo de la petición es demasiado grande (PAYLOAD_TOO_LARGE)"
+
+// conErrores agrega a los errores comunes los propios de un endpoint
+func conErrores(propios map[string]string) map[string]string {
+	errores := make(map[string]string, len(erroresComunes)+len(propios))
+	for estado, descripcion := range erroresComunes {
+		errores[estado] = descripcion
+	}
+	for estado, descripcion := range propios {
+		errores[estado] = descripcion
+	}
+	return errores
+}
+
+// generarOpenAPI arma el documento OpenAPI 3.0 de la API de consulta
+func generarOpenAPI() map[string]interface{} {
+	g := &generadorEsquemas{esquemas: map[string]interface{}{}}
+
+	// La consulta por cédula acepta la identificación en el cuerpo (POST) o en la query string (GET)
+	parametrosConsulta := []interface{}{
+		parametroQuery("nameFormat", "Agrega nombreFormateado en el formato indicado", false, map[string]interface{}{"type": "string", "enum": []string{formatoApellidosNombres}}),
+		parametroQuery("dryRun", "Devuelve las peticiones planificadas sin llamar al SRI", false, map[string]interface{}{"type": "boolean"}),
+	}
+	respuestasConsulta := func() map[string]interface{} {
+		return map[string]interface{}{
+			"200": g.respuestaOpenAPI("Datos encontrados (o el plan de la consulta con dryRun=true)", reflect.TypeOf(cedula.Result{}), reflect.TypeOf(PlanConsulta{})),
+		}
+	}
+	erroresConsulta := func(propios map[string]string) map[string]string {
+		propios["400"] = "Petición inválida (INVALID_JSON, VALIDATION_ERROR, INVALID_CEDULA, INVALID_RUC, INVALID_RUC_ESTABLISHMENT, INVALID_NAME_FORMAT, SUSPICIOUS_CEDULA)"
+		propios["404"] = "El SRI no tiene datos para la identificación (NOT_FOUND)"
+		return conErrores(propios)
+	}
+	consultar := map[string]interface{}{
+		"post": g.operacion(
+			"Consulta de nombres por número de cédula o RUC",
+			reflect.TypeOf(CedulaRequest{}),
+			parametrosConsulta,
+			respuestasConsulta(),
+			erroresConsulta(map[string]string{"413": errorCuerpoGrande}),
+		),
+		"get": g.operacion(
+			"Consulta de nombres por número de cédula o RUC (identificación en la query string)",
+			nil,
+			append([]interface{}{
+				parametroQuery("cedula", "Cédula o RUC a consultar", true, map[string]interface{}{"type": "string"}),
+			}, parametrosConsulta...),
+			respuestasConsulta(),
+			erroresConsulta(map[string]string{}),
+		),
+	}
+
+	// La variante con la identificación en la ruta admite revalidación con If-Modified-Since
+	respuestasPorRuta := respuestasConsulta()
+	respuestasPorRuta["304"] = map[string]interface{}{"description": "Los datos no cambiaron desde la fecha de If-Modified-Since"}
+	consultarPorRuta := g.operacion(
+		"Consulta de nombres por número de cédula o RUC (identificación en la ruta)",
+		nil,
+		append([]interface{}{
+			parametroRuta("cedula", "Cédula o RUC a consultar", map[string]interface{}{"type": "string"}),
+			map[string]interface{}{
+				"name":        "If-Modified-Since",
+				"in":          "header",
+				"required":    false,
+				"description": "Responde 304 si los datos no se obtuvieron después de esta fecha (la del Last-Modified de una respuesta anterior)",
+				"schema":      map[string]interface{}{"type": "string"},
+			},
+		}, parametrosConsulta...),
+		respuestasPorRuta,
+		erroresConsulta(map[string]string{}),
+	)
+
+	nombres := g.operacion(
+		"Consulta por nombres y apellidos (alternativas legales)",
+		reflect.TypeOf(NombresRequest{}),
+		nil,
+		map[string]interface{}{
+			"200": g.respuestaOpenAPI("Cédula encontrada o, si no hay una fuente pública disponible, las alternativas legales",
+				reflect.TypeOf(cedula.NameResult{}), reflect.TypeOf(AlternativasResponse{}), reflect.TypeOf(PlanConsulta{})),
+		},
+		conErrores(map[string]string{
+			"400": "Petición inválida (INVALID_JSON, VALIDATION_ERROR, INVALID_NAME, QUERY_TOO_BROAD)",
+			"413": errorCuerpoGrande,
+		}),
+	)
+
+	lote := g.operacion(
+		"Consulta de hasta 50 cédulas o RUC en una sola petición",
+		reflect.TypeOf(LoteRequest{}),
+		nil,
+		map[string]interface{}{
+			"200": g.respuestaOpenAPI("Resultado de cada identificación, en el orden de la petición", reflect.TypeOf(LoteResponse{})),
+		},
+		conErrores(map[string]string{
+			"400": "Petición inválida (INVALID_JSON, VALIDATION_ERROR, BATCH_TOO_LARGE)",
+			"413": errorCuerpoGrande,
+		}),
+	)
+	// El lote también se puede enviar como text/plain, una identificación por línea
+	lote["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["text/plain"] = map[string]interface{}{
+		"schema": map[string]interface{}{"type": "string", "description": "Una cédula o RUC por línea; se ignoran las líneas vacías"},
+	}
+
+	validar := g.operacion(
+		"Validación local de una cédula o RUC (dígito verificador y provincia), sin consultar el SRI",
+		nil,
+		[]interface{}{
+			parametroQuery("cedula", "Cédula o RUC a validar", true, map[string]interface{}{"type": "string"}),
+		},
+		map[string]interface{}{
+			"200": g.respuestaOpenAPI("Resultado de la validación", reflect.TypeOf(ValidacionResponse{})),
+		},
+		conErrores(map[string]string{
+			"400": "Falta el parámetro cedula (VALIDATION_ERROR)",
+		}),
+	)
+
+	decodificar := g.operacion(
+		"Decodificación local de una cédula o RUC (provincia, tipo de persona y dígito verificador), sin consultar el SRI",
+		nil,
+		[]interface{}{
+			parametroRuta("cedula", "Cédula o RUC a decodificar", map[string]interface{}{"type": "string"}),
+		},
+		map[string]interface{}{
+			"200": g.respuestaOpenAPI("Resultado de la decodificación", reflect.TypeOf(DecodificacionResponse{})),
+		},
+		conErrores(map[string]string{
+			"400": "Falta la cédula en la ruta (VALIDATION_ERROR)",
+		}),
+	)
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Consulta de Cédulas Ecuador",
+			"description": "Consulta de datos de cédulas y RUC ecuatorianos en la API pública del SRI",
+			"version":     version,
+		},
+		"paths": map[string]interface{}{
+			"/api/consultar":            consultar,
+			"/api/consultar/{cedula}":   map[string]interface{}{"get": consultarPorRuta},
+			"/api/consultar-nombres":    map[string]interface{}{"post": nombres},
+			"/api/consultar-lote":       map[string]interface{}{"post": lote},
+			"/api/validar":              map[string]interface{}{"get": validar},
+			"/api/decodificar/{cedula}": map[string]interface{}{"get": decodificar},
+		},
+		"components": map[string]interface{}{"schemas": g.esquemas},
+	}
+}
+
+// documentoOpenAPI se genera una sola vez, en la primera petición a /openapi.json
+var documentoOpenAPI = sync.OnceValue(func() []byte {
+	documento, err := json.MarshalIndent(generarOpenAPI(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return documento
+})
+
+// manejarOpenAPI sirve el documento OpenAPI de la API en /openapi.json
+func manejarOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(documentoOpenAPI())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// documentoPublicado pide /openapi.json al mux del servidor y lo decodifica
+func documentoPublicado(t *testing.T) map[string]interface{} {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	nuevoMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/openapi.json: estado = %d", rec.Code)
+	}
+	if tipo := rec.Header().Get("Content-Type"); tipo != "application/json" {
+		t.Errorf("Content-Type = %q", tipo)
+	}
+	var documento map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &documento); err != nil {
+		t.Fatalf("/openapi.json no es JSON válido: %v", err)
+	}
+	return documento
+}
+
+// referencias recorre el documento y devuelve todos los valores de $ref
+func referencias(nodo interface{}) []string {
+	var refs []string
+	switch valor := nodo.(type) {
+	case map[string]interface{}:
+		for clave, hijo := range valor {
+			if ref, ok := hijo.(string); ok && clave == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, referencias(hijo)...)
+		}
+	case []interface{}:
+		for _, hijo := range valor {
+			refs = append(refs, referencias(hijo)...)
+		}
+	}
+	return refs
+}
+
+// metodosOpenAPI son las claves de un path item que describen operaciones
+var metodosOpenAPI = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true}
+
+// patronEstado son las claves válidas del objeto responses
+var patronEstado = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX|default)$`)
+
+func TestOpenAPIEsUnDocumentoValido(t *testing.T) {
+	documento := documentoPublicado(t)
+
+	if version, _ := documento["openapi"].(string); !strings.HasPrefix(version, "3.0.") {
+		t.Errorf("openapi = %q, se esperaba 3.0.x", version)
+	}
+	info, _ := documento["info"].(map[string]interface{})
+	if titulo, _ := info["title"].(string); titulo == "" {
+		t.Error("falta info.title")
+	}
+	if v, _ := info["version"].(string); v == "" {
+		t.Error("falta info.version")
+	}
+
+	paths, _ := documento["paths"].(map[string]interface{})
+	for _, ruta := range []string{"/api/consultar", "/api/consultar/{cedula}", "/api/consultar-nombres", "/api/consultar-lote", "/api/validar", "/api/decodificar/{cedula}"} {
+		if _, ok := paths[ruta]; !ok {
+			t.Errorf("falta la ruta %s", ruta)
+		}
+	}
+
+	for ruta, item := range paths {
+		for metodo, op := range item.(map[string]interface{}) {
+			if !metodosOpenAPI[metodo] {
+				t.Errorf("%s: método desconocido %q", ruta, metodo)
+				continue
+			}
+			operacion := op.(map[string]interface{})
+			respuestas, _ := operacion["responses"].(map[string]interface{})
+			if len(respuestas) == 0 {
+				t.Errorf("%s %s: sin respuestas", metodo, ruta)
+			}
+			for estado, respuesta := range respuestas {
+				if !patronEstado.MatchString(estado) {
+					t.Errorf("%s %s: código de respuesta inválido %q", metodo, ruta, estado)
+				}
+				if descripcion, _ := respuesta.(map[string]interface{})["description"].(string); descripcion == "" {
+					t.Errorf("%s %s %s: falta la descripción obligatoria", metodo, ruta, estado)
+				}
+			}
+
+			// Cada parámetro de la ruta debe estar declarado como obligatorio
+			for _, nombre := range regexp.MustCompile(`\{([^}]+)\}`).FindAllStringSubmatch(ruta, -1) {
+				declarado := false
+				parametros, _ := operacion["parameters"].([]interface{})
+				for _, p := range parametros {
+					parametro := p.(map[string]interface{})
+					if parametro["in"] == "path" && parametro["name"] == nombre[1] && parametro["required"] == true {
+						declarado = true
+					}
+				}
+				if !declarado {
+					t.Errorf("%s %s: falta el parámetro de ruta obligatorio %q", metodo, ruta, nombre[1])
+				}
+			}
+		}
+	}
+
+	componentes, _ := documento["components"].(map[string]interface{})
+	esquemas, _ := componentes["schemas"].(map[string]interface{})
+	for _, ref := range referencias(documento) {
+		nombre, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		if !ok {
+			t.Errorf("referencia fuera de components/schemas: %s", ref)
+			continue
+		}
+		if esquema, existe := esquemas[nombre]; !existe || esquema == nil {
+			t.Errorf("la referencia %s no apunta a ningún esquema", ref)
+		}
+	}
+
+	cedulaResponse, _ := esquemas["CedulaResponse"].(map[string]interface{})
+	propiedades, _ := cedulaResponse["properties"].(map[string]interface{})
+	for _, campo := range []string{"nombre", "apellido", "fuente", "provincia"} {
+		if _, ok := propiedades[campo]; !ok {
+			t.Errorf("CedulaResponse no describe el campo %q", campo)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// origenesPermitidos son los orígenes desde los que se aceptan peticiones de navegador.
+// Se configuran con ALLOWED_ORIGINS; si está vacío no se valida el origen.
+var origenesPermitidos atomic.Pointer[map[string]bool]
+
+// errOrigenNoPermitido se devuelve a las peticiones de navegador desde un origen no permitido
+var errOrigenNoPermitido = &errorAPI{codigo: CodigoOrigenNoPermitido, estado: http.StatusForbidden, mensaje: "Origen no permitido"}
+
+// cargarOrigenesPermitidos construye el conjunto de orígenes a partir de la lista separada por comas
+func cargarOrigenesPermitidos(lista string) map[string]bool {
+	origenes := parsearListaEnv(lista)
+	if len(origenes) == 0 {
+		return nil
+	}
+	permitidos := make(map[string]bool, len(origenes))
+	for _, origen := range origenes {
+		permitidos[strings.ToLower(strings.TrimRight(origen, "/"))] = true
+	}
+	return permitidos
+}
+
+// origenPermitido indica si el header Origin corresponde a un origen permitido o al propio servidor
+func origenPermitido(r *http.Request, permitidos map[string]bool, origen string) bool {
+	if permitidos[strings.ToLower(origen)] {
+		return true
+	}
+	u, err := url.Parse(origen)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// validarOrigen rechaza con 403 las peticiones de navegador (con header Origin) que no vienen de un
+// origen permitido. Los clientes que no son navegadores (sin Origin) pasan sin validar, y sin
+// ALLOWED_ORIGINS no se valida ninguna petición.
+func validarOrigen(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permitidos := origenesPermitidos.Load()
+		if origen := r.Header.Get("Origin"); permitidos != nil && origen != "" && !origenPermitido(r, *permitidos, origen) {
+			writeError(w, r, errOrigenNoPermitido)
+			return
+		}
+		siguiente.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// atenderCon envía la petición a un handler que responde 200 detrás del middleware indicado
+func atenderCon(middleware func(http.Handler) http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	return rec
+}
+
+// configurarOrigenes activa durante la prueba la lista de orígenes permitidos (ALLOWED_ORIGINS)
+func configurarOrigenes(t *testing.T, lista string) {
+	t.Helper()
+	anteriores := origenesPermitidos.Load()
+	if permitidos := cargarOrigenesPermitidos(lista); permitidos != nil {
+		origenesPermitidos.Store(&permitidos)
+	} else {
+		origenesPermitidos.Store(nil)
+	}
+	t.Cleanup(func() { origenesPermitidos.Store(anteriores) })
+}
+
+func TestValidarOrigen(t *testing.T) {
+	configurarOrigenes(t, "https://app.ejemplo.ec, https://otra.ejemplo.ec/")
+
+	casos := []struct {
+		nombre string
+		origen string
+		estado int
+	}{
+		{"sin Origin", "", http.StatusOK},
+		{"origen permitido", "https://app.ejemplo.ec", http.StatusOK},
+		{"origen permitido en mayúsculas", "HTTPS://OTRA.EJEMPLO.EC", http.StatusOK},
+		{"el propio servidor", "http://api.ejemplo.ec", http.StatusOK},
+		{"origen no permitido", "https://malicioso.ejemplo.com", http.StatusForbidden},
+		{"subdominio no listado", "https://x.app.ejemplo.ec", http.StatusForbidden},
+		{"origen nulo", "null", http.StatusForbidden},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://api.ejemplo.ec/api/validar?cedula=1710034065", nil)
+			if caso.origen != "" {
+				req.Header.Set("Origin", caso.origen)
+			}
+			rec := atenderCon(validarOrigen, req)
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			if caso.estado == http.StatusForbidden {
+				var respuesta ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+					t.Fatal(err)
+				}
+				if respuesta.Code != CodigoOrigenNoPermitido {
+					t.Errorf("código = %s, se esperaba %s", respuesta.Code, CodigoOrigenNoPermitido)
+				}
+			}
+		})
+	}
+}
+
+func TestValidarOrigenSinConfigurar(t *testing.T) {
+	configurarOrigenes(t, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+	req.Header.Set("Origin", "https://cualquiera.ejemplo.com")
+	if rec := atenderCon(validarOrigen, req); rec.Code != http.StatusOK {
+		t.Errorf("estado = %d, sin ALLOWED_ORIGINS no se valida el origen", rec.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+)
+
+// errNoAutorizado se devuelve cuando falta la clave de administración o de API, o no es válida
+var errNoAutorizado = &errorAPI{codigo: CodigoNoAutorizado, estado: http.StatusUnauthorized, mensaje: "No autorizado"}
+
+// requiereClaveAdmin protege un handler con la clave de administración (header X-API-Key)
+func requiereClaveAdmin(claveAdmin string, siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clave := r.Header.Get("X-API-Key")
+		if clave == "" || subtle.ConstantTimeCompare([]byte(clave), []byte(claveAdmin)) != 1 {
+			writeError(w, r, errNoAutorizado)
+			return
+		}
+		siguiente.ServeHTTP(w, r)
+	})
+}
+
+// registrarPprof registra los handlers de net/http/pprof bajo /debug/pprof/, protegidos con
+// la clave de administración. Si no hay clave configurada no se registran.
+func registrarPprof(mux *http.ServeMux, claveAdmin string) {
+	if claveAdmin == "" {
+		slog.Warn("ENABLE_PPROF requiere ADMIN_API_KEY; /debug/pprof no se habilitó")
+		return
+	}
+
+	mux.Handle("/debug/pprof/", requiereClaveAdmin(claveAdmin, http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", requiereClaveAdmin(claveAdmin, http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", requiereClaveAdmin(claveAdmin, http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", requiereClaveAdmin(claveAdmin, http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", requiereClaveAdmin(claveAdmin, http.HandlerFunc(pprof.Trace)))
+	slog.Info("Perfilado habilitado en /debug/pprof/ (requiere clave de administración)")
+}
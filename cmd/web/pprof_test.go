@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rutaRegistrada devuelve el patrón del mux que atendería la ruta
+func rutaRegistrada(mux *http.ServeMux, ruta string) string {
+	_, patron := mux.Handler(httptest.NewRequest(http.MethodGet, ruta, nil))
+	return patron
+}
+
+func TestPprofDesactivadoPorDefecto(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "")
+	t.Setenv("ADMIN_API_KEY", "clave-admin")
+
+	mux := nuevoMux()
+	for _, ruta := range []string{"/debug/pprof/", "/debug/pprof/profile", "/debug/pprof/cmdline"} {
+		if patron := rutaRegistrada(mux, ruta); strings.HasPrefix(patron, "/debug/pprof") {
+			t.Errorf("%s se atiende con %q sin ENABLE_PPROF", ruta, patron)
+		}
+	}
+}
+
+func TestPprofSinClaveAdminNoSeHabilita(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	t.Setenv("ADMIN_API_KEY", "")
+
+	if patron := rutaRegistrada(nuevoMux(), "/debug/pprof/"); patron != "/" {
+		t.Errorf("/debug/pprof/ se atiende con %q sin ADMIN_API_KEY", patron)
+	}
+}
+
+func TestPprofRequiereClaveAdmin(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	t.Setenv("ADMIN_API_KEY", "clave-admin")
+	mux := nuevoMux()
+
+	casos := []struct {
+		nombre string
+		clave  string
+		estado int
+	}{
+		{"sin clave", "", http.StatusUnauthorized},
+		{"clave incorrecta", "otra-clave", http.StatusUnauthorized},
+		{"clave de administración", "clave-admin", http.StatusOK},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil)
+			if caso.clave != "" {
+				req.Header.Set("X-API-Key", caso.clave)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != caso.estado {
+				t.Errorf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+		})
+	}
+}
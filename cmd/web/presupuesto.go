@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// errCuotaAgotada se devuelve cuando se agotó el presupuesto diario de llamadas al SRI
+var errCuotaAgotada = &errorAPI{codigo: CodigoCuotaAgotada, estado: http.StatusServiceUnavailable, mensaje: "Cuota diaria agotada. Intente nuevamente mañana"}
+
+// presupuestoDiario limita la cantidad total de llamadas a las fuentes externas por día.
+// El contador se reinicia a la medianoche de la zona horaria configurada.
+type presupuestoDiario struct {
+	mu       sync.Mutex
+	limite   int
+	usadas   int
+	zona     *time.Location
+	reinicio time.Time
+	ahora    func() time.Time
+}
+
+// presupuestoUpstream es el presupuesto activo; nil si no hay límite (DAILY_UPSTREAM_BUDGET)
+var presupuestoUpstream *presupuestoDiario
+
+// nuevoPresupuestoDiario crea un presupuesto con el límite de llamadas por día en la zona indicada
+func nuevoPresupuestoDiario(limite int, zona *time.Location) *presupuestoDiario {
+	return &presupuestoDiario{limite: limite, zona: zona, ahora: time.Now}
+}
+
+// proximaMedianoche devuelve la medianoche siguiente a t en la zona del presupuesto
+func (p *presupuestoDiario) proximaMedianoche(t time.Time) time.Time {
+	local := t.In(p.zona)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, p.zona)
+}
+
+// consumir descuenta una llamada del presupuesto; devuelve false si ya se agotó
+func (p *presupuestoDiario) consumir() bool {
+	if p == nil {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ahora := p.ahora()
+	if !ahora.Before(p.reinicio) {
+		p.usadas = 0
+		p.reinicio = p.proximaMedianoche(ahora)
+	}
+	if p.usadas >= p.limite {
+		return false
+	}
+	p.usadas++
+	return true
+}
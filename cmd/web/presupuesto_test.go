@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// zonaEcuador es UTC-5 sin depender de la base de datos de zonas horarias del sistema
+var zonaEcuador = time.FixedZone("ECT", -5*60*60)
+
+// presupuestoConReloj crea un presupuesto cuyo reloj es la variable devuelta
+func presupuestoConReloj(limite int, inicio time.Time) (*presupuestoDiario, *time.Time) {
+	ahora := inicio
+	presupuesto := nuevoPresupuestoDiario(limite, zonaEcuador)
+	presupuesto.ahora = func() time.Time { return ahora }
+	return presupuesto, &ahora
+}
+
+// consumirVeces consume n llamadas y devuelve cuántas fueron aceptadas
+func consumirVeces(p *presupuestoDiario, n int) int {
+	aceptadas := 0
+	for i := 0; i < n; i++ {
+		if p.consumir() {
+			aceptadas++
+		}
+	}
+	return aceptadas
+}
+
+func TestPresupuestoDiarioSeAgota(t *testing.T) {
+	presupuesto, _ := presupuestoConReloj(3, time.Date(2024, 5, 10, 12, 0, 0, 0, zonaEcuador))
+
+	if aceptadas := consumirVeces(presupuesto, 5); aceptadas != 3 {
+		t.Errorf("aceptadas = %d, se esperaban 3", aceptadas)
+	}
+	if presupuesto.consumir() {
+		t.Error("el presupuesto agotado no debe aceptar más llamadas el mismo día")
+	}
+}
+
+func TestPresupuestoDiarioSeReiniciaAMedianoche(t *testing.T) {
+	presupuesto, ahora := presupuestoConReloj(2, time.Date(2024, 5, 10, 23, 59, 58, 0, zonaEcuador))
+	consumirVeces(presupuesto, 2)
+
+	*ahora = time.Date(2024, 5, 10, 23, 59, 59, 999999999, zonaEcuador)
+	if presupuesto.consumir() {
+		t.Error("antes de la medianoche el presupuesto sigue agotado")
+	}
+
+	*ahora = time.Date(2024, 5, 11, 0, 0, 0, 0, zonaEcuador)
+	if aceptadas := consumirVeces(presupuesto, 3); aceptadas != 2 {
+		t.Errorf("después de la medianoche: aceptadas = %d, se esperaban 2", aceptadas)
+	}
+}
+
+func TestPresupuestoDiarioUsaLaMedianocheDeSuZona(t *testing.T) {
+	// 23:00 en Ecuador son las 04:00 UTC del día siguiente: la medianoche UTC ya pasó, la de
+	// Ecuador no
+	presupuesto, ahora := presupuestoConReloj(1, time.Date(2024, 5, 10, 23, 0, 0, 0, zonaEcuador))
+	presupuesto.consumir()
+
+	*ahora = time.Date(2024, 5, 11, 4, 30, 0, 0, time.UTC)
+	if presupuesto.consumir() {
+		t.Error("el presupuesto no debe reiniciarse con la medianoche UTC")
+	}
+
+	*ahora = time.Date(2024, 5, 11, 5, 0, 0, 0, time.UTC)
+	if !presupuesto.consumir() {
+		t.Error("el presupuesto debe reiniciarse con la medianoche de Ecuador (05:00 UTC)")
+	}
+}
+
+func TestAntesDeLlamarSRIConCuotaAgotada(t *testing.T) {
+	anterior := presupuestoUpstream
+	presupuesto, _ := presupuestoConReloj(1, time.Date(2024, 5, 10, 12, 0, 0, 0, zonaEcuador))
+	presupuestoUpstream = presupuesto
+	t.Cleanup(func() { presupuestoUpstream = anterior })
+
+	liberar, err := antesDeLlamarSRI(context.Background())
+	if err != nil {
+		t.Fatalf("primera llamada: %v", err)
+	}
+	liberar()
+
+	if _, err := antesDeLlamarSRI(context.Background()); err != errCuotaAgotada {
+		t.Errorf("error = %v, se esperaba errCuotaAgotada", err)
+	}
+}
+
+func TestPresupuestoDiarioNilNoLimita(t *testing.T) {
+	var presupuesto *presupuestoDiario
+	if aceptadas := consumirVeces(presupuesto, 100); aceptadas != 100 {
+		t.Errorf("sin DAILY_UPSTREAM_BUDGET: aceptadas = %d, se esperaban 100", aceptadas)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// enmascararPII indica si los nombres se recortan para los clientes no privilegiados.
+// Se configura con MASK_PII.
+var enmascararPII atomic.Bool
+
+// clavesPrivilegiadas son las claves (header X-API-Key) que reciben los nombres completos.
+// Se configuran con PRIVILEGED_API_KEYS, separadas por comas.
+var clavesPrivilegiadas atomic.Pointer[[]string]
+
+// parsearListaEnv separa una lista de valores separados por comas, descartando vacíos
+func parsearListaEnv(valor string) []string {
+	var lista []string
+	for _, elemento := range strings.Split(valor, ",") {
+		if elemento = strings.TrimSpace(elemento); elemento != "" {
+			lista = append(lista, elemento)
+		}
+	}
+	return lista
+}
+
+// esClientePrivilegiado indica si la petición trae una de las claves privilegiadas configuradas
+func esClientePrivilegiado(r *http.Request) bool {
+	return esClavePrivilegiada(r.Header.Get("X-API-Key"))
+}
+
+// esClavePrivilegiada indica si la clave es una de las privilegiadas configuradas
+func esClavePrivilegiada(clave string) bool {
+	if clave == "" {
+		return false
+	}
+	claves := clavesPrivilegiadas.Load()
+	if claves == nil {
+		return false
+	}
+	for _, privilegiada := range *claves {
+		if subtle.ConstantTimeCompare([]byte(clave), []byte(privilegiada)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// enmascararResultado devuelve una copia del resultado con los nombres recortados:
+// se conserva el primer nombre y del resto solo las iniciales
+func enmascararResultado(resultado *cedula.Result) *cedula.Result {
+	copia := *resultado
+	copia.Nombre = enmascararNombre(resultado.Nombre, true)
+	copia.Apellido = enmascararNombre(resultado.Apellido, false)
+	copia.Nombres = enmascararNombre(resultado.Nombres, true)
+	copia.Apellidos = enmascararNombre(resultado.Apellidos, false)
+	copia.SegundoNombre = enmascararNombre(resultado.SegundoNombre, false)
+	copia.PrimerApellido = enmascararNombre(resultado.PrimerApellido, false)
+	copia.SegundoApellido = enmascararNombre(resultado.SegundoApellido, false)
+	copia.NombresAnteriores = nil
+	if copia.NombreFormateado != "" {
+		copia.NombreFormateado = formatearApellidosNombres(copia.Nombre, copia.Apellido)
+	}
+	return &copia
+}
+
+// enmascararNombre reduce cada palabra a su inicial, conservando opcionalmente la primera completa
+func enmascararNombre(nombre string, conservarPrimera bool) string {
+	palabras := strings.Fields(nombre)
+	for i, palabra := range palabras {
+		if i == 0 && conservarPrimera {
+			continue
+		}
+		palabras[i] = string([]rune(palabra)[:1]) + "."
+	}
+	return strings.Join(palabras, " ")
+}
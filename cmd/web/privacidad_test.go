@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// activarEnmascarado activa MASK_PII durante la prueba
+func activarEnmascarado(t *testing.T) {
+	t.Helper()
+	anterior := enmascararPII.Load()
+	enmascararPII.Store(true)
+	t.Cleanup(func() { enmascararPII.Store(anterior) })
+}
+
+// consultarConClave consulta 1710034065 con la clave indicada (si no está vacía)
+func consultarConClave(t *testing.T, clave string) cedula.Result {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil)
+	if clave != "" {
+		req.Header.Set("X-API-Key", clave)
+	}
+	rec := httptest.NewRecorder()
+	manejarConsulta(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resultado cedula.Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &resultado); err != nil {
+		t.Fatal(err)
+	}
+	return resultado
+}
+
+func TestConsultaEnmascarada(t *testing.T) {
+	usarSRIPrueba(t)
+	activarEnmascarado(t)
+	configurarClavesAPI(t, false, "clave-comun,clave-privilegiada", "clave-privilegiada")
+
+	casos := []struct {
+		nombre    string
+		clave     string
+		nombres   string
+		apellidos string
+	}{
+		{"sin clave", "", "JUAN C.", "P. L."},
+		{"clave no privilegiada", "clave-comun", "JUAN C.", "P. L."},
+		{"clave privilegiada", "clave-privilegiada", "JUAN CARLOS", "PEREZ LOPEZ"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			resultado := consultarConClave(t, caso.clave)
+			if resultado.Nombres != caso.nombres || resultado.Apellidos != caso.apellidos {
+				t.Errorf("nombres = %q, apellidos = %q; se esperaba %q, %q",
+					resultado.Nombres, resultado.Apellidos, caso.nombres, caso.apellidos)
+			}
+		})
+	}
+}
+
+func TestConsultaSinEnmascarar(t *testing.T) {
+	usarSRIPrueba(t)
+	configurarClavesAPI(t, false, "")
+
+	resultado := consultarConClave(t, "")
+	if resultado.Nombres != "JUAN CARLOS" || resultado.Apellidos != "PEREZ LOPEZ" {
+		t.Errorf("sin MASK_PII se esperaban los nombres completos, se obtuvo %q %q", resultado.Nombres, resultado.Apellidos)
+	}
+}
+
+func TestEnmascararResultado(t *testing.T) {
+	anteriores := []string{"PEREZ JUAN"}
+	resultado := &cedula.Result{
+		Nombre:            "MARÍA JOSÉ",
+		Apellido:          "ÑAUPARI ORTIZ",
+		Nombres:           "MARÍA JOSÉ",
+		Apellidos:         "ÑAUPARI ORTIZ",
+		SegundoNombre:     "JOSÉ",
+		PrimerApellido:    "ÑAUPARI",
+		SegundoApellido:   "ORTIZ",
+		NombresAnteriores: &anteriores,
+	}
+
+	enmascarado := enmascararResultado(resultado)
+	if enmascarado.Nombres != "MARÍA J." || enmascarado.Apellidos != "Ñ. O." {
+		t.Errorf("nombres = %q, apellidos = %q", enmascarado.Nombres, enmascarado.Apellidos)
+	}
+	if enmascarado.SegundoNombre != "J." || enmascarado.PrimerApellido != "Ñ." || enmascarado.SegundoApellido != "O." {
+		t.Errorf("componentes = %q %q %q", enmascarado.SegundoNombre, enmascarado.PrimerApellido, enmascarado.SegundoApellido)
+	}
+	if enmascarado.NombresAnteriores != nil {
+		t.Error("los nombres anteriores no se deben entregar enmascarados")
+	}
+	if resultado.Nombres != "MARÍA JOSÉ" || resultado.NombresAnteriores == nil {
+		t.Error("enmascararResultado no debe modificar el resultado original")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// version es la versión del servicio; se puede fijar al compilar con -ldflags "-X main.version=..."
+var version = "dev"
+
+// EndpointInfo describe un endpoint de la API para el índice de la raíz
+type EndpointInfo struct {
+	Metodo      string `json:"metodo"`
+	Ruta        string `json:"ruta"`
+	Descripcion string `json:"descripcion"`
+}
+
+// IndiceAPI es el documento que se sirve en / cuando no existe la interfaz web
+type IndiceAPI struct {
+	Servicio  string         `json:"servicio"`
+	Version   string         `json:"version"`
+	Endpoints []EndpointInfo `json:"endpoints"`
+}
+
+// endpointsAPI lista los endpoints disponibles de la API
+var endpointsAPI = []EndpointInfo{
+	{Metodo: "POST", Ruta: "/api/consultar", Descripcion: "Consulta de nombres por número de cédula o RUC"},
+	{Metodo: "GET", Ruta: "/api/consultar?cedula=", Descripcion: "Consulta por cédula o RUC con la identificación en la query string"},
+	{Metodo: "GET", Ruta: "/api/consultar/{cedula}", Descripcion: "Consulta por cédula o RUC con la identificación en la ruta; admite If-Modified-Since"},
+	{Metodo: "POST", Ruta: "/api/consultar-lote", Descripcion: "Consulta de hasta 50 cédulas o RUC en una sola petición"},
+	{Metodo: "POST", Ruta: "/api/consultar-nombres", Descripcion: "Consulta por nombres y apellidos (alternativas legales)"},
+	{Metodo: "GET", Ruta: "/api/validar?cedula=", Descripcion: "Validación local de una cédula o RUC, sin consultar el SRI"},
+	{Metodo: "GET", Ruta: "/api/decodificar/{cedula}", Descripcion: "Provincia, tipo de persona y dígito verificador de una cédula o RUC, sin consultar el SRI"},
+	{Metodo: "GET", Ruta: "/stats/latency", Descripcion: "Percentiles de latencia de las fuentes consultadas"},
+	{Metodo: "GET", Ruta: "/openapi.json", Descripcion: "Documento OpenAPI 3.0 de la API"},
+	{Metodo: "GET", Ruta: "/metrics", Descripcion: "Métricas de Prometheus de las consultas y del SRI"},
+	{Metodo: "GET", Ruta: "/healthz", Descripcion: "Comprobación de que el servicio está en marcha; indica si está en mantenimiento"},
+	{Metodo: "GET", Ruta: "/readyz", Descripcion: "Comprobación de que el SRI es alcanzable y la API no está en mantenimiento"},
+}
+
+// manejarRaiz sirve los archivos estáticos de la interfaz web y, si no existe un index.html,
+// responde en / con un índice JSON de los endpoints disponibles
+func manejarRaiz(dirEstatico string) http.Handler {
+	archivos := http.FileServer(http.Dir(dirEstatico))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			if _, err := os.Stat(filepath.Join(dirEstatico, "index.html")); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				json.NewEncoder(w).Encode(IndiceAPI{
+					Servicio:  "consulta-cedula-ecuador",
+					Version:   version,
+					Endpoints: endpointsAPI,
+				})
+				return
+			}
+		}
+		archivos.ServeHTTP(w, r)
+	})
+}
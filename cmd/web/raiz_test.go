@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pedirRaiz envía una petición GET a manejarRaiz con el directorio estático indicado
+func pedirRaiz(dirEstatico, ruta string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	manejarRaiz(dirEstatico).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ruta, nil))
+	return rec
+}
+
+func TestRaizSinInterfazSirveElIndice(t *testing.T) {
+	directorios := map[string]string{
+		"directorio vacío":       t.TempDir(),
+		"directorio inexistente": filepath.Join(t.TempDir(), "no-existe"),
+	}
+	for nombre, dir := range directorios {
+		t.Run(nombre, func(t *testing.T) {
+			rec := pedirRaiz(dir, "/")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("estado = %d", rec.Code)
+			}
+			if tipo := rec.Header().Get("Content-Type"); tipo != "application/json" {
+				t.Errorf("Content-Type = %q", tipo)
+			}
+
+			var indice IndiceAPI
+			if err := json.Unmarshal(rec.Body.Bytes(), &indice); err != nil {
+				t.Fatal(err)
+			}
+			if indice.Version != version || len(indice.Endpoints) != len(endpointsAPI) {
+				t.Errorf("índice = %+v", indice)
+			}
+		})
+	}
+}
+
+func TestRaizConInterfazSirveElIndexHTML(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>consulta</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("// app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := pedirRaiz(dir, "/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<html>consulta</html>") {
+		t.Errorf("/: estado = %d, cuerpo = %q", rec.Code, rec.Body.String())
+	}
+	if rec := pedirRaiz(dir, "/app.js"); rec.Code != http.StatusOK || rec.Body.String() != "// app" {
+		t.Errorf("/app.js: estado = %d, cuerpo = %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRaizSinInterfazNoInventaOtrasRutas(t *testing.T) {
+	if rec := pedirRaiz(t.TempDir(), "/app.js"); rec.Code != http.StatusNotFound {
+		t.Errorf("estado = %d, se esperaba 404 fuera de /", rec.Code)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// variablesDelEntorno son las variables definidas al arrancar el proceso; tienen prioridad
+// sobre las del archivo de configuración
+var variablesDelEntorno map[string]bool
+
+// variablesDelArchivo son las variables que se tomaron del archivo en la última carga
+var variablesDelArchivo = map[string]bool{}
+
+// configLimiteAplicada y configLimiteLoteAplicada recuerdan las variables de los limitadores activos
+var (
+	configLimiteAplicada     = "sin aplicar"
+	configLimiteLoteAplicada = "sin aplicar"
+)
+
+// variablesNoRecargables solo se leen al arrancar; cambiarlas requiere reiniciar el servidor
+var variablesNoRecargables = []string{
+	"PORT", "SIGN_RESPONSES", "SIGNING_KEY_SEED", "ENABLE_PPROF", "ADMIN_API_KEY",
+	"SOURCE_CONCURRENCY", "SRI_TIMEOUT_SECONDS", "DAILY_UPSTREAM_BUDGET", "BUDGET_TIMEZONE",
+	"SRI_BASE_URLS", "DETAILED_RESPONSE", "ERROR_LOG_WINDOW_SECONDS", "CACHE_SIZE", "CACHE_COMPACT",
+	"BATCH_WORKERS", "ENABLE_FALLBACK", "FALLBACK_LOOKUP_URL", "CIRCUIT_BREAKER_THRESHOLD",
+	"CIRCUIT_BREAKER_COOLDOWN_SECONDS", "SRI_USER_AGENT", "SRI_HEADERS",
+	"UPSTREAM_PROXY_URL", "CACHE_PATH", "REDIS_URL",
+	"MAX_BODY_BYTES",
+}
+
+// registrarEntorno guarda qué variables vienen del entorno del proceso
+func registrarEntorno() {
+	variablesDelEntorno = make(map[string]bool)
+	for _, variable := range os.Environ() {
+		nombre, _, _ := strings.Cut(variable, "=")
+		variablesDelEntorno[nombre] = true
+	}
+}
+
+// leerArchivoConfig lee un archivo con una variable NOMBRE=valor por línea. Se ignoran las
+// líneas vacías y las que empiezan con #; las comillas que rodean al valor se quitan.
+func leerArchivoConfig(ruta string) (map[string]string, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	valores := make(map[string]string)
+	lector := bufio.NewScanner(archivo)
+	for numero := 1; lector.Scan(); numero++ {
+		linea := strings.TrimSpace(lector.Text())
+		if linea == "" || strings.HasPrefix(linea, "#") {
+			continue
+		}
+		nombre, valor, ok := strings.Cut(strings.TrimPrefix(linea, "export "), "=")
+		nombre = strings.TrimSpace(nombre)
+		if !ok || nombre == "" {
+			return nil, fmt.Errorf("línea %d: se esperaba NOMBRE=valor", numero)
+		}
+		valor = strings.TrimSpace(valor)
+		if sinComillas, err := strconv.Unquote(valor); err == nil {
+			valor = sinComillas
+		} else if len(valor) >= 2 && valor[0] == '\'' && valor[len(valor)-1] == '\'' {
+			valor = valor[1 : len(valor)-1]
+		}
+		valores[nombre] = valor
+	}
+	return valores, lector.Err()
+}
+
+// cargarArchivoConfig vuelca el archivo de configuración (CONFIG_FILE) en las variables de
+// entorno, sin pisar las definidas al arrancar. Las variables que se quitaron del archivo
+// desde la carga anterior se eliminan.
+func cargarArchivoConfig(ruta string) error {
+	valores, err := leerArchivoConfig(ruta)
+	if err != nil {
+		return err
+	}
+
+	for nombre := range variablesDelArchivo {
+		if _, sigue := valores[nombre]; !sigue {
+			os.Unsetenv(nombre)
+		}
+	}
+	variablesDelArchivo = make(map[string]bool, len(valores))
+	for nombre, valor := range valores {
+		if variablesDelEntorno[nombre] {
+			continue
+		}
+		os.Setenv(nombre, valor)
+		variablesDelArchivo[nombre] = true
+	}
+	return nil
+}
+
+// aplicarAjustesRecargables lee de las variables de entorno los ajustes que se pueden cambiar
+// en caliente. Cada ajuste se reemplaza de forma atómica, así que las peticiones en curso
+// terminan con el valor anterior y las nuevas usan el nuevo.
+func aplicarAjustesRecargables() {
+	// Nivel de los logs (LOG_LEVEL, por defecto info); va primero para que aplique a los demás avisos
+	nivel, err := parsearNivelRegistro(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		slog.Warn("LOG_LEVEL inválido, usando info", "valor", os.Getenv("LOG_LEVEL"))
+	}
+	nivelRegistro.Set(nivel)
+
+	// Enmascaramiento de nombres para clientes no privilegiados
+	enmascararPII.Store(leerBoolEnv("MASK_PII", false))
+	claves := parsearListaEnv(os.Getenv("PRIVILEGED_API_KEYS"))
+	clavesPrivilegiadas.Store(&claves)
+
+	// Autenticación con claves de API (REQUIRE_API_KEY, con las claves de API_KEYS y API_KEYS_FILE)
+	if claves, err := cargarClavesAPI(os.Getenv("API_KEYS"), os.Getenv("API_KEYS_FILE")); err != nil {
+		slog.Error("Error al leer API_KEYS_FILE; se mantienen las claves actuales", "archivo", os.Getenv("API_KEYS_FILE"), "error", err)
+	} else {
+		clavesAPI.Store(&claves)
+	}
+	exigirClaveAPI.Store(leerBoolEnv("REQUIRE_API_KEY", false))
+	if claves := clavesAPI.Load(); exigirClaveAPI.Load() && (claves == nil || len(*claves) == 0) && len(*clavesPrivilegiadas.Load()) == 0 {
+		slog.Warn("REQUIRE_API_KEY está activo pero no hay claves configuradas; se rechazarán todas las peticiones a la API")
+	}
+
+	// Validación estricta de patrones sospechosos en las cédulas
+	validacionEstricta.Store(leerBoolEnv("STRICT_VALIDATION", false))
+
+	// Rechazo de búsquedas por nombres demasiado generales
+	exigirNombresEspecificos.Store(leerBoolEnv("REQUIRE_SPECIFIC_NAMES", false))
+
+	// Bloqueo de clientes automatizados por User-Agent
+	bloquearBots.Store(leerBoolEnv("BLOCK_BOTS", false))
+	if patrones := parsearListaEnv(os.Getenv("BOT_UA_PATTERNS")); len(patrones) > 0 {
+		patronesBots.Store(&patrones)
+	} else {
+		patronesBots.Store(&patronesBotsPorDefecto)
+	}
+
+	// Latencia sintética (solo fuera de producción)
+	inyeccionLatencia.Store(cargarLatenciaSintetica())
+
+	// Orígenes permitidos para peticiones de navegador
+	if origenes := cargarOrigenesPermitidos(os.Getenv("ALLOWED_ORIGINS")); origenes != nil {
+		origenesPermitidos.Store(&origenes)
+	} else {
+		origenesPermitidos.Store(nil)
+	}
+
+	// Orígenes que pueden leer las respuestas desde un navegador (CORS_ALLOWED_ORIGINS)
+	corsPermitidos.Store(cargarPoliticaCORS(os.Getenv("CORS_ALLOWED_ORIGINS")))
+
+	// Límite de peticiones por IP (RATE_LIMIT_RPS y RATE_LIMIT_BURST) y límite aparte de las
+	// identificaciones de los lotes (RATE_LIMIT_BATCH_RPS y RATE_LIMIT_BATCH_BURST); sin tasa
+	// configurada no hay límite
+	aplicarLimite(&limitePorIP, &configLimiteAplicada, "RATE_LIMIT_RPS", "RATE_LIMIT_BURST", limiteRafagaPorDefecto)
+	aplicarLimite(&limiteLotePorIP, &configLimiteLoteAplicada, "RATE_LIMIT_BATCH_RPS", "RATE_LIMIT_BATCH_BURST", limiteRafagaLotePorDefecto)
+
+	// Proxies confiables cuyo X-Forwarded-For identifica al cliente (TRUSTED_PROXIES)
+	if redes, err := parsearRedes(os.Getenv("TRUSTED_PROXIES")); err != nil {
+		slog.Warn("TRUSTED_PROXIES inválido; se mantiene el valor actual", "error", err)
+	} else {
+		proxiesConfiables.Store(&redes)
+	}
+
+	// Modo mantenimiento (MAINTENANCE_MODE, MAINTENANCE_MESSAGE y MAINTENANCE_RETRY_AFTER en segundos)
+	reintentoMantenimiento := 5 * time.Minute
+	if valor := os.Getenv("MAINTENANCE_RETRY_AFTER"); valor != "" {
+		segundos, err := strconv.Atoi(valor)
+		if err != nil || segundos <= 0 {
+			slog.Warn("Valor inválido para MAINTENANCE_RETRY_AFTER, usando el valor por defecto", "valor", valor, "porDefecto", reintentoMantenimiento.String())
+		} else {
+			reintentoMantenimiento = time.Duration(segundos) * time.Second
+		}
+	}
+	configurarMantenimiento(leerBoolEnv("MAINTENANCE_MODE", false), os.Getenv("MAINTENANCE_MESSAGE"), reintentoMantenimiento)
+
+	// Vigencia de las entradas nuevas de la caché de resultados del SRI (CACHE_TTL_SECONDS) y las
+	// propias de las entradas con deudas (CACHE_TTL_DEBT_SECONDS) y de los RUC
+	// (CACHE_TTL_RUC_SECONDS), que sin configurar usan la general
+	if clienteSRI.Cache != nil {
+		clienteSRI.SetCacheTTL(leerTTLCache("CACHE_TTL_SECONDS", cedula.DefaultCacheTTL))
+		clienteSRI.SetDebtCacheTTL(leerTTLCache("CACHE_TTL_DEBT_SECONDS", 0))
+		clienteSRI.SetRUCCacheTTL(leerTTLCache("CACHE_TTL_RUC_SECONDS", 0))
+
+		// Variación aleatoria del TTL de cada entrada, en porcentaje (CACHE_TTL_JITTER_PERCENT)
+		variacion := cedula.DefaultCacheJitter
+		if valor := os.Getenv("CACHE_TTL_JITTER_PERCENT"); valor != "" {
+			porcentaje, err := strconv.Atoi(valor)
+			if err != nil || porcentaje < 0 || porcentaje > 100 {
+				slog.Warn("Valor inválido para CACHE_TTL_JITTER_PERCENT, usando el valor por defecto", "valor", valor, "porDefecto", variacion*100)
+			} else {
+				variacion = float64(porcentaje) / 100
+			}
+		}
+		if cache, ok := clienteSRI.Cache.(interface{ SetJitter(float64) }); ok {
+			cache.SetJitter(variacion)
+		}
+
+		// Porcentaje final de la vigencia en el que un acierto refresca la entrada en segundo
+		// plano (CACHE_REFRESH_AHEAD_PERCENT); 0 lo desactiva
+		refresco := 0
+		if valor := os.Getenv("CACHE_REFRESH_AHEAD_PERCENT"); valor != "" {
+			porcentaje, err := strconv.Atoi(valor)
+			if err != nil || porcentaje < 0 || porcentaje >= 100 {
+				slog.Warn("Valor inválido para CACHE_REFRESH_AHEAD_PERCENT, se desactiva el refresco anticipado", "valor", valor)
+			} else {
+				refresco = porcentaje
+			}
+		}
+		clienteSRI.SetRefreshAhead(float64(refresco) / 100)
+	}
+}
+
+// leerTTLCache lee una vigencia de la caché en segundos de la variable indicada, usando el valor
+// por defecto si no está definida o es inválida
+func leerTTLCache(variable string, porDefecto time.Duration) time.Duration {
+	valor := os.Getenv(variable)
+	if valor == "" {
+		return porDefecto
+	}
+	ttl, err := parsearSegundos(valor)
+	if err != nil {
+		slog.Warn("Valor inválido para "+variable+", usando el valor por defecto", "valor", valor, "error", err, "porDefecto", porDefecto.String())
+		return porDefecto
+	}
+	return ttl
+}
+
+// aplicarLimite carga un limitador de sus variables y lo activa en destino. Solo se reemplaza si
+// cambian los valores, para no reiniciar los baldes de tokens; si son inválidos se mantiene el
+// limitador actual (o ninguno, al arrancar).
+func aplicarLimite(destino *atomic.Pointer[limitadorIP], aplicada *string, variableRPS, variableRafaga string, rafagaPorDefecto int) {
+	config := os.Getenv(variableRPS) + "|" + os.Getenv(variableRafaga)
+	if config == *aplicada {
+		return
+	}
+	limitador, err := cargarLimite(variableRPS, variableRafaga, rafagaPorDefecto)
+	if err != nil {
+		slog.Warn("Límite de peticiones inválido; se mantiene el actual", "error", err)
+		return
+	}
+	destino.Store(limitador)
+	*aplicada = config
+}
+
+// recargarConfiguracion relee el archivo de configuración y aplica los ajustes recargables.
+// Los cambios en variables que solo se leen al arrancar se informan como pendientes de reinicio.
+func recargarConfiguracion(ruta string) {
+	anteriores := make(map[string]string, len(variablesNoRecargables))
+	for _, nombre := range variablesNoRecargables {
+		anteriores[nombre] = os.Getenv(nombre)
+	}
+
+	if err := cargarArchivoConfig(ruta); err != nil {
+		slog.Error("Error al recargar la configuración; se mantiene la actual", "archivo", ruta, "error", err)
+		return
+	}
+	aplicarAjustesRecargables()
+
+	for _, nombre := range variablesNoRecargables {
+		if os.Getenv(nombre) != anteriores[nombre] {
+			slog.Warn("La variable cambió, pero requiere reiniciar el servidor para aplicarse", "variable", nombre)
+		}
+	}
+	slog.Info("Configuración recargada", "archivo", ruta)
+}
+
+// atenderRecargas recarga la configuración cada vez que llega una señal (SIGHUP)
+func atenderRecargas(senales <-chan os.Signal, ruta string) {
+	for range senales {
+		if ruta == "" {
+			slog.Warn("SIGHUP recibido, pero no hay CONFIG_FILE configurado; no hay nada que recargar")
+			continue
+		}
+		recargarConfiguracion(ruta)
+	}
+}
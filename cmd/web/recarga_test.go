@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// prepararRecarga deja el proceso listo para recargar un archivo de configuración temporal y
+// restaura al terminar las variables del archivo y el límite por IP
+func prepararRecarga(t *testing.T) string {
+	t.Helper()
+	for _, nombre := range []string{"RATE_LIMIT_RPS", "RATE_LIMIT_BURST"} {
+		if _, definida := os.LookupEnv(nombre); definida {
+			t.Skipf("%s está definida en el entorno y tiene prioridad sobre el archivo", nombre)
+		}
+	}
+
+	entorno, archivo := variablesDelEntorno, variablesDelArchivo
+	limite, aplicada := limitePorIP.Load(), configLimiteAplicada
+	registrarEntorno()
+	variablesDelArchivo = map[string]bool{}
+	t.Cleanup(func() {
+		for nombre := range variablesDelArchivo {
+			os.Unsetenv(nombre)
+		}
+		variablesDelEntorno, variablesDelArchivo = entorno, archivo
+		limitePorIP.Store(limite)
+		configLimiteAplicada = aplicada
+	})
+	return filepath.Join(t.TempDir(), "config.env")
+}
+
+// escribirConfig reemplaza el contenido del archivo de configuración
+func escribirConfig(t *testing.T, ruta, contenido string) {
+	t.Helper()
+	if err := os.WriteFile(ruta, []byte(contenido), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// peticionesAceptadas cuenta cuántas de n peticiones seguidas desde una IP nueva pasan el límite
+func peticionesAceptadas(remota string, n int) int {
+	aceptadas := 0
+	for i := 0; i < n; i++ {
+		if peticionLimitada(remota, "").Code == http.StatusOK {
+			aceptadas++
+		}
+	}
+	return aceptadas
+}
+
+func TestRecargaCambiaElLimiteActivo(t *testing.T) {
+	ruta := prepararRecarga(t)
+
+	escribirConfig(t, ruta, "RATE_LIMIT_RPS=0.01\nRATE_LIMIT_BURST=2\n")
+	recargarConfiguracion(ruta)
+	if aceptadas := peticionesAceptadas("192.0.2.10:5000", 5); aceptadas != 2 {
+		t.Errorf("ráfaga 2: aceptadas = %d, se esperaban 2", aceptadas)
+	}
+
+	escribirConfig(t, ruta, "RATE_LIMIT_RPS=0.01\nRATE_LIMIT_BURST=4\n")
+	recargarConfiguracion(ruta)
+	if aceptadas := peticionesAceptadas("192.0.2.11:5000", 6); aceptadas != 4 {
+		t.Errorf("ráfaga 4 tras recargar: aceptadas = %d, se esperaban 4", aceptadas)
+	}
+
+	// Quitar las variables del archivo desactiva el límite
+	escribirConfig(t, ruta, "# sin límite\n")
+	recargarConfiguracion(ruta)
+	if limitePorIP.Load() != nil {
+		t.Error("sin RATE_LIMIT_RPS el límite debe quedar desactivado")
+	}
+	if aceptadas := peticionesAceptadas("192.0.2.10:5000", 10); aceptadas != 10 {
+		t.Errorf("sin límite: aceptadas = %d, se esperaban 10", aceptadas)
+	}
+}
+
+func TestRecargaConLimiteInvalidoMantieneElActual(t *testing.T) {
+	ruta := prepararRecarga(t)
+
+	escribirConfig(t, ruta, "RATE_LIMIT_RPS=0.01\nRATE_LIMIT_BURST=3\n")
+	recargarConfiguracion(ruta)
+	activo := limitePorIP.Load()
+	if activo == nil {
+		t.Fatal("el límite no se activó")
+	}
+
+	escribirConfig(t, ruta, "RATE_LIMIT_RPS=rapido\nRATE_LIMIT_BURST=3\n")
+	recargarConfiguracion(ruta)
+	if limitePorIP.Load() != activo {
+		t.Error("un valor inválido no debe reemplazar el limitador activo")
+	}
+
+	// Recargar sin cambios tampoco reinicia los baldes de tokens
+	escribirConfig(t, ruta, "RATE_LIMIT_RPS=0.01\nRATE_LIMIT_BURST=3\n")
+	recargarConfiguracion(ruta)
+	peticionesAceptadas("192.0.2.12:5000", 3)
+	recargarConfiguracion(ruta)
+	if aceptadas := peticionesAceptadas("192.0.2.12:5000", 1); aceptadas != 0 {
+		t.Error("recargar la misma configuración no debe reiniciar el balde de la IP")
+	}
+}
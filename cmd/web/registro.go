@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// nivelRegistro es el nivel mínimo de los logs (LOG_LEVEL); se puede cambiar en caliente
+var nivelRegistro slog.LevelVar
+
+// nuevoRegistro crea el logger JSON que escribe en salida con el nivel de nivelRegistro.
+// Se recibe la salida para poder capturar los logs (por ejemplo en un bytes.Buffer).
+func nuevoRegistro(salida io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(salida, &slog.HandlerOptions{Level: &nivelRegistro}))
+}
+
+// parsearNivelRegistro interpreta LOG_LEVEL: debug, info, warn o error (info si está vacío)
+func parsearNivelRegistro(valor string) (slog.Level, error) {
+	var nivel slog.Level
+	if strings.TrimSpace(valor) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := nivel.UnmarshalText([]byte(strings.TrimSpace(valor))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("nivel de log inválido %q", valor)
+	}
+	return nivel, nil
+}
+
+// terminar registra un error fatal y termina el proceso
+func terminar(mensaje string, err error) {
+	slog.Error(mensaje, "error", err)
+	os.Exit(1)
+}
+
+// cabeceraIDPeticion es la cabecera con la que el cliente puede enviar su propio ID de petición
+// y con la que se devuelve el ID usado
+const cabeceraIDPeticion = "X-Request-ID"
+
+// longitudMaximaIDPeticion acota el ID recibido del cliente, que se copia en cada línea de log
+const longitudMaximaIDPeticion = 128
+
+// nuevoIDPeticion genera un UUID versión 4 aleatorio
+func nuevoIDPeticion() string {
+	var bytes [16]byte
+	rand.Read(bytes[:])
+	bytes[6] = bytes[6]&0x0f | 0x40
+	bytes[8] = bytes[8]&0x3f | 0x80
+	texto := hex.EncodeToString(bytes[:])
+	return texto[:8] + "-" + texto[8:12] + "-" + texto[12:16] + "-" + texto[16:20] + "-" + texto[20:]
+}
+
+// idPeticionValido indica si el ID recibido del cliente se puede usar tal cual: no vacío,
+// acotado y solo con caracteres ASCII visibles, para que no pueda alterar los logs
+func idPeticionValido(id string) bool {
+	if id == "" || len(id) > longitudMaximaIDPeticion {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// registrarPeticion toma el X-Request-ID del cliente (o genera uno), lo devuelve en la
+// respuesta y guarda en el contexto un logger que lo incluye en cada línea, que usan los
+// handlers y las consultas al SRI hechas durante la petición
+func registrarPeticion(siguiente http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(cabeceraIDPeticion)
+		if !idPeticionValido(id) {
+			id = nuevoIDPeticion()
+		}
+		w.Header().Set(cabeceraIDPeticion, id)
+
+		registro := slog.Default().With("requestId", id)
+		siguiente.ServeHTTP(w, r.WithContext(cedula.WithLogger(r.Context(), registro)))
+	})
+}
+
+// registroAgrupado evita inundar los logs con errores idénticos: la primera ocurrencia
+// se registra de inmediato y las repeticiones dentro de la ventana se resumen en una
+// sola línea con el conteo al cerrarse la ventana
+type registroAgrupado struct {
+	mu      sync.Mutex
+	ventana time.Duration
+	logf    func(format string, args ...interface{})
+	eventos map[string]int
+}
+
+// nuevoRegistroAgrupado crea un registro agrupado; con ventana 0 se registra cada línea
+func nuevoRegistroAgrupado(ventana time.Duration) *registroAgrupado {
+	return &registroAgrupado{
+		ventana: ventana,
+		logf:    registrarAdvertencia,
+		eventos: make(map[string]int),
+	}
+}
+
+// registrarAdvertencia registra un mensaje con formato en nivel warn
+func registrarAdvertencia(format string, args ...interface{}) {
+	slog.Warn(fmt.Sprintf(format, args...))
+}
+
+// Printf registra el mensaje salvo que uno idéntico ya se haya registrado en la ventana actual
+func (r *registroAgrupado) Printf(format string, args ...interface{}) {
+	mensaje := fmt.Sprintf(format, args...)
+	if r.ventana <= 0 {
+		r.logf("%s", mensaje)
+		return
+	}
+
+	r.mu.Lock()
+	if _, ok := r.eventos[mensaje]; ok {
+		r.eventos[mensaje]++
+		r.mu.Unlock()
+		return
+	}
+	r.eventos[mensaje] = 0
+	r.mu.Unlock()
+
+	r.logf("%s", mensaje)
+	time.AfterFunc(r.ventana, func() { r.resumir(mensaje) })
+}
+
+// resumir cierra la ventana de un mensaje y registra cuántas veces se repitió
+func (r *registroAgrupado) resumir(mensaje string) {
+	r.mu.Lock()
+	repeticiones := r.eventos[mensaje]
+	delete(r.eventos, mensaje)
+	r.mu.Unlock()
+
+	if repeticiones > 0 {
+		r.logf("%s x%d en los últimos %s", mensaje, repeticiones, r.ventana)
+	}
+}
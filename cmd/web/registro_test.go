@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// capturarRegistros reemplaza durante la prueba el logger por defecto por uno JSON que escribe
+// en el buffer devuelto
+func capturarRegistros(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var registros bytes.Buffer
+	anterior := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&registros, nil)))
+	t.Cleanup(func() { slog.SetDefault(anterior) })
+	return &registros
+}
+
+// lineasRegistro decodifica cada línea JSON de los registros capturados
+func lineasRegistro(t *testing.T, registros *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lineas []map[string]interface{}
+	for _, linea := range strings.Split(strings.TrimSpace(registros.String()), "\n") {
+		if linea == "" {
+			continue
+		}
+		var campos map[string]interface{}
+		if err := json.Unmarshal([]byte(linea), &campos); err != nil {
+			t.Fatalf("línea de log inválida %q: %v", linea, err)
+		}
+		lineas = append(lineas, campos)
+	}
+	return lineas
+}
+
+// patronUUID es el formato de los IDs de petición generados (UUID versión 4)
+var patronUUID = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// peticionConID envía una petición con el X-Request-ID indicado (si no está vacío) a un handler
+// detrás de registrarPeticion que registra una línea con el logger de la petición
+func peticionConID(t *testing.T, id string) (*httptest.ResponseRecorder, []map[string]interface{}) {
+	t.Helper()
+	registros := capturarRegistros(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/validar?cedula=1710034065", nil)
+	if id != "" {
+		req.Header.Set(cabeceraIDPeticion, id)
+	}
+	rec := httptest.NewRecorder()
+	registrarPeticion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cedula.LoggerFrom(r.Context()).Info("dentro del handler")
+	})).ServeHTTP(rec, req)
+	return rec, lineasRegistro(t, registros)
+}
+
+func TestRegistrarPeticionDevuelveElIDRecibido(t *testing.T) {
+	rec, lineas := peticionConID(t, "cliente-123")
+
+	if got := rec.Header().Get(cabeceraIDPeticion); got != "cliente-123" {
+		t.Errorf("%s = %q, se esperaba el recibido", cabeceraIDPeticion, got)
+	}
+	if len(lineas) != 1 || lineas[0]["requestId"] != "cliente-123" {
+		t.Errorf("el log del handler no incluye el requestId recibido: %v", lineas)
+	}
+}
+
+func TestRegistrarPeticionGeneraUnIDSiNoViene(t *testing.T) {
+	rec, lineas := peticionConID(t, "")
+
+	id := rec.Header().Get(cabeceraIDPeticion)
+	if !patronUUID.MatchString(id) {
+		t.Fatalf("%s = %q, se esperaba un UUID v4", cabeceraIDPeticion, id)
+	}
+	if len(lineas) != 1 || lineas[0]["requestId"] != id {
+		t.Errorf("el log del handler no incluye el requestId generado %q: %v", id, lineas)
+	}
+
+	otro, _ := peticionConID(t, "")
+	if otro.Header().Get(cabeceraIDPeticion) == id {
+		t.Error("dos peticiones sin ID recibieron el mismo ID generado")
+	}
+}
+
+func TestRegistrarPeticionReemplazaIDsInvalidos(t *testing.T) {
+	invalidos := []string{
+		"con espacio",
+		"salto\nde-linea",
+		"acentuado-ñ",
+		strings.Repeat("a", longitudMaximaIDPeticion+1),
+	}
+	for _, id := range invalidos {
+		rec, _ := peticionConID(t, id)
+		if got := rec.Header().Get(cabeceraIDPeticion); !patronUUID.MatchString(got) {
+			t.Errorf("ID %q: %s = %q, se esperaba uno generado", id, cabeceraIDPeticion, got)
+		}
+	}
+
+	largoMaximo := strings.Repeat("a", longitudMaximaIDPeticion)
+	if rec, _ := peticionConID(t, largoMaximo); rec.Header().Get(cabeceraIDPeticion) != largoMaximo {
+		t.Error("un ID de la longitud máxima se debe aceptar tal cual")
+	}
+}
+
+// registroAgrupadoPrueba crea un registro agrupado que guarda sus líneas en lugar de
+// registrarlas; la función devuelta las lee
+func registroAgrupadoPrueba(ventana time.Duration) (*registroAgrupado, func() []string) {
+	var mu sync.Mutex
+	var lineas []string
+	registro := nuevoRegistroAgrupado(ventana)
+	registro.logf = func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		lineas = append(lineas, fmt.Sprintf(format, args...))
+	}
+	return registro, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lineas...)
+	}
+}
+
+// esperarLineas espera hasta que haya n líneas o pase un segundo
+func esperarLineas(leer func() []string, n int) []string {
+	limite := time.Now().Add(time.Second)
+	for len(leer()) < n && time.Now().Before(limite) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return leer()
+}
+
+func TestRegistroAgrupadoResumeLasRepeticiones(t *testing.T) {
+	registro, leer := registroAgrupadoPrueba(50 * time.Millisecond)
+
+	for i := 0; i < 4; i++ {
+		registro.Printf("Fallo del host del SRI %s: %v", "https://a", "timeout")
+	}
+	registro.Printf("Fallo del host del SRI %s: %v", "https://b", "timeout")
+
+	// La primera ocurrencia de cada mensaje se registra de inmediato
+	if lineas := leer(); !reflect.DeepEqual(lineas, []string{
+		"Fallo del host del SRI https://a: timeout",
+		"Fallo del host del SRI https://b: timeout",
+	}) {
+		t.Fatalf("líneas inmediatas = %q", lineas)
+	}
+
+	// Al cerrarse la ventana solo se resume el mensaje que se repitió; se da tiempo a que cierre
+	// también la del otro mensaje para comprobar que no agrega nada
+	esperarLineas(leer, 3)
+	time.Sleep(60 * time.Millisecond)
+	if lineas := leer(); len(lineas) != 3 || lineas[2] != "Fallo del host del SRI https://a: timeout x3 en los últimos 50ms" {
+		t.Fatalf("líneas tras la ventana = %q", lineas)
+	}
+
+	// Cerrada la ventana, el mensaje vuelve a registrarse de inmediato
+	registro.Printf("Fallo del host del SRI %s: %v", "https://a", "timeout")
+	if lineas := leer(); len(lineas) != 4 || lineas[3] != "Fallo del host del SRI https://a: timeout" {
+		t.Errorf("líneas en la nueva ventana = %q", lineas)
+	}
+}
+
+func TestRegistroAgrupadoSinVentana(t *testing.T) {
+	registro, leer := registroAgrupadoPrueba(0)
+
+	for i := 0; i < 3; i++ {
+		registro.Printf("Fallo %d", 1)
+	}
+	if lineas := leer(); len(lineas) != 3 {
+		t.Errorf("con ventana 0 se debe registrar cada línea, se registraron %q", lineas)
+	}
+}
+
+// registrarConNivel captura durante la prueba los logs del logger de la aplicación con el nivel
+// indicado
+func registrarConNivel(t *testing.T, nivel slog.Level) *bytes.Buffer {
+	t.Helper()
+	var registros bytes.Buffer
+	anteriorLogger, anteriorNivel := slog.Default(), nivelRegistro.Level()
+	slog.SetDefault(nuevoRegistro(&registros))
+	nivelRegistro.Set(nivel)
+	t.Cleanup(func() {
+		slog.SetDefault(anteriorLogger)
+		nivelRegistro.Set(anteriorNivel)
+	})
+	return &registros
+}
+
+func TestRegistrosEstructuradosDeUnaConsulta(t *testing.T) {
+	usarSRIPrueba(t)
+	registros := registrarConNivel(t, slog.LevelInfo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil)
+	req.Header.Set(cabeceraIDPeticion, "consulta-265")
+	rec := httptest.NewRecorder()
+	registrarPeticion(http.HandlerFunc(manejarConsulta)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	lineas := lineasRegistro(t, registros)
+	if len(lineas) == 0 {
+		t.Fatal("la consulta no registró nada")
+	}
+	for _, linea := range lineas {
+		for _, campo := range []string{"time", "level", "msg", "requestId", "cedula"} {
+			if _, ok := linea[campo]; !ok {
+				t.Errorf("falta el campo %q en %v", campo, linea)
+			}
+		}
+		if linea["requestId"] != "consulta-265" || linea["cedula"] != "17******65" {
+			t.Errorf("requestId = %v, cedula = %v", linea["requestId"], linea["cedula"])
+		}
+		if linea["level"] == "DEBUG" {
+			t.Errorf("con LOG_LEVEL=info no se registra debug: %v", linea)
+		}
+	}
+	if strings.Contains(registros.String(), "1710034065") {
+		t.Errorf("los logs incluyen la cédula completa:\n%s", registros.String())
+	}
+}
+
+func TestRegistrosDebugIncluyenLaRespuestaDelSRI(t *testing.T) {
+	usarSRIPrueba(t)
+	registros := registrarConNivel(t, slog.LevelDebug)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/consultar?cedula=1710034065", nil)
+	registrarPeticion(http.HandlerFunc(manejarConsulta)).ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, linea := range lineasRegistro(t, registros) {
+		if linea["msg"] != "Respuesta del SRI" {
+			continue
+		}
+		if linea["level"] != "DEBUG" || linea["estado"] != float64(http.StatusOK) {
+			t.Errorf("línea = %v", linea)
+		}
+		if cuerpo, _ := linea["cuerpo"].(string); !strings.Contains(cuerpo, "PEREZ LOPEZ") {
+			t.Errorf("cuerpo = %q, se esperaba la respuesta del SRI", cuerpo)
+		}
+		return
+	}
+	t.Errorf("con LOG_LEVEL=debug se esperaba la línea de la respuesta del SRI:\n%s", registros.String())
+}
+
+func TestParsearNivelRegistro(t *testing.T) {
+	casos := []struct {
+		valor  string
+		nivel  slog.Level
+		valido bool
+	}{
+		{"", slog.LevelInfo, true},
+		{"debug", slog.LevelDebug, true},
+		{" WARN ", slog.LevelWarn, true},
+		{"error", slog.LevelError, true},
+		{"verboso", slog.LevelInfo, false},
+	}
+	for _, caso := range casos {
+		nivel, err := parsearNivelRegistro(caso.valor)
+		if nivel != caso.nivel || (err == nil) != caso.valido {
+			t.Errorf("parsearNivelRegistro(%q) = %v, %v; se esperaba %v, válido = %v", caso.valor, nivel, err, caso.nivel, caso.valido)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// vigenciaExitoSRI es el tiempo durante el cual una consulta exitosa al SRI basta para
+// considerar el servicio listo sin volver a comprobarlo
+const vigenciaExitoSRI = 30 * time.Second
+
+// timeoutPreparacion acota la comprobación de /readyz contra el SRI
+const timeoutPreparacion = 2 * time.Second
+
+// ultimoExitoSRI guarda el momento (UnixNano) de la última llamada exitosa al SRI
+var ultimoExitoSRI atomic.Int64
+
+// errSRIInalcanzable se devuelve en /readyz cuando no se puede contactar al SRI
+var errSRIInalcanzable = &errorAPI{codigo: CodigoSRIInalcanzable, estado: http.StatusServiceUnavailable, mensaje: "No se puede contactar al SRI"}
+
+// Valores de EstadoSalud.Status
+const (
+	estadoSaludOK            = "ok"
+	estadoSaludMantenimiento = "maintenance"
+)
+
+// EstadoSalud es la respuesta de /healthz y /readyz
+type EstadoSalud struct {
+	Status string `json:"status"`
+	// Mantenimiento indica si la API está en modo mantenimiento (MAINTENANCE_MODE)
+	Mantenimiento bool `json:"maintenance"`
+}
+
+// manejarSalud responde en /healthz mientras el proceso esté en marcha (liveness). Con el modo
+// mantenimiento activo se sigue respondiendo 200, para que el orquestador no reinicie el
+// proceso, pero el estado pasa a "maintenance".
+func manejarSalud(w http.ResponseWriter, r *http.Request) {
+	estado := EstadoSalud{Status: estadoSaludOK}
+	if mantenimiento.Load().activo {
+		estado = EstadoSalud{Status: estadoSaludMantenimiento, Mantenimiento: true}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(estado)
+}
+
+// manejarPreparacion responde en /readyz si el SRI es alcanzable (readiness). Una consulta
+// exitosa reciente basta; si no la hay, se hace una petición HEAD con un timeout corto. Con el
+// modo mantenimiento activo se responde 503 para que el balanceador deje de enviar tráfico.
+func manejarPreparacion(w http.ResponseWriter, r *http.Request) {
+	if estado := mantenimiento.Load(); estado.activo {
+		escribirMantenimiento(w, r, estado)
+		return
+	}
+
+	if time.Since(time.Unix(0, ultimoExitoSRI.Load())) > vigenciaExitoSRI {
+		ctx, cancelar := context.WithTimeout(r.Context(), timeoutPreparacion)
+		defer cancelar()
+		if err := clienteSRI.Ping(ctx); err != nil {
+			slog.Warn("Comprobación de preparación fallida", "error", err)
+			writeError(w, r, errSRIInalcanzable)
+			return
+		}
+		ultimoExitoSRI.Store(time.Now().UnixNano())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(EstadoSalud{Status: estadoSaludOK})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// activarMantenimiento activa el modo mantenimiento durante la prueba
+func activarMantenimiento(t *testing.T) {
+	t.Helper()
+	configurarMantenimiento(true, "", time.Minute)
+	t.Cleanup(func() { configurarMantenimiento(false, "", time.Minute) })
+}
+
+func TestManejarSalud(t *testing.T) {
+	casos := []struct {
+		nombre        string
+		mantenimiento bool
+		espera        EstadoSalud
+	}{
+		{"normal", false, EstadoSalud{Status: estadoSaludOK}},
+		{"mantenimiento", true, EstadoSalud{Status: estadoSaludMantenimiento, Mantenimiento: true}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if caso.mantenimiento {
+				activarMantenimiento(t)
+			}
+			rec := httptest.NewRecorder()
+			manejarSalud(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("estado = %d, se esperaba 200", rec.Code)
+			}
+			var estado EstadoSalud
+			if err := json.Unmarshal(rec.Body.Bytes(), &estado); err != nil {
+				t.Fatal(err)
+			}
+			if estado != caso.espera {
+				t.Errorf("respuesta = %+v, se esperaba %+v", estado, caso.espera)
+			}
+		})
+	}
+}
+
+func TestManejarPreparacionMantenimiento(t *testing.T) {
+	// Con un éxito reciente del SRI la preparación no depende de la red
+	ultimoExitoSRI.Store(time.Now().UnixNano())
+	t.Cleanup(func() { ultimoExitoSRI.Store(0) })
+
+	rec := httptest.NewRecorder()
+	manejarPreparacion(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("estado = %d, se esperaba 200", rec.Code)
+	}
+
+	activarMantenimiento(t)
+	rec = httptest.NewRecorder()
+	manejarPreparacion(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("estado = %d, se esperaba 503", rec.Code)
+	}
+	var respuesta ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	if respuesta.Code != CodigoMantenimiento || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("código = %s, Retry-After = %q", respuesta.Code, rec.Header().Get("Retry-After"))
+	}
+}
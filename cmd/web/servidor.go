@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// tiempoCierre es el tiempo máximo que se espera a que terminen las peticiones en curso al cerrar
+const tiempoCierre = 15 * time.Second
+
+// ejecutarServidor atiende peticiones en el listener hasta recibir una señal y entonces cierra el
+// servidor de forma ordenada, dejando terminar las consultas en curso durante tiempoCierre
+func ejecutarServidor(servidor *http.Server, listener net.Listener, senales <-chan os.Signal) error {
+	errServidor := make(chan error, 1)
+	go func() {
+		errServidor <- servidor.Serve(listener)
+	}()
+
+	select {
+	case err := <-errServidor:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case senal := <-senales:
+		slog.Info("Señal recibida, cerrando el servidor tras las peticiones en curso", "senal", senal.String(), "espera", tiempoCierre.String())
+	}
+
+	ctx, cancelar := context.WithTimeout(context.Background(), tiempoCierre)
+	defer cancelar()
+	if err := servidor.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	slog.Info("Servidor detenido correctamente")
+	return nil
+}
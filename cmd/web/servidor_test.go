@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEjecutarServidorTerminaLasPeticionesEnCurso(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+
+	iniciada, liberar := make(chan struct{}), make(chan struct{})
+	servidor := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(iniciada)
+		<-liberar
+		io.WriteString(w, "completa")
+	})}
+
+	senales := make(chan os.Signal, 1)
+	terminado := make(chan error, 1)
+	go func() { terminado <- ejecutarServidor(servidor, listener, senales) }()
+
+	type respuesta struct {
+		cuerpo string
+		err    error
+	}
+	enCurso := make(chan respuesta, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			enCurso <- respuesta{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		cuerpo, err := io.ReadAll(resp.Body)
+		enCurso <- respuesta{cuerpo: string(cuerpo), err: err}
+	}()
+	<-iniciada
+
+	senales <- syscall.SIGTERM
+	select {
+	case err := <-terminado:
+		t.Fatalf("el servidor terminó (%v) sin esperar la petición en curso", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Durante el cierre ya no se aceptan conexiones nuevas
+	if resp, err := http.Get(url); err == nil {
+		resp.Body.Close()
+		t.Error("el servidor aceptó una petición nueva durante el cierre")
+	}
+
+	close(liberar)
+	if r := <-enCurso; r.err != nil || r.cuerpo != "completa" {
+		t.Errorf("petición en curso: cuerpo = %q, error = %v", r.cuerpo, r.err)
+	}
+	select {
+	case err := <-terminado:
+		if err != nil {
+			t.Errorf("ejecutarServidor = %v, se esperaba nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("el servidor no terminó después de la última petición")
+	}
+}
+
+func TestEjecutarServidorDevuelveErroresDelListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.Close()
+
+	if err := ejecutarServidor(&http.Server{}, listener, make(chan os.Signal)); err == nil {
+		t.Error("se esperaba el error de Serve con el listener cerrado")
+	}
+}
+
+func TestResolverPuerto(t *testing.T) {
+	casos := []struct {
+		nombre, flag, env string
+		puerto            int
+		invalido          bool
+	}{
+		{nombre: "por defecto", puerto: 8085},
+		{nombre: "variable PORT", env: "9000", puerto: 9000},
+		{nombre: "el flag gana a PORT", flag: "9100", env: "9000", puerto: 9100},
+		{nombre: "puerto libre", env: "0", puerto: 0},
+		{nombre: "no numérico", env: "ochenta", invalido: true},
+		{nombre: "negativo", flag: "-1", invalido: true},
+		{nombre: "fuera de rango", flag: "65536", invalido: true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			puerto, err := resolverPuerto(caso.flag, caso.env)
+			if caso.invalido {
+				if err == nil {
+					t.Errorf("resolverPuerto(%q, %q) = %d, se esperaba un error", caso.flag, caso.env, puerto)
+				}
+				return
+			}
+			if err != nil || puerto != caso.puerto {
+				t.Errorf("resolverPuerto(%q, %q) = %d, %v; se esperaba %d", caso.flag, caso.env, puerto, err, caso.puerto)
+			}
+		})
+	}
+}
+
+func TestPuertoCeroEligeUnPuertoLibreYLoAnuncia(t *testing.T) {
+	listener, puerto, err := abrirListener(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if puerto == 0 {
+		t.Fatal("abrirListener(0) no devolvió el puerto elegido por el sistema operativo")
+	}
+
+	// El puerto anunciado es el que realmente atiende conexiones
+	conexion, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", puerto))
+	if err != nil {
+		t.Fatalf("no se pudo conectar al puerto %d: %v", puerto, err)
+	}
+	conexion.Close()
+
+	var salida strings.Builder
+	anunciarServidor(&salida, puerto)
+	if esperado := fmt.Sprintf("http://localhost:%d\n", puerto); !strings.Contains(salida.String(), esperado) {
+		t.Errorf("el anuncio %q no incluye %q", salida.String(), esperado)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// validacionEstricta indica si se rechazan las cédulas con patrones sospechosos.
+// Se configura con STRICT_VALIDATION; sin ella los patrones solo se registran en los logs.
+var validacionEstricta atomic.Bool
+
+// motivoCedulaSospechosa revisa los dígitos posteriores al código de provincia (sin el
+// dígito verificador) y devuelve el motivo si forman un patrón sospechoso, o "" si no
+func motivoCedulaSospechosa(cedula string) string {
+	cuerpo := cedula[2:9]
+
+	iguales, ascendentes, descendentes := true, true, true
+	for i := 1; i < len(cuerpo); i++ {
+		anterior, actual := cuerpo[i-1]-'0', cuerpo[i]-'0'
+		if actual != anterior {
+			iguales = false
+		}
+		if actual != (anterior+1)%10 {
+			ascendentes = false
+		}
+		if actual != (anterior+9)%10 {
+			descendentes = false
+		}
+	}
+
+	switch {
+	case iguales:
+		return "todos los dígitos después de la provincia son iguales"
+	case ascendentes || descendentes:
+		return "los dígitos después de la provincia son secuenciales"
+	}
+	return ""
+}
+
+// revisarCedulaSospechosa registra con el logger de la petición las cédulas con patrones
+// sospechosos y, en modo estricto, devuelve el error con el que se rechazan
+func revisarCedulaSospechosa(ctx context.Context, identificacion string) error {
+	motivo := motivoCedulaSospechosa(identificacion)
+	if motivo == "" {
+		return nil
+	}
+
+	cedula.LoggerFrom(ctx).Info("Cédula con patrón sospechoso", "cedula", cedula.Redact(identificacion), "motivo", motivo)
+	if !validacionEstricta.Load() {
+		return nil
+	}
+	return &errorAPI{
+		codigo:  CodigoCedulaSospechosa,
+		estado:  http.StatusBadRequest,
+		mensaje: "Cédula rechazada por validación estricta: " + motivo,
+	}
+}
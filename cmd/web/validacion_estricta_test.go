@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// activarValidacionEstricta fija STRICT_VALIDATION durante la prueba
+func activarValidacionEstricta(t *testing.T, estricta bool) {
+	t.Helper()
+	anterior := validacionEstricta.Load()
+	validacionEstricta.Store(estricta)
+	t.Cleanup(func() { validacionEstricta.Store(anterior) })
+}
+
+func TestMotivoCedulaSospechosa(t *testing.T) {
+	casos := []struct {
+		cedula     string
+		sospechosa bool
+	}{
+		{"1710034065", false},
+		{"0911111110", true}, // dígitos iguales
+		{"1712345675", true}, // ascendentes
+		{"1789012340", true}, // ascendentes pasando por el 0
+		{"1776543210", true}, // descendentes
+		{"1710034065001", false},
+	}
+	for _, caso := range casos {
+		if got := motivoCedulaSospechosa(caso.cedula) != ""; got != caso.sospechosa {
+			t.Errorf("motivoCedulaSospechosa(%q) sospechosa = %v, se esperaba %v", caso.cedula, got, caso.sospechosa)
+		}
+	}
+}
+
+func TestValidarIdentificacionEstrictaYPermisiva(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		estricta  bool
+		cedula    string
+		rechazada bool
+		registra  bool
+	}{
+		{"normal permisiva", false, "1710034065", false, false},
+		{"normal estricta", true, "1710034065", false, false},
+		{"sospechosa permisiva", false, "0911111110", false, true},
+		{"sospechosa estricta", true, "0911111110", true, true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			activarValidacionEstricta(t, caso.estricta)
+			var registros bytes.Buffer
+			ctx := cedula.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&registros, nil)))
+
+			_, err := validarIdentificacion(ctx, caso.cedula)
+			if rechazada := err != nil; rechazada != caso.rechazada {
+				t.Fatalf("error = %v, se esperaba rechazo = %v", err, caso.rechazada)
+			}
+			if err != nil && comoErrorAPI(err).codigo != CodigoCedulaSospechosa {
+				t.Errorf("código = %s, se esperaba %s", comoErrorAPI(err).codigo, CodigoCedulaSospechosa)
+			}
+
+			registrado := strings.Contains(registros.String(), "patrón sospechoso")
+			if registrado != caso.registra {
+				t.Errorf("registrado en el logger de la petición = %v, se esperaba %v: %s", registrado, caso.registra, registros.String())
+			}
+			if strings.Contains(registros.String(), caso.cedula) {
+				t.Errorf("el registro no debe incluir la cédula completa: %s", registros.String())
+			}
+		})
+	}
+}
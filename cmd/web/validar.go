@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"consulta-cedula-app/pkg/cedula"
+)
+
+// ValidacionResponse es la respuesta de /api/validar
+type ValidacionResponse struct {
+	XMLName xml.Name `json:"-" xml:"validacionResponse"`
+	Valida  bool     `json:"valida" xml:"valida"`
+	// Provincia solo se incluye si la identificación es válida
+	Provincia string `json:"provincia,omitempty" xml:"provincia,omitempty"`
+}
+
+// manejarValidacion maneja las peticiones GET a /api/validar?cedula=: revisa localmente la
+// estructura y el dígito verificador de una cédula o RUC, sin consultar el SRI. Una
+// identificación inválida no es un error de la petición, así que se responde 200 con valida=false.
+func manejarValidacion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, errMetodoNoPermitido)
+		return
+	}
+	if !r.URL.Query().Has("cedula") {
+		var validacion ValidationError
+		validacion.Agregar("cedula", "El parámetro cedula es obligatorio")
+		writeError(w, r, validacion.Err())
+		return
+	}
+
+	var respuesta ValidacionResponse
+	identificacion, ok := cedula.Normalize(r.URL.Query().Get("cedula"))
+	if len(identificacion) == 13 {
+		respuesta.Valida = cedula.ValidateRUC(identificacion)
+	} else {
+		respuesta.Valida = ok && cedula.ValidateCedula(identificacion)
+	}
+	if respuesta.Valida {
+		respuesta.Provincia, _ = cedula.Province(identificacion)
+	}
+	escribirRespuesta(w, r, http.StatusOK, respuesta)
+}
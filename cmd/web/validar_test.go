@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestManejarValidacion(t *testing.T) {
+	usarSRIProhibido(t)
+
+	casos := []struct {
+		nombre, cedula string
+		espera         ValidacionResponse
+	}{
+		{"cédula válida", "1710034065", ValidacionResponse{Valida: true, Provincia: "Pichincha"}},
+		{"cédula válida con guion", "091234567-5", ValidacionResponse{Valida: true, Provincia: "Guayas"}},
+		{"RUC válido", "1710034065001", ValidacionResponse{Valida: true, Provincia: "Pichincha"}},
+		{"dígito verificador inválido", "1710034064", ValidacionResponse{}},
+		{"muy corta", "171003406", ValidacionResponse{}},
+		{"muy larga", "17100340650", ValidacionResponse{}},
+		{"no numérica", "17100340AB", ValidacionResponse{}},
+		{"vacía", "", ValidacionResponse{}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			manejarValidacion(rec, httptest.NewRequest(http.MethodGet, "/api/validar?cedula="+url.QueryEscape(caso.cedula), nil))
+
+			// Una identificación inválida no es un error de la petición
+			if rec.Code != http.StatusOK {
+				t.Fatalf("estado = %d, se esperaba 200: %s", rec.Code, rec.Body.String())
+			}
+			var respuesta ValidacionResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Valida != caso.espera.Valida || respuesta.Provincia != caso.espera.Provincia {
+				t.Errorf("respuesta = %+v, se esperaba %+v", respuesta, caso.espera)
+			}
+		})
+	}
+}
+
+func TestManejarValidacionErroresDePeticion(t *testing.T) {
+	usarSRIProhibido(t)
+
+	casos := []struct {
+		nombre, metodo, ruta string
+		estado               int
+		codigo               CodigoError
+	}{
+		{"sin parámetro", http.MethodGet, "/api/validar", http.StatusBadRequest, CodigoValidacion},
+		{"método no permitido", http.MethodPost, "/api/validar?cedula=1710034065", http.StatusMethodNotAllowed, CodigoMetodoNoPermitido},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			manejarValidacion(rec, httptest.NewRequest(caso.metodo, caso.ruta, nil))
+
+			if rec.Code != caso.estado {
+				t.Fatalf("estado = %d, se esperaba %d", rec.Code, caso.estado)
+			}
+			var respuesta ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Code != caso.codigo {
+				t.Errorf("código = %s, se esperaba %s", respuesta.Code, caso.codigo)
+			}
+		})
+	}
+}
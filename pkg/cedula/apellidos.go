@@ -0,0 +1,69 @@
+package cedula
+
+import "strings"
+
+// particulasApellido son las palabras que forman parte de un apellido compuesto junto con la
+// palabra que les sigue ("DE LA TORRE", "DEL POZO", "SAN MARTIN", "VAN DER BERG")
+var particulasApellido = map[string]bool{
+	"DE": true, "DEL": true, "LA": true, "LAS": true, "LOS": true,
+	"SAN": true, "SANTA": true, "VAN": true, "VON": true, "DER": true,
+	"DA": true, "DI": true, "DO": true, "DOS": true, "MC": true, "MAC": true,
+}
+
+// agruparApellidos une cada secuencia de partículas con la palabra que la sigue, de modo
+// que cada grupo es un apellido (o un nombre) completo
+func agruparApellidos(palabras []string) []string {
+	var grupos []string
+	var actual []string
+	for _, palabra := range palabras {
+		actual = append(actual, palabra)
+		if particulasApellido[strings.ToUpper(palabra)] {
+			continue
+		}
+		grupos = append(grupos, strings.Join(actual, " "))
+		actual = nil
+	}
+	// Partículas sueltas al final (nombre mal formado): se agregan al último grupo
+	if len(actual) > 0 {
+		if len(grupos) == 0 {
+			return []string{strings.Join(actual, " ")}
+		}
+		grupos[len(grupos)-1] += " " + strings.Join(actual, " ")
+	}
+	return grupos
+}
+
+// componentesNombre divide un nombre completo en el orden del SRI en primer y segundo apellido y
+// primer y segundo nombre, respetando las partículas. Los componentes ausentes quedan vacíos; si
+// hay más de dos nombres, el segundo incluye todos los que siguen al primero.
+func componentesNombre(nombreCompleto string) (primerApellido, segundoApellido, primerNombre, segundoNombre string) {
+	grupos := agruparApellidos(strings.Fields(nombreCompleto))
+	switch len(grupos) {
+	case 0:
+	case 1:
+		primerNombre = grupos[0]
+	case 2:
+		primerApellido, primerNombre = grupos[0], grupos[1]
+	default:
+		primerApellido, segundoApellido, primerNombre = grupos[0], grupos[1], grupos[2]
+		segundoNombre = strings.Join(grupos[3:], " ")
+	}
+	return primerApellido, segundoApellido, primerNombre, segundoNombre
+}
+
+// separarApellidosNombres separa un nombre completo en el orden del SRI ("APELLIDOS NOMBRES"):
+// los dos primeros apellidos (con sus partículas) y el resto como nombres. Con solo dos
+// grupos se toma un apellido y un nombre; con uno solo, todo se considera nombre.
+func separarApellidosNombres(nombreCompleto string) (apellidos, nombres string) {
+	grupos := agruparApellidos(strings.Fields(nombreCompleto))
+	switch len(grupos) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", grupos[0]
+	case 2:
+		return grupos[0], grupos[1]
+	default:
+		return strings.Join(grupos[:2], " "), strings.Join(grupos[2:], " ")
+	}
+}
@@ -0,0 +1,84 @@
+package cedula
+
+import "testing"
+
+func TestSepararApellidosNombres(t *testing.T) {
+	casos := []struct {
+		completo  string
+		apellidos string
+		nombres   string
+	}{
+		{"", "", ""},
+		{"JUAN", "", "JUAN"},
+		{"PEREZ JUAN", "PEREZ", "JUAN"},
+		{"PEREZ LOPEZ JUAN", "PEREZ LOPEZ", "JUAN"},
+		{"PEREZ LOPEZ JUAN CARLOS", "PEREZ LOPEZ", "JUAN CARLOS"},
+		{"  PEREZ   LOPEZ  JUAN  CARLOS ", "PEREZ LOPEZ", "JUAN CARLOS"},
+		{"DE LA TORRE PEREZ MARIA JOSE", "DE LA TORRE PEREZ", "MARIA JOSE"},
+		{"PEREZ DE LA TORRE MARIA JOSE", "PEREZ DE LA TORRE", "MARIA JOSE"},
+		{"DEL POZO SAN MARTIN ANA", "DEL POZO SAN MARTIN", "ANA"},
+		{"VAN DER BERG JUAN", "VAN DER BERG", "JUAN"},
+		{"de la torre perez maria", "de la torre perez", "maria"},
+		{"MARIA DE LOS ANGELES", "MARIA", "DE LOS ANGELES"},
+		// Partículas sueltas al final se agregan al último grupo
+		{"PEREZ LOPEZ JUAN DE", "PEREZ LOPEZ", "JUAN DE"},
+		{"DE LA", "", "DE LA"},
+	}
+	for _, caso := range casos {
+		apellidos, nombres := separarApellidosNombres(caso.completo)
+		if apellidos != caso.apellidos || nombres != caso.nombres {
+			t.Errorf("separarApellidosNombres(%q) = %q, %q; se esperaba %q, %q", caso.completo, apellidos, nombres, caso.apellidos, caso.nombres)
+		}
+	}
+}
+
+func TestSepararNombreSegunTipoPersona(t *testing.T) {
+	casos := []struct {
+		completo string
+		tipo     string
+		nombre   string
+		apellido string
+	}{
+		{"PEREZ LOPEZ JUAN CARLOS", NaturalPerson, "JUAN CARLOS", "PEREZ LOPEZ"},
+		{"PEREZ JUAN", NaturalPerson, "JUAN", "PEREZ"},
+		// Las razones sociales no se separan, aunque tengan partículas o muchas palabras
+		{"CORPORACION FAVORITA C.A.", LegalEntity, "CORPORACION FAVORITA C.A.", ""},
+		{"BANCO DE LA PRODUCCION S.A. PRODUBANCO", LegalEntity, "BANCO DE LA PRODUCCION S.A. PRODUBANCO", ""},
+		{"GOBIERNO AUTONOMO DESCENTRALIZADO DE QUITO", LegalEntity, "GOBIERNO AUTONOMO DESCENTRALIZADO DE QUITO", ""},
+	}
+	for _, caso := range casos {
+		nombre, apellido := separarNombre(caso.completo, caso.tipo)
+		if nombre != caso.nombre || apellido != caso.apellido {
+			t.Errorf("separarNombre(%q, %s) = %q, %q; se esperaba %q, %q", caso.completo, caso.tipo, nombre, apellido, caso.nombre, caso.apellido)
+		}
+	}
+}
+
+func TestComponentesNombre(t *testing.T) {
+	casos := []struct {
+		completo                                                     string
+		primerApellido, segundoApellido, primerNombre, segundoNombre string
+	}{
+		{"", "", "", "", ""},
+		{"JUAN", "", "", "JUAN", ""},
+		// Dos palabras: un apellido y un nombre
+		{"PEREZ JUAN", "PEREZ", "", "JUAN", ""},
+		// Tres palabras: dos apellidos y un nombre
+		{"PEREZ LOPEZ JUAN", "PEREZ", "LOPEZ", "JUAN", ""},
+		// Cuatro palabras: dos apellidos y dos nombres
+		{"PEREZ LOPEZ JUAN CARLOS", "PEREZ", "LOPEZ", "JUAN", "CARLOS"},
+		// Más de dos nombres: el segundo incluye todos los que siguen al primero
+		{"PEREZ LOPEZ JUAN CARLOS ANDRES", "PEREZ", "LOPEZ", "JUAN", "CARLOS ANDRES"},
+		{"DE LA TORRE PEREZ MARIA JOSE", "DE LA TORRE", "PEREZ", "MARIA", "JOSE"},
+		{"PEREZ DEL POZO ANA", "PEREZ", "DEL POZO", "ANA", ""},
+		{"PEREZ LOPEZ MARIA DE LOS ANGELES", "PEREZ", "LOPEZ", "MARIA", "DE LOS ANGELES"},
+	}
+	for _, caso := range casos {
+		primerApellido, segundoApellido, primerNombre, segundoNombre := componentesNombre(caso.completo)
+		obtenido := [4]string{primerApellido, segundoApellido, primerNombre, segundoNombre}
+		esperado := [4]string{caso.primerApellido, caso.segundoApellido, caso.primerNombre, caso.segundoNombre}
+		if obtenido != esperado {
+			t.Errorf("componentesNombre(%q) = %q, se esperaba %q", caso.completo, obtenido, esperado)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cedula
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// DefaultUserAgents son los User-Agent de navegadores actuales entre los que se rota cuando el
+// Client no configura UserAgents
+var DefaultUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0",
+	"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36",
+}
+
+// cabecerasPorDefecto completan la petición como la haría un navegador; Client.Headers las reemplaza
+var cabecerasPorDefecto = http.Header{
+	"Accept":          {"application/json, text/plain, */*"},
+	"Accept-Language": {"es-ES,es;q=0.9,en;q=0.8"},
+	"Referer":         {"https://srienlinea.sri.gob.ec/"},
+}
+
+// siguienteAgente es el contador del round-robin de User-Agent, compartido por todos los Client
+var siguienteAgente atomic.Uint64
+
+// agente devuelve el User-Agent de la próxima petición, rotando entre los configurados
+func (c *Client) agente() string {
+	agentes := c.UserAgents
+	if len(agentes) == 0 {
+		agentes = DefaultUserAgents
+	}
+	return agentes[(siguienteAgente.Add(1)-1)%uint64(len(agentes))]
+}
+
+// aplicarCabeceras agrega a la petición el User-Agent, las cabeceras por defecto y las de
+// Client.Headers, que tienen prioridad. Accept-Encoding no se puede reemplazar porque
+// leerCuerpo solo sabe descomprimir los encodings de encodingsAceptados.
+func (c *Client) aplicarCabeceras(req *http.Request) {
+	req.Header.Set("User-Agent", c.agente())
+	for nombre, valores := range cabecerasPorDefecto {
+		req.Header[nombre] = valores
+	}
+	for nombre, valores := range c.Headers {
+		req.Header[http.CanonicalHeaderKey(nombre)] = valores
+	}
+	req.Header.Set("Accept-Encoding", encodingsAceptados)
+}
@@ -0,0 +1,117 @@
+package cedula
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// cabecerasRecibidas levanta un SRI de prueba y devuelve una función con las cabeceras de cada
+// petición que recibió, en orden
+func cabecerasRecibidas(t *testing.T) (string, func() []http.Header) {
+	t.Helper()
+	var mu sync.Mutex
+	var recibidas []http.Header
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		recibidas = append(recibidas, r.Header.Clone())
+		mu.Unlock()
+		return false
+	})
+	return servidor.URL, func() []http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return recibidas
+	}
+}
+
+func TestLookupRotaElUserAgent(t *testing.T) {
+	url, recibidas := cabecerasRecibidas(t)
+	agentes := []string{"Agente/1", "Agente/2", "Agente/3"}
+	cliente := &Client{Hosts: NewHosts(url), UserAgents: agentes}
+
+	for i := 0; i < 2*len(agentes); i++ {
+		if _, err := cliente.Lookup(context.Background(), "1710034065"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cabeceras := recibidas()
+	if len(cabeceras) != 2*len(agentes) {
+		t.Fatalf("peticiones recibidas = %d, se esperaban %d", len(cabeceras), 2*len(agentes))
+	}
+	// El contador es compartido, así que no se sabe con cuál empieza: se comprueba que cada
+	// vuelta use todos los agentes y que la segunda repita el orden de la primera
+	var usados []string
+	for i, cabecera := range cabeceras {
+		agente := cabecera.Get("User-Agent")
+		if !slices.Contains(agentes, agente) {
+			t.Errorf("petición %d: User-Agent %q no es uno de los configurados", i, agente)
+		}
+		usados = append(usados, agente)
+	}
+	primera := slices.Clone(usados[:len(agentes)])
+	slices.Sort(primera)
+	if !slices.Equal(primera, agentes) {
+		t.Errorf("la primera vuelta usó %q, se esperaban todos los agentes", usados[:len(agentes)])
+	}
+	if !slices.Equal(usados[:len(agentes)], usados[len(agentes):]) {
+		t.Errorf("la rotación no es round-robin: %q", usados)
+	}
+}
+
+func TestLookupEnviaLasCabecerasPorDefecto(t *testing.T) {
+	url, recibidas := cabecerasRecibidas(t)
+	cliente := &Client{Hosts: NewHosts(url)}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); err != nil {
+		t.Fatal(err)
+	}
+
+	cabecera := recibidas()[0]
+	if !slices.Contains(DefaultUserAgents, cabecera.Get("User-Agent")) {
+		t.Errorf("User-Agent = %q, se esperaba uno de DefaultUserAgents", cabecera.Get("User-Agent"))
+	}
+	for nombre, valores := range cabecerasPorDefecto {
+		if got := cabecera.Get(nombre); got != valores[0] {
+			t.Errorf("%s = %q, se esperaba %q", nombre, got, valores[0])
+		}
+	}
+	if got := cabecera.Get("Accept-Encoding"); got != encodingsAceptados {
+		t.Errorf("Accept-Encoding = %q, se esperaba %q", got, encodingsAceptados)
+	}
+}
+
+func TestLookupCabecerasConfiguradasTienenPrioridad(t *testing.T) {
+	url, recibidas := cabecerasRecibidas(t)
+	cliente := &Client{
+		Hosts:      NewHosts(url),
+		UserAgents: []string{"Agente/unico"},
+		Headers: http.Header{
+			"referer":         {"https://ejemplo.ec/"},
+			"X-Extra":         {"valor"},
+			"Accept-Encoding": {"br"},
+		},
+	}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); err != nil {
+		t.Fatal(err)
+	}
+
+	cabecera := recibidas()[0]
+	esperadas := map[string]string{
+		"User-Agent":      "Agente/unico",
+		"Referer":         "https://ejemplo.ec/",
+		"X-Extra":         "valor",
+		"Accept-Language": cabecerasPorDefecto.Get("Accept-Language"),
+		// Accept-Encoding no se puede reemplazar: solo se sabe descomprimir encodingsAceptados
+		"Accept-Encoding": encodingsAceptados,
+	}
+	for nombre, valor := range esperadas {
+		if got := cabecera.Get(nombre); got != valor {
+			t.Errorf("%s = %q, se esperaba %q", nombre, got, valor)
+		}
+	}
+}
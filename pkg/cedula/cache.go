@@ -0,0 +1,195 @@
+package cedula
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de la caché de consultas. DefaultCacheJitter es la variación aleatoria del
+// TTL de cada entrada (±10 %), para que las entradas guardadas a la vez no venzan todas juntas y
+// provoquen una ráfaga de consultas al SRI.
+const (
+	DefaultCacheSize   = 1000
+	DefaultCacheTTL    = time.Hour
+	DefaultCacheJitter = 0.1
+)
+
+// variarTTL devuelve ttl variado al azar dentro de ±variacion (una fracción entre 0 y 1); un ttl
+// no positivo se reemplaza por DefaultCacheTTL
+func variarTTL(ttl time.Duration, variacion float64) time.Duration {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if variacion <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration((rand.Float64()*2-1)*variacion*float64(ttl))
+}
+
+// limitarVariacion acota la variación del TTL entre 0 y 1
+func limitarVariacion(variacion float64) float64 {
+	return min(max(variacion, 0), 1)
+}
+
+// Cache guarda los resultados exitosos de Lookup por identificación. Get devuelve una copia
+// que el llamador puede modificar; los errores del almacenamiento se tratan como ausencia del
+// resultado, nunca como un fallo de la consulta. Hay una implementación en memoria (NewCache y
+// NewCompactCache), otra en disco que sobrevive a los reinicios (NewDiskCache) y otra en Redis
+// que comparten varias réplicas (NewRedisCache).
+type Cache interface {
+	Get(id string) (*Result, bool)
+	// Set guarda el resultado con la vigencia ttl, a la que cada implementación puede sumar su
+	// variación aleatoria; un ttl no positivo se reemplaza por DefaultCacheTTL
+	Set(id string, resultado *Result, ttl time.Duration)
+}
+
+// sinCache es la Cache de los Client que no configuran una: nunca encuentra nada ni guarda nada
+type sinCache struct{}
+
+func (sinCache) Get(string) (*Result, bool)         { return nil, false }
+func (sinCache) Set(string, *Result, time.Duration) {}
+
+// entradaCache es un resultado guardado junto con su vencimiento. En una caché compacta
+// el resultado se guarda serializado en compacto y valor queda en nil.
+type entradaCache struct {
+	id       string
+	valor    *Result
+	compacto []byte
+	vence    time.Time
+}
+
+// MemoryCache guarda en memoria los resultados exitosos de Lookup por identificación, con un
+// máximo de entradas (se descarta la usada hace más tiempo) y la vigencia que indique Set.
+// Los "no encontrado" y los errores nunca se guardan.
+type MemoryCache struct {
+	mu        sync.Mutex
+	capacidad int
+	variacion float64
+	orden     *list.List
+	entradas  map[string]*list.Element
+	ahora     func() time.Time
+	aciertos  uint64
+	fallos    uint64
+	// internas no es nil en las cachés compactas (ver NewCompactCache)
+	internas *tablaInterna
+}
+
+// NewCache crea una caché con la capacidad indicada; una capacidad no positiva se reemplaza
+// por DefaultCacheSize
+func NewCache(capacidad int) *MemoryCache {
+	if capacidad <= 0 {
+		capacidad = DefaultCacheSize
+	}
+	return &MemoryCache{
+		capacidad: capacidad,
+		variacion: DefaultCacheJitter,
+		orden:     list.New(),
+		entradas:  make(map[string]*list.Element),
+		ahora:     time.Now,
+	}
+}
+
+// Get devuelve una copia del resultado guardado para la identificación, si existe y no venció
+func (c *MemoryCache) Get(id string) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elemento, ok := c.entradas[id]
+	if !ok {
+		c.fallos++
+		return nil, false
+	}
+	entrada := elemento.Value.(*entradaCache)
+	if !c.ahora().Before(entrada.vence) {
+		c.quitar(elemento)
+		c.fallos++
+		return nil, false
+	}
+
+	c.orden.MoveToFront(elemento)
+	c.aciertos++
+	// Se devuelve una copia para que el llamador pueda modificarla sin alterar la caché
+	copia := c.leer(entrada)
+	return &copia, true
+}
+
+// Set guarda una copia del resultado para la identificación, descartando la entrada usada
+// hace más tiempo si la caché está llena. La vigencia es ttl con la variación de SetJitter.
+func (c *MemoryCache) Set(id string, resultado *Result, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vence := c.ahora().Add(variarTTL(ttl, c.variacion))
+	if elemento, ok := c.entradas[id]; ok {
+		entrada := elemento.Value.(*entradaCache)
+		c.guardar(entrada, resultado)
+		entrada.vence = vence
+		c.orden.MoveToFront(elemento)
+		return
+	}
+
+	entrada := &entradaCache{id: id, vence: vence}
+	c.guardar(entrada, resultado)
+	c.entradas[id] = c.orden.PushFront(entrada)
+	if c.orden.Len() > c.capacidad {
+		c.quitar(c.orden.Back())
+	}
+}
+
+// quitar descarta el elemento de la caché y, si es compacta, suelta sus valores internados
+func (c *MemoryCache) quitar(elemento *list.Element) {
+	entrada := elemento.Value.(*entradaCache)
+	c.orden.Remove(elemento)
+	delete(c.entradas, entrada.id)
+	if c.internas != nil {
+		liberarCompacto(entrada.compacto, c.internas)
+	}
+}
+
+// guardar almacena una copia del resultado en la entrada, serializada si la caché es compacta.
+// Al reemplazar una entrada compacta se sueltan los valores internados de la anterior después
+// de codificar la nueva, para no liberar y volver a agregar los que comparten.
+func (c *MemoryCache) guardar(entrada *entradaCache, resultado *Result) {
+	if c.internas != nil {
+		anterior := entrada.compacto
+		entrada.compacto = codificarCompacto(resultado, c.internas)
+		if anterior != nil {
+			liberarCompacto(anterior, c.internas)
+		}
+		return
+	}
+	copia := *resultado
+	entrada.valor = &copia
+}
+
+// leer devuelve una copia del resultado guardado en la entrada
+func (c *MemoryCache) leer(entrada *entradaCache) Result {
+	if c.internas != nil {
+		return decodificarCompacto(entrada.compacto, c.internas)
+	}
+	return *entrada.valor
+}
+
+// SetJitter cambia la variación aleatoria (fracción entre 0 y 1) del TTL de las entradas que se
+// guarden a partir de ahora; 0 la desactiva
+func (c *MemoryCache) SetJitter(variacion float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.variacion = limitarVariacion(variacion)
+}
+
+// Len devuelve la cantidad de entradas guardadas, incluidas las vencidas aún no descartadas
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.orden.Len()
+}
+
+// Stats devuelve cuántas consultas a la caché encontraron un resultado vigente y cuántas no
+func (c *MemoryCache) Stats() (aciertos, fallos uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.aciertos, c.fallos
+}
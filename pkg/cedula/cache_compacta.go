@@ -0,0 +1,253 @@
+package cedula
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// NewCompactCache crea una caché como NewCache que guarda cada resultado serializado en un
+// formato binario compacto y comparte entre entradas los valores repetidos (actividades
+// económicas, provincias y fuentes). Ocupa bastante menos memoria por entrada a cambio de
+// decodificar en cada Get.
+func NewCompactCache(capacidad int) *MemoryCache {
+	c := NewCache(capacidad)
+	c.internas = &tablaInterna{
+		actividades: nuevaReservaInterna[Activity](),
+		textos:      nuevaReservaInterna[string](),
+	}
+	return c
+}
+
+// tablaInterna guarda una sola vez cada valor repetido entre entradas; las entradas compactas
+// solo guardan su índice. Se protege con el mutex de la caché.
+type tablaInterna struct {
+	actividades *reservaInterna[Activity]
+	// textos guarda la provincia y la fuente, que se repiten en casi todas las entradas
+	textos *reservaInterna[string]
+}
+
+// reservaInterna guarda cada valor distinto una sola vez junto con la cantidad de entradas que
+// lo usan. Cuando ninguna lo usa se libera y su índice se reutiliza, para que la tabla no crezca
+// sin límite a medida que las entradas vencen o se descartan.
+type reservaInterna[T comparable] struct {
+	indices map[T]uint64
+	valores []T
+	usos    []int
+	libres  []uint64
+}
+
+func nuevaReservaInterna[T comparable]() *reservaInterna[T] {
+	return &reservaInterna[T]{indices: make(map[T]uint64)}
+}
+
+// tomar devuelve el índice del valor, agregándolo si es nuevo, y cuenta un uso más
+func (r *reservaInterna[T]) tomar(valor T) uint64 {
+	if i, ok := r.indices[valor]; ok {
+		r.usos[i]++
+		return i
+	}
+
+	var i uint64
+	if n := len(r.libres); n > 0 {
+		i, r.libres = r.libres[n-1], r.libres[:n-1]
+		r.valores[i], r.usos[i] = valor, 1
+	} else {
+		i = uint64(len(r.valores))
+		r.valores = append(r.valores, valor)
+		r.usos = append(r.usos, 1)
+	}
+	r.indices[valor] = i
+	return i
+}
+
+// valor devuelve el valor guardado en el índice
+func (r *reservaInterna[T]) valor(i uint64) T {
+	return r.valores[i]
+}
+
+// soltar descuenta un uso del índice y libera el valor si ya nadie lo usa
+func (r *reservaInterna[T]) soltar(i uint64) {
+	r.usos[i]--
+	if r.usos[i] > 0 {
+		return
+	}
+	var vacio T
+	delete(r.indices, r.valores[i])
+	r.valores[i] = vacio
+	r.libres = append(r.libres, i)
+}
+
+// len devuelve la cantidad de valores distintos en uso
+func (r *reservaInterna[T]) len() int {
+	return len(r.indices)
+}
+
+// Marcas del primer byte de una entrada compacta
+const (
+	marcaTieneDeudas byte = 1 << iota
+	marcaMontoTotal
+	marcaNombresAnteriores
+	// marcaNombresDistintos indica que Nombres y Apellidos no coinciden con Nombre y Apellido
+	// y se guardan aparte; en el caso habitual se guardan una sola vez
+	marcaNombresDistintos
+	marcaDigitoVerificadorValido
+	marcaDeRespaldo
+	marcaConsultadoEn
+)
+
+// codificarCompacto serializa el resultado: un byte de marcas, los índices internados (fuente,
+// provincia y actividades, en ese orden para que liberarCompacto pueda leerlos sin decodificar
+// el resto), el monto y la fecha de consulta (si hay), los textos con su largo como uvarint (Nombres y Apellidos solo si
+// difieren de Nombre y Apellido) y los nombres anteriores (si hay)
+func codificarCompacto(resultado *Result, internas *tablaInterna) []byte {
+	var marcas byte
+	if resultado.TieneDeudas {
+		marcas |= marcaTieneDeudas
+	}
+	if resultado.MontoTotal != 0 {
+		marcas |= marcaMontoTotal
+	}
+	if resultado.NombresAnteriores != nil {
+		marcas |= marcaNombresAnteriores
+	}
+	if resultado.Nombres != resultado.Nombre || resultado.Apellidos != resultado.Apellido {
+		marcas |= marcaNombresDistintos
+	}
+	if resultado.DigitoVerificadorValido {
+		marcas |= marcaDigitoVerificadorValido
+	}
+	if resultado.DeRespaldo {
+		marcas |= marcaDeRespaldo
+	}
+	if !resultado.ConsultadoEn.IsZero() {
+		marcas |= marcaConsultadoEn
+	}
+
+	datos := []byte{marcas}
+	datos = binary.AppendUvarint(datos, internas.textos.tomar(resultado.Fuente))
+	datos = binary.AppendUvarint(datos, internas.textos.tomar(resultado.Provincia))
+	datos = binary.AppendUvarint(datos, uint64(len(resultado.Actividades)))
+	for _, actividad := range resultado.Actividades {
+		datos = binary.AppendUvarint(datos, internas.actividades.tomar(actividad))
+	}
+
+	if marcas&marcaMontoTotal != 0 {
+		datos = binary.LittleEndian.AppendUint64(datos, math.Float64bits(resultado.MontoTotal))
+	}
+	if marcas&marcaConsultadoEn != 0 {
+		datos = binary.AppendVarint(datos, resultado.ConsultadoEn.UnixNano())
+	}
+	textos := []string{
+		resultado.Nombre, resultado.Apellido, resultado.NombreFormateado, resultado.FechaInicioActividades,
+		resultado.PrimerNombre, resultado.SegundoNombre, resultado.PrimerApellido, resultado.SegundoApellido,
+	}
+	for _, texto := range textos {
+		datos = agregarTexto(datos, texto)
+	}
+	if marcas&marcaNombresDistintos != 0 {
+		datos = agregarTexto(datos, resultado.Nombres)
+		datos = agregarTexto(datos, resultado.Apellidos)
+	}
+	if resultado.NombresAnteriores != nil {
+		datos = binary.AppendUvarint(datos, uint64(len(*resultado.NombresAnteriores)))
+		for _, nombre := range *resultado.NombresAnteriores {
+			datos = agregarTexto(datos, nombre)
+		}
+	}
+
+	// Se recorta la capacidad sobrante para que la entrada ocupe solo lo necesario
+	return datos[:len(datos):len(datos)]
+}
+
+// liberarCompacto suelta los valores internados que usa una entrada serializada con
+// codificarCompacto; se invoca al descartarla o reemplazarla
+func liberarCompacto(datos []byte, internas *tablaInterna) {
+	datos = datos[1:]
+	for i := 0; i < 2; i++ {
+		indice, n := binary.Uvarint(datos)
+		datos = datos[n:]
+		internas.textos.soltar(indice)
+	}
+	cantidad, n := binary.Uvarint(datos)
+	datos = datos[n:]
+	for ; cantidad > 0; cantidad-- {
+		indice, n := binary.Uvarint(datos)
+		datos = datos[n:]
+		internas.actividades.soltar(indice)
+	}
+}
+
+// decodificarCompacto reconstruye un resultado serializado con codificarCompacto
+func decodificarCompacto(datos []byte, internas *tablaInterna) Result {
+	var resultado Result
+	marcas := datos[0]
+	datos = datos[1:]
+
+	resultado.TieneDeudas = marcas&marcaTieneDeudas != 0
+	resultado.DigitoVerificadorValido = marcas&marcaDigitoVerificadorValido != 0
+	resultado.DeRespaldo = marcas&marcaDeRespaldo != 0
+
+	indice, n := binary.Uvarint(datos)
+	datos = datos[n:]
+	resultado.Fuente = internas.textos.valor(indice)
+	indice, n = binary.Uvarint(datos)
+	datos = datos[n:]
+	resultado.Provincia = internas.textos.valor(indice)
+	cantidad, n := binary.Uvarint(datos)
+	datos = datos[n:]
+	for ; cantidad > 0; cantidad-- {
+		indice, n := binary.Uvarint(datos)
+		datos = datos[n:]
+		resultado.Actividades = append(resultado.Actividades, internas.actividades.valor(indice))
+	}
+
+	if marcas&marcaMontoTotal != 0 {
+		resultado.MontoTotal = math.Float64frombits(binary.LittleEndian.Uint64(datos))
+		datos = datos[8:]
+	}
+	if marcas&marcaConsultadoEn != 0 {
+		nanosegundos, n := binary.Varint(datos)
+		datos = datos[n:]
+		resultado.ConsultadoEn = time.Unix(0, nanosegundos).UTC()
+	}
+	resultado.Nombre, datos = leerTexto(datos)
+	resultado.Apellido, datos = leerTexto(datos)
+	resultado.NombreFormateado, datos = leerTexto(datos)
+	resultado.FechaInicioActividades, datos = leerTexto(datos)
+	resultado.PrimerNombre, datos = leerTexto(datos)
+	resultado.SegundoNombre, datos = leerTexto(datos)
+	resultado.PrimerApellido, datos = leerTexto(datos)
+	resultado.SegundoApellido, datos = leerTexto(datos)
+	resultado.Nombres, resultado.Apellidos = resultado.Nombre, resultado.Apellido
+	if marcas&marcaNombresDistintos != 0 {
+		resultado.Nombres, datos = leerTexto(datos)
+		resultado.Apellidos, datos = leerTexto(datos)
+	}
+
+	if marcas&marcaNombresAnteriores != 0 {
+		cantidad, n := binary.Uvarint(datos)
+		datos = datos[n:]
+		nombres := make([]string, 0, cantidad)
+		for ; cantidad > 0; cantidad-- {
+			var nombre string
+			nombre, datos = leerTexto(datos)
+			nombres = append(nombres, nombre)
+		}
+		resultado.NombresAnteriores = &nombres
+	}
+	return resultado
+}
+
+// agregarTexto agrega un texto precedido de su largo
+func agregarTexto(datos []byte, texto string) []byte {
+	datos = binary.AppendUvarint(datos, uint64(len(texto)))
+	return append(datos, texto...)
+}
+
+// leerTexto lee un texto escrito con agregarTexto y devuelve el resto de los datos
+func leerTexto(datos []byte) (string, []byte) {
+	largo, n := binary.Uvarint(datos)
+	datos = datos[n:]
+	return string(datos[:largo]), datos[largo:]
+}
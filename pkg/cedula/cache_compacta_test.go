@@ -0,0 +1,120 @@
+package cedula
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// resultadoCompleto arma un resultado con todos los campos que guarda la caché compacta
+func resultadoCompleto(i int) *Result {
+	anteriores := []string{fmt.Sprintf("NOMBRE ANTERIOR %d", i)}
+	return &Result{
+		Nombre:                  fmt.Sprintf("JUAN %d", i),
+		Apellido:                "PEREZ LOPEZ",
+		Nombres:                 fmt.Sprintf("JUAN %d", i),
+		Apellidos:               "PEREZ LOPEZ",
+		PrimerNombre:            "JUAN",
+		PrimerApellido:          "PEREZ",
+		SegundoApellido:         "LOPEZ",
+		Actividades:             []Activity{{Ciiu: fmt.Sprintf("G47%02d", i%20), Descripcion: "VENTA AL POR MENOR"}},
+		NombresAnteriores:       &anteriores,
+		FechaInicioActividades:  "2010-05-01T00:00:00-05:00",
+		Provincia:               "Pichincha",
+		Fuente:                  SourceSRI,
+		DigitoVerificadorValido: true,
+		TieneDeudas:             true,
+		MontoTotal:              125.5,
+		ConsultadoEn:            time.Date(2024, 1, 1, 12, 30, 0, 123, time.UTC),
+	}
+}
+
+func TestCodificarCompactoIdaYVuelta(t *testing.T) {
+	internas := &tablaInterna{actividades: nuevaReservaInterna[Activity](), textos: nuevaReservaInterna[string]()}
+	original := resultadoCompleto(1)
+	original.Nombres = "OTRO NOMBRE"
+
+	if decodificado := decodificarCompacto(codificarCompacto(original, internas), internas); !reflect.DeepEqual(&decodificado, original) {
+		t.Errorf("decodificado = %+v\nse esperaba    %+v", decodificado, *original)
+	}
+
+	// Un resultado de la fuente de respaldo conserva la marca
+	original.Fuente, original.DeRespaldo = SourceFallback, true
+	if decodificado := decodificarCompacto(codificarCompacto(original, internas), internas); !reflect.DeepEqual(&decodificado, original) {
+		t.Errorf("respaldo: decodificado = %+v\nse esperaba    %+v", decodificado, *original)
+	}
+}
+
+func TestCompactCacheLiberaInternados(t *testing.T) {
+	c := NewCompactCache(5)
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("%010d", i), resultadoCompleto(i), time.Hour)
+	}
+
+	// Solo quedan 5 entradas, así que la tabla no debería conservar las actividades de las descartadas
+	if n := c.internas.actividades.len(); n > 5 {
+		t.Errorf("actividades internadas = %d, se esperaba a lo sumo 5", n)
+	}
+	if n := len(c.internas.actividades.valores); n > 6 {
+		t.Errorf("la tabla de actividades creció a %d posiciones; los índices libres deberían reutilizarse", n)
+	}
+	// Provincia y fuente se comparten entre todas las entradas
+	if n := c.internas.textos.len(); n != 2 {
+		t.Errorf("textos internados = %d, se esperaba 2", n)
+	}
+
+	// Reemplazar y vencer entradas también suelta sus valores
+	c.SetJitter(0)
+	ahora, adelantar := relojFijo()
+	c.ahora = ahora
+	for i := 95; i < 100; i++ {
+		c.Set(fmt.Sprintf("%010d", i), resultadoPrueba("JUAN"), time.Hour)
+	}
+	if n := c.internas.actividades.len(); n != 0 {
+		t.Errorf("actividades internadas tras reemplazar = %d, se esperaba 0", n)
+	}
+	adelantar(2 * time.Hour)
+	for i := 95; i < 100; i++ {
+		c.Get(fmt.Sprintf("%010d", i))
+	}
+	if n := c.internas.textos.len(); n != 0 {
+		t.Errorf("textos internados tras vencer = %d, se esperaba 0", n)
+	}
+}
+
+func BenchmarkCompactCache(b *testing.B) {
+	const entradas = 10000
+	for nombre, nueva := range constructoresMemoria {
+		b.Run(nombre, func(b *testing.B) {
+			b.ReportAllocs()
+			resultados := make([]*Result, entradas)
+			ids := make([]string, entradas)
+			for i := range resultados {
+				resultados[i], ids[i] = resultadoCompleto(i), fmt.Sprintf("%010d", i)
+			}
+
+			var antes, despues runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&antes)
+			c := nueva(entradas)
+			for i := range ids {
+				c.Set(ids[i], resultados[i], time.Hour)
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&despues)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				id := ids[i%entradas]
+				c.Set(id, resultados[i%entradas], time.Hour)
+				c.Get(id)
+			}
+			b.StopTimer()
+			// ResetTimer descarta las métricas propias, así que se informan al final
+			b.ReportMetric(float64(despues.HeapAlloc-antes.HeapAlloc)/entradas, "B/entrada")
+			runtime.KeepAlive(c)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package cedula
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketResultados es el bucket de bbolt en el que DiskCache guarda los resultados
+var bucketResultados = []byte("resultados")
+
+// DiskCache guarda los resultados en un archivo bbolt, de modo que sobreviven a los reinicios.
+// Cada entrada es el vencimiento (nanosegundos Unix, 8 bytes) seguido del resultado en JSON.
+// Las entradas vencidas se descartan al leerlas.
+type DiskCache struct {
+	db        *bolt.DB
+	mu        sync.RWMutex
+	variacion float64
+	ahora     func() time.Time
+}
+
+// NewDiskCache abre (o crea) la caché en disco en la ruta indicada. El archivo queda bloqueado
+// mientras la caché esté abierta, así que dos procesos no pueden compartirlo.
+func NewDiskCache(ruta string) (*DiskCache, error) {
+	db, err := bolt.Open(ruta, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir la caché en disco %q: %v", ruta, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketResultados)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("no se pudo preparar la caché en disco %q: %v", ruta, err)
+	}
+
+	return &DiskCache{db: db, variacion: DefaultCacheJitter, ahora: time.Now}, nil
+}
+
+// Get devuelve el resultado guardado para la identificación, si existe y no venció
+func (c *DiskCache) Get(id string) (*Result, bool) {
+	var datos []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		// Los valores solo son válidos durante la transacción, así que se copian
+		if valor := tx.Bucket(bucketResultados).Get([]byte(id)); valor != nil {
+			datos = append([]byte(nil), valor...)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("Error al leer la caché en disco", "error", err)
+		return nil, false
+	}
+	if len(datos) < 8 {
+		return nil, false
+	}
+
+	vence := time.Unix(0, int64(binary.BigEndian.Uint64(datos)))
+	if !c.ahora().Before(vence) {
+		c.borrar(id)
+		return nil, false
+	}
+	var resultado Result
+	if err := json.Unmarshal(datos[8:], &resultado); err != nil {
+		slog.Warn("Entrada inválida en la caché en disco; se descarta", "error", err)
+		c.borrar(id)
+		return nil, false
+	}
+	return &resultado, true
+}
+
+// Set guarda el resultado para la identificación con la vigencia ttl (con la variación de SetJitter)
+func (c *DiskCache) Set(id string, resultado *Result, ttl time.Duration) {
+	c.mu.RLock()
+	vence := c.ahora().Add(variarTTL(ttl, c.variacion))
+	c.mu.RUnlock()
+
+	cuerpo, err := json.Marshal(resultado)
+	if err != nil {
+		slog.Warn("No se pudo serializar el resultado para la caché en disco", "error", err)
+		return
+	}
+	datos := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(cuerpo)), uint64(vence.UnixNano()))
+	datos = append(datos, cuerpo...)
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketResultados).Put([]byte(id), datos)
+	})
+	if err != nil {
+		slog.Warn("Error al escribir en la caché en disco", "error", err)
+	}
+}
+
+// borrar descarta la entrada de la identificación
+func (c *DiskCache) borrar(id string) {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketResultados).Delete([]byte(id))
+	})
+	if err != nil {
+		slog.Warn("Error al borrar una entrada de la caché en disco", "error", err)
+	}
+}
+
+// SetJitter cambia la variación aleatoria (fracción entre 0 y 1) del TTL de las entradas que se
+// guarden a partir de ahora; 0 la desactiva
+func (c *DiskCache) SetJitter(variacion float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.variacion = limitarVariacion(variacion)
+}
+
+// Close cierra el archivo de la caché
+func (c *DiskCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,127 @@
+package cedula
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// abrirCacheDisco abre una caché en disco en la ruta indicada y la cierra al terminar la prueba
+func abrirCacheDisco(t *testing.T, ruta string) *DiskCache {
+	t.Helper()
+	c, err := NewDiskCache(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// vigenciaDisco devuelve cuánto le queda a la entrada guardada para id según el reloj de la caché
+func vigenciaDisco(t *testing.T, c *DiskCache, id string) time.Duration {
+	t.Helper()
+	var vence time.Time
+	err := c.db.View(func(tx *bolt.Tx) error {
+		datos := tx.Bucket(bucketResultados).Get([]byte(id))
+		if len(datos) < 8 {
+			return fmt.Errorf("no hay entrada para %q", id)
+		}
+		vence = time.Unix(0, int64(binary.BigEndian.Uint64(datos)))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vence.Sub(c.ahora())
+}
+
+func TestDiskCacheSobreviveAlReinicio(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "cache.db")
+
+	c, err := NewDiskCache(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := resultadoCompleto(1)
+	c.Set("1710034065", original, time.Hour)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reabierta := abrirCacheDisco(t, ruta)
+	resultado, ok := reabierta.Get("1710034065")
+	if !ok {
+		t.Fatal("la entrada debería seguir en disco tras reabrir la caché")
+	}
+	if resultado.Nombre != original.Nombre || resultado.MontoTotal != original.MontoTotal ||
+		len(resultado.Actividades) != 1 || resultado.Actividades[0] != original.Actividades[0] {
+		t.Errorf("Get = %+v\nse esperaba %+v", *resultado, *original)
+	}
+	if _, ok := reabierta.Get("0912345675"); ok {
+		t.Error("una identificación nunca guardada no debería encontrarse")
+	}
+}
+
+func TestDiskCacheVencimiento(t *testing.T) {
+	c := abrirCacheDisco(t, filepath.Join(t.TempDir(), "cache.db"))
+	c.SetJitter(0)
+	ahora, adelantar := relojFijo()
+	c.ahora = ahora
+
+	c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+	adelantar(59 * time.Minute)
+	if _, ok := c.Get("1710034065"); !ok {
+		t.Fatal("la entrada debería seguir vigente")
+	}
+	adelantar(time.Minute)
+	if _, ok := c.Get("1710034065"); ok {
+		t.Fatal("la entrada debería haber vencido")
+	}
+
+	// La entrada vencida se borra del archivo al leerla
+	c.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketResultados).Get([]byte("1710034065")) != nil {
+			t.Error("la entrada vencida debería borrarse del disco")
+		}
+		return nil
+	})
+}
+
+func TestDiskCacheVariacionTTL(t *testing.T) {
+	const ttl = time.Hour
+	const variacion = 0.2
+	c := abrirCacheDisco(t, filepath.Join(t.TempDir(), "cache.db"))
+	c.SetJitter(variacion)
+	ahora, _ := relojFijo()
+	c.ahora = ahora
+
+	minimo, maximo := 2*ttl, time.Duration(0)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("%010d", i)
+		c.Set(id, resultadoPrueba("JUAN"), ttl)
+		vigencia := vigenciaDisco(t, c, id)
+		minimo, maximo = min(minimo, vigencia), max(maximo, vigencia)
+	}
+
+	if minimo < time.Duration(float64(ttl)*(1-variacion)) || maximo > time.Duration(float64(ttl)*(1+variacion)) {
+		t.Errorf("vigencias entre %v y %v, fuera de ±%.0f %% de %v", minimo, maximo, variacion*100, ttl)
+	}
+	if maximo-minimo < 5*time.Minute {
+		t.Errorf("vigencias entre %v y %v, se esperaba que variaran", minimo, maximo)
+	}
+}
+
+func TestDiskCacheArchivoBloqueado(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "cache.db")
+	abrirCacheDisco(t, ruta)
+
+	// bbolt bloquea el archivo, así que una segunda apertura falla tras el timeout
+	if c, err := NewDiskCache(ruta); err == nil {
+		c.Close()
+		t.Error("abrir dos veces el mismo archivo debería fallar")
+	}
+}
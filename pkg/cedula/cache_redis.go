@@ -0,0 +1,97 @@
+package cedula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// timeoutRedis es el tiempo máximo de cada operación de RedisCache; si Redis no responde a
+// tiempo la consulta sigue como si el resultado no estuviera guardado
+const timeoutRedis = 500 * time.Millisecond
+
+// RedisCache guarda los resultados en Redis para compartirlos entre varias réplicas del
+// servicio. Cada resultado se guarda en JSON con la clave "cedula:<identificación>" y Redis
+// descarta las entradas al vencer. Si Redis no está disponible se trata como ausencia del resultado.
+type RedisCache struct {
+	cliente   *redis.Client
+	mu        sync.RWMutex
+	variacion float64
+}
+
+// NewRedisCache crea la caché a partir de una URL redis:// o rediss://. No se conecta hasta la
+// primera operación.
+func NewRedisCache(direccion string) (*RedisCache, error) {
+	opciones, err := redis.ParseURL(direccion)
+	if err != nil {
+		return nil, fmt.Errorf("URL de Redis inválida: %v", err)
+	}
+	return &RedisCache{cliente: redis.NewClient(opciones), variacion: DefaultCacheJitter}, nil
+}
+
+// claveRedis devuelve la clave de Redis de una identificación
+func claveRedis(id string) string {
+	return "cedula:" + id
+}
+
+// Get devuelve el resultado guardado para la identificación, si existe
+func (c *RedisCache) Get(id string) (*Result, bool) {
+	ctx, cancelar := context.WithTimeout(context.Background(), timeoutRedis)
+	defer cancelar()
+
+	datos, err := c.cliente.Get(ctx, claveRedis(id)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Error al leer la caché en Redis", "error", err)
+		}
+		return nil, false
+	}
+	var resultado Result
+	if err := json.Unmarshal(datos, &resultado); err != nil {
+		slog.Warn("Entrada inválida en la caché en Redis; se ignora", "error", err)
+		return nil, false
+	}
+	return &resultado, true
+}
+
+// Set guarda el resultado para la identificación con la vigencia ttl (con la variación de SetJitter)
+func (c *RedisCache) Set(id string, resultado *Result, ttl time.Duration) {
+	c.mu.RLock()
+	ttl = variarTTL(ttl, c.variacion)
+	c.mu.RUnlock()
+
+	datos, err := json.Marshal(resultado)
+	if err != nil {
+		slog.Warn("No se pudo serializar el resultado para la caché en Redis", "error", err)
+		return
+	}
+	ctx, cancelar := context.WithTimeout(context.Background(), timeoutRedis)
+	defer cancelar()
+	if err := c.cliente.Set(ctx, claveRedis(id), datos, ttl).Err(); err != nil {
+		slog.Warn("Error al escribir en la caché en Redis", "error", err)
+	}
+}
+
+// SetJitter cambia la variación aleatoria (fracción entre 0 y 1) del TTL de las entradas que se
+// guarden a partir de ahora; 0 la desactiva
+func (c *RedisCache) SetJitter(variacion float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.variacion = limitarVariacion(variacion)
+}
+
+// Ping comprueba que Redis responda
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.cliente.Ping(ctx).Err()
+}
+
+// Close cierra las conexiones con Redis
+func (c *RedisCache) Close() error {
+	return c.cliente.Close()
+}
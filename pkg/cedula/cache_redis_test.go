@@ -0,0 +1,117 @@
+package cedula
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// redisPrueba levanta un Redis en memoria y devuelve una RedisCache conectada a él
+func redisPrueba(t *testing.T) (*miniredis.Miniredis, *RedisCache) {
+	t.Helper()
+	servidor := miniredis.RunT(t)
+	c, err := NewRedisCache("redis://" + servidor.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return servidor, c
+}
+
+func TestRedisCacheAciertoYFallo(t *testing.T) {
+	servidor, c := redisPrueba(t)
+
+	if _, ok := c.Get("1710034065"); ok {
+		t.Fatal("la caché vacía no debería encontrar nada")
+	}
+	original := resultadoCompleto(1)
+	c.Set("1710034065", original, time.Hour)
+	if !servidor.Exists(claveRedis("1710034065")) {
+		t.Fatalf("no se guardó la clave %q", claveRedis("1710034065"))
+	}
+
+	resultado, ok := c.Get("1710034065")
+	if !ok {
+		t.Fatal("la entrada debería encontrarse")
+	}
+	if resultado.Nombre != original.Nombre || resultado.Provincia != original.Provincia ||
+		len(resultado.Actividades) != 1 || resultado.Actividades[0] != original.Actividades[0] {
+		t.Errorf("Get = %+v\nse esperaba %+v", *resultado, *original)
+	}
+}
+
+func TestRedisCacheVencimiento(t *testing.T) {
+	servidor, c := redisPrueba(t)
+	c.SetJitter(0)
+
+	c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+	if ttl := servidor.TTL(claveRedis("1710034065")); ttl != time.Hour {
+		t.Errorf("TTL = %v, se esperaba exactamente 1h", ttl)
+	}
+	servidor.FastForward(59 * time.Minute)
+	if _, ok := c.Get("1710034065"); !ok {
+		t.Fatal("la entrada debería seguir vigente")
+	}
+	servidor.FastForward(time.Minute)
+	if _, ok := c.Get("1710034065"); ok {
+		t.Fatal("la entrada debería haber vencido")
+	}
+}
+
+func TestRedisCacheVariacionTTL(t *testing.T) {
+	const ttl = time.Hour
+	const variacion = 0.2
+	servidor, c := redisPrueba(t)
+	c.SetJitter(variacion)
+
+	minimo, maximo := 2*ttl, time.Duration(0)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("%010d", i)
+		c.Set(id, resultadoPrueba("JUAN"), ttl)
+		vigencia := servidor.TTL(claveRedis(id))
+		minimo, maximo = min(minimo, vigencia), max(maximo, vigencia)
+	}
+
+	if minimo < time.Duration(float64(ttl)*(1-variacion)) || maximo > time.Duration(float64(ttl)*(1+variacion)) {
+		t.Errorf("vigencias entre %v y %v, fuera de ±%.0f %% de %v", minimo, maximo, variacion*100, ttl)
+	}
+	if maximo-minimo < 5*time.Minute {
+		t.Errorf("vigencias entre %v y %v, se esperaba que variaran", minimo, maximo)
+	}
+}
+
+func TestRedisCacheEntradaInvalida(t *testing.T) {
+	servidor, c := redisPrueba(t)
+	servidor.Set(claveRedis("1710034065"), "no es json")
+
+	if _, ok := c.Get("1710034065"); ok {
+		t.Error("una entrada que no se puede interpretar debería tratarse como ausente")
+	}
+}
+
+func TestRedisCacheCaido(t *testing.T) {
+	servidor, c := redisPrueba(t)
+	c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+	servidor.Close()
+
+	// Sin Redis las operaciones fallan a tiempo y se tratan como ausencia del resultado
+	inicio := time.Now()
+	if _, ok := c.Get("1710034065"); ok {
+		t.Error("con Redis caído no debería encontrarse nada")
+	}
+	c.Set("0912345675", resultadoPrueba("ANA"), time.Hour)
+	if demora := time.Since(inicio); demora > 4*timeoutRedis {
+		t.Errorf("las operaciones tardaron %v con Redis caído", demora)
+	}
+
+	// Al volver Redis la caché se recupera sola
+	if err := servidor.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+	if _, ok := c.Get("1710034065"); !ok {
+		t.Error("la caché debería volver a funcionar cuando Redis responde")
+	}
+}
@@ -0,0 +1,44 @@
+package cedula
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// SetRefreshAhead activa el refresco anticipado de la caché: un acierto cuya antigüedad ya entró
+// en la fracción final indicada de la vigencia (entre 0 y 1) devuelve el resultado guardado al
+// instante y lo vuelve a consultar en segundo plano, para que las entradas que se siguen usando
+// no venzan y provoquen una consulta lenta. 0 lo desactiva. Se puede llamar mientras hay
+// consultas en curso.
+func (c *Client) SetRefreshAhead(fraccion float64) {
+	c.refrescoAnticipado.Store(math.Float64bits(limitarVariacion(fraccion)))
+}
+
+func (c *Client) refreshAhead() float64 {
+	return math.Float64frombits(c.refrescoAnticipado.Load())
+}
+
+// refrescarAntesDeVencer vuelve a consultar en segundo plano la identificación si el resultado
+// que salió de la caché está por vencer. La consulta se agrupa con las demás de la misma
+// identificación, así que los aciertos simultáneos disparan un solo refresco, y no se interrumpe
+// si se cancela la petición que lo disparó.
+func (c *Client) refrescarAntesDeVencer(ctx context.Context, id string, resultado *Result) {
+	fraccion := c.refreshAhead()
+	if fraccion <= 0 || resultado.ConsultadoEn.IsZero() {
+		return
+	}
+	ttl := c.cacheTTLPara(id, resultado)
+	antiguedad := time.Since(resultado.ConsultadoEn)
+	if antiguedad < time.Duration(float64(ttl)*(1-fraccion)) {
+		return
+	}
+
+	LoggerFrom(ctx).Debug("Refrescando en segundo plano una entrada de la caché por vencer",
+		"cedula", Redact(id), "antiguedad", antiguedad.String(), "ttl", ttl.String())
+	fondo := context.WithoutCancel(ctx)
+	// El canal de DoChan tiene espacio para el resultado, así que nadie necesita leerlo
+	c.enVuelo.DoChan(id, func() (interface{}, error) {
+		return c.consultar(fondo, id)
+	})
+}
@@ -0,0 +1,127 @@
+package cedula
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clienteConEntrada arma un Client con refresco anticipado contra el SRI indicado y con la
+// caché ya cargada con un resultado consultado hace antiguedad (vigencia de una hora)
+func clienteConEntrada(url string, fraccion float64, antiguedad time.Duration) (*Client, *MemoryCache) {
+	cache := NewCache(10)
+	cliente := &Client{Hosts: NewHosts(url), Cache: cache}
+	cliente.SetCacheTTL(time.Hour)
+	cliente.SetRefreshAhead(fraccion)
+
+	guardado := resultadoPrueba("GUARDADO")
+	guardado.ConsultadoEn = time.Now().Add(-antiguedad).UTC()
+	cache.Set("1710034065", guardado, time.Hour)
+	return cliente, cache
+}
+
+// esperarRefresco espera a que la caché tenga un resultado consultado después de desde
+func esperarRefresco(t *testing.T, cache *MemoryCache, desde time.Time) *Result {
+	t.Helper()
+	limite := time.Now().Add(2 * time.Second)
+	for time.Now().Before(limite) {
+		if resultado, ok := cache.Get("1710034065"); ok && resultado.ConsultadoEn.After(desde) {
+			return resultado
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("la entrada de la caché no se refrescó en segundo plano")
+	return nil
+}
+
+func TestRefrescoAnticipadoDevuelveLaCacheYRefrescaEnSegundoPlano(t *testing.T) {
+	liberar := make(chan struct{})
+	servidor, peticiones := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		<-liberar
+		return false
+	})
+	cliente, cache := clienteConEntrada(servidor.URL, 0.1, 55*time.Minute)
+
+	// El acierto responde al instante con el dato guardado aunque el SRI todavía no responda
+	inicio := time.Now()
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Nombre != "GUARDADO" {
+		t.Errorf("nombre = %q, se esperaba el de la caché", resultado.Nombre)
+	}
+	if espera := time.Since(inicio); espera > 500*time.Millisecond {
+		t.Errorf("el acierto tardó %v esperando al refresco", espera)
+	}
+
+	close(liberar)
+	refrescado := esperarRefresco(t, cache, inicio.Add(-time.Second))
+	if refrescado.Nombres != "JUAN CARLOS" {
+		t.Errorf("nombres refrescados = %q, se esperaban los del SRI", refrescado.Nombres)
+	}
+	if peticiones.Load() != 1 {
+		t.Errorf("peticiones al SRI = %d, se esperaba 1", peticiones.Load())
+	}
+}
+
+func TestRefrescoAnticipadoNoSeDisparaSinNecesidad(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		fraccion   float64
+		antiguedad time.Duration
+	}{
+		{"entrada reciente", 0.1, 10 * time.Minute},
+		{"justo antes del umbral", 0.1, 50 * time.Minute},
+		{"desactivado", 0, 59 * time.Minute},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			servidor, peticiones := servidorSRI(t, nil)
+			cliente, _ := clienteConEntrada(servidor.URL, caso.fraccion, caso.antiguedad)
+
+			resultado, err := cliente.Lookup(context.Background(), "1710034065")
+			if err != nil || resultado.Nombre != "GUARDADO" {
+				t.Fatalf("Lookup = %+v, %v; se esperaba el resultado de la caché", resultado, err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if peticiones.Load() != 0 {
+				t.Errorf("peticiones al SRI = %d, no se esperaba un refresco", peticiones.Load())
+			}
+		})
+	}
+}
+
+func TestRefrescoAnticipadoUnoPorEntrada(t *testing.T) {
+	liberar := make(chan struct{})
+	servidor, peticiones := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		<-liberar
+		return false
+	})
+	cliente, cache := clienteConEntrada(servidor.URL, 0.2, 50*time.Minute)
+
+	// Los aciertos simultáneos de una entrada por vencer comparten un solo refresco, aunque la
+	// petición que lo disparó se cancele
+	ctx, cancelar := context.WithCancel(context.Background())
+	var grupo sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		grupo.Add(1)
+		go func() {
+			defer grupo.Done()
+			if _, err := cliente.Lookup(ctx, "1710034065"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	grupo.Wait()
+	cancelar()
+
+	inicio := time.Now()
+	close(liberar)
+	esperarRefresco(t, cache, inicio.Add(-time.Second))
+	if peticiones.Load() != 1 {
+		t.Errorf("peticiones al SRI = %d, se esperaba 1", peticiones.Load())
+	}
+}
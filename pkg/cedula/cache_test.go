@@ -0,0 +1,337 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// relojFijo devuelve un reloj que se puede adelantar desde la prueba
+func relojFijo() (func() time.Time, func(time.Duration)) {
+	ahora := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time { return ahora }, func(d time.Duration) { ahora = ahora.Add(d) }
+}
+
+// resultadoPrueba arma un resultado con el nombre indicado
+func resultadoPrueba(nombre string) *Result {
+	return &Result{Nombre: nombre, Apellido: "PEREZ", Nombres: nombre, Apellidos: "PEREZ", Fuente: SourceSRI, Provincia: "Pichincha"}
+}
+
+// constructoresMemoria son las dos variantes de MemoryCache, que deben comportarse igual
+var constructoresMemoria = map[string]func(int) *MemoryCache{
+	"normal":   NewCache,
+	"compacta": NewCompactCache,
+}
+
+func TestMemoryCacheAciertoYFallo(t *testing.T) {
+	for nombre, nueva := range constructoresMemoria {
+		t.Run(nombre, func(t *testing.T) {
+			c := nueva(10)
+
+			if _, ok := c.Get("1710034065"); ok {
+				t.Fatal("la caché vacía no debería encontrar nada")
+			}
+			c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+			resultado, ok := c.Get("1710034065")
+			if !ok || resultado.Nombre != "JUAN" || resultado.Provincia != "Pichincha" {
+				t.Fatalf("Get = %+v, %t", resultado, ok)
+			}
+
+			// El llamador recibe una copia que puede modificar
+			resultado.Nombre = "OTRO"
+			if resultado, _ := c.Get("1710034065"); resultado.Nombre != "JUAN" {
+				t.Errorf("la caché se modificó a través de la copia: %q", resultado.Nombre)
+			}
+
+			if aciertos, fallos := c.Stats(); aciertos != 2 || fallos != 1 {
+				t.Errorf("Stats = %d aciertos, %d fallos; se esperaba 2 y 1", aciertos, fallos)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheVencimiento(t *testing.T) {
+	for nombre, nueva := range constructoresMemoria {
+		t.Run(nombre, func(t *testing.T) {
+			c := nueva(10)
+			c.SetJitter(0)
+			ahora, adelantar := relojFijo()
+			c.ahora = ahora
+
+			c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+			adelantar(59 * time.Minute)
+			if _, ok := c.Get("1710034065"); !ok {
+				t.Fatal("la entrada debería seguir vigente")
+			}
+			adelantar(time.Minute)
+			if _, ok := c.Get("1710034065"); ok {
+				t.Fatal("la entrada debería haber vencido")
+			}
+			if c.Len() != 0 {
+				t.Errorf("Len = %d, la entrada vencida debería descartarse", c.Len())
+			}
+		})
+	}
+}
+
+func TestMemoryCacheDescartaLaMenosUsada(t *testing.T) {
+	for nombre, nueva := range constructoresMemoria {
+		t.Run(nombre, func(t *testing.T) {
+			c := nueva(2)
+
+			c.Set("a", resultadoPrueba("A"), time.Hour)
+			c.Set("b", resultadoPrueba("B"), time.Hour)
+			c.Get("a") // "b" pasa a ser la menos usada
+			c.Set("c", resultadoPrueba("C"), time.Hour)
+
+			if _, ok := c.Get("b"); ok {
+				t.Error("se esperaba que se descartara la entrada menos usada")
+			}
+			for _, id := range []string{"a", "c"} {
+				if _, ok := c.Get(id); !ok {
+					t.Errorf("la entrada %q debería seguir en la caché", id)
+				}
+			}
+			if c.Len() != 2 {
+				t.Errorf("Len = %d, se esperaba 2", c.Len())
+			}
+		})
+	}
+}
+
+func TestMemoryCacheVariacionTTL(t *testing.T) {
+	const ttl = time.Hour
+	const variacion = 0.2
+	c := NewCache(1000)
+	c.SetJitter(variacion)
+	ahora, _ := relojFijo()
+	c.ahora = ahora
+
+	minimo, maximo := 2*ttl, time.Duration(0)
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("%010d", i)
+		c.Set(id, resultadoPrueba("JUAN"), ttl)
+		vigencia := c.entradas[id].Value.(*entradaCache).vence.Sub(ahora())
+		minimo, maximo = min(minimo, vigencia), max(maximo, vigencia)
+	}
+
+	if minimo < time.Duration(float64(ttl)*(1-variacion)) || maximo > time.Duration(float64(ttl)*(1+variacion)) {
+		t.Errorf("vigencias entre %v y %v, fuera de ±%.0f %% de %v", minimo, maximo, variacion*100, ttl)
+	}
+	// Con 200 entradas la variación debería cubrir buena parte de la banda de ±12 minutos
+	if maximo-minimo < 10*time.Minute {
+		t.Errorf("vigencias entre %v y %v, se esperaba que variaran", minimo, maximo)
+	}
+}
+
+func TestMemoryCacheSinVariacion(t *testing.T) {
+	c := NewCache(10)
+	c.SetJitter(0)
+	ahora, _ := relojFijo()
+	c.ahora = ahora
+
+	c.Set("1710034065", resultadoPrueba("JUAN"), time.Hour)
+	if vigencia := c.entradas["1710034065"].Value.(*entradaCache).vence.Sub(ahora()); vigencia != time.Hour {
+		t.Errorf("vigencia = %v, se esperaba exactamente 1h", vigencia)
+	}
+}
+
+// cacheFalsa es una Cache en memoria que registra las llamadas que recibe
+type cacheFalsa struct {
+	mu         sync.Mutex
+	resultados map[string]*Result
+	consultas  []string
+	vigencias  map[string]time.Duration
+}
+
+func nuevaCacheFalsa() *cacheFalsa {
+	return &cacheFalsa{resultados: make(map[string]*Result), vigencias: make(map[string]time.Duration)}
+}
+
+func (c *cacheFalsa) Get(id string) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consultas = append(c.consultas, id)
+	resultado, ok := c.resultados[id]
+	if !ok {
+		return nil, false
+	}
+	copia := *resultado
+	return &copia, true
+}
+
+func (c *cacheFalsa) Set(id string, resultado *Result, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copia := *resultado
+	c.resultados[id] = &copia
+	c.vigencias[id] = ttl
+}
+
+func TestLookupConsultaLaCache(t *testing.T) {
+	servidor, peticiones := servidorSRI(t, nil)
+	cache := nuevaCacheFalsa()
+	cache.resultados["1710034065"] = resultadoPrueba("DESDE LA CACHE")
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Nombre != "DESDE LA CACHE" {
+		t.Errorf("Nombre = %q, se esperaba el resultado guardado", resultado.Nombre)
+	}
+	if len(cache.consultas) != 1 || cache.consultas[0] != "1710034065" {
+		t.Errorf("consultas a la caché = %v", cache.consultas)
+	}
+	if peticiones.Load() != 0 {
+		t.Errorf("peticiones = %d, un acierto de la caché no debería llamar al SRI", peticiones.Load())
+	}
+}
+
+func TestLookupGuardaConLaVigenciaDelCliente(t *testing.T) {
+	servidor, peticiones := servidorSRI(t, nil)
+	cache := nuevaCacheFalsa()
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+
+	casos := []struct {
+		id       string
+		ttl      time.Duration
+		vigencia time.Duration
+	}{
+		{"1710034065", 0, DefaultCacheTTL},
+		{"0912345675", 10 * time.Minute, 10 * time.Minute},
+		{"0102030400", -time.Minute, DefaultCacheTTL},
+	}
+	for _, caso := range casos {
+		cliente.SetCacheTTL(caso.ttl)
+		if _, err := cliente.Lookup(context.Background(), caso.id); err != nil {
+			t.Fatalf("%s: %v", caso.id, err)
+		}
+		if _, ok := cache.resultados[caso.id]; !ok {
+			t.Fatalf("%s: el resultado no se guardó en la caché", caso.id)
+		}
+		if vigencia := cache.vigencias[caso.id]; vigencia != caso.vigencia {
+			t.Errorf("%s: vigencia = %v, se esperaba %v", caso.id, vigencia, caso.vigencia)
+		}
+	}
+
+	// La segunda consulta sale de la caché
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); err != nil {
+		t.Fatal(err)
+	}
+	if peticiones.Load() != int32(len(casos)) {
+		t.Errorf("peticiones = %d, se esperaba %d", peticiones.Load(), len(casos))
+	}
+}
+
+// sriConDeudas responde con deudas pendientes para las identificaciones de conDeudas y sin
+// deudas para las demás
+func sriConDeudas(conDeudas ...string) func(w http.ResponseWriter, r *http.Request) bool {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		deuda := ""
+		for _, id := range conDeudas {
+			if strings.Contains(r.URL.Path, id) {
+				deuda = `,"deuda":{"valor":25.5}`
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS"}` + deuda + `}`))
+		return true
+	}
+}
+
+func TestLookupGuardaConLaVigenciaDeSuTipo(t *testing.T) {
+	servidor, _ := servidorSRI(t, sriConDeudas("0912345675", "1790000001001"))
+	cache := nuevaCacheFalsa()
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+	cliente.SetCacheTTL(time.Hour)
+	cliente.SetDebtCacheTTL(5 * time.Minute)
+	cliente.SetRUCCacheTTL(24 * time.Hour)
+
+	casos := []struct {
+		nombre, id string
+		vigencia   time.Duration
+	}{
+		{"cédula sin deudas", "1710034065", time.Hour},
+		{"cédula con deudas", "0912345675", 5 * time.Minute},
+		{"RUC sin deudas", "1710034065001", 24 * time.Hour},
+		{"RUC con deudas", "1790000001001", 5 * time.Minute},
+	}
+	for _, caso := range casos {
+		if _, err := cliente.Lookup(context.Background(), caso.id); err != nil {
+			t.Fatalf("%s: %v", caso.nombre, err)
+		}
+		if vigencia := cache.vigencias[caso.id]; vigencia != caso.vigencia {
+			t.Errorf("%s: vigencia = %v, se esperaba %v", caso.nombre, vigencia, caso.vigencia)
+		}
+	}
+
+	// Sin vigencias propias todos los tipos usan la general
+	cliente.SetDebtCacheTTL(0)
+	cliente.SetRUCCacheTTL(0)
+	for _, caso := range casos {
+		delete(cache.resultados, caso.id)
+		if _, err := cliente.Lookup(context.Background(), caso.id); err != nil {
+			t.Fatalf("%s: %v", caso.nombre, err)
+		}
+		if vigencia := cache.vigencias[caso.id]; vigencia != time.Hour {
+			t.Errorf("%s sin vigencia propia: vigencia = %v, se esperaba %v", caso.nombre, vigencia, time.Hour)
+		}
+	}
+}
+
+func TestLasDeudasVencenAntesQueLosNombres(t *testing.T) {
+	servidor, peticiones := servidorSRI(t, sriConDeudas("0912345675"))
+	cache := NewCache(10)
+	cache.SetJitter(0)
+	ahora, adelantar := relojFijo()
+	cache.ahora = ahora
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+	cliente.SetCacheTTL(time.Hour)
+	cliente.SetDebtCacheTTL(5 * time.Minute)
+
+	for _, id := range []string{"1710034065", "0912345675"} {
+		if _, err := cliente.Lookup(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Pasada la vigencia de las deudas solo vence la entrada con deudas
+	adelantar(6 * time.Minute)
+	if _, ok := cache.Get("0912345675"); ok {
+		t.Error("la entrada con deudas sigue en la caché después de su vigencia")
+	}
+	if _, ok := cache.Get("1710034065"); !ok {
+		t.Error("la entrada sin deudas venció con la vigencia de las deudas")
+	}
+
+	// La nueva consulta de la entrada con deudas vuelve a llamar al SRI
+	if _, err := cliente.Lookup(context.Background(), "0912345675"); err != nil {
+		t.Fatal(err)
+	}
+	if peticiones.Load() != 3 {
+		t.Errorf("peticiones = %d, se esperaban 3", peticiones.Load())
+	}
+}
+
+func TestLookupNoGuardaErrores(t *testing.T) {
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusNotFound)
+		return true
+	})
+	cache := nuevaCacheFalsa()
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, se esperaba ErrNotFound", err)
+	}
+	if len(cache.resultados) != 0 {
+		t.Errorf("la caché guardó %d resultados; las identificaciones no encontradas no se guardan", len(cache.resultados))
+	}
+}
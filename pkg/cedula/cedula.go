@@ -0,0 +1,372 @@
+// Package cedula consulta los datos de cédulas y RUC ecuatorianos en la API pública del SRI.
+//
+// Puede usarse sin levantar el servidor web:
+//
+//	resultado, err := cedula.Lookup(ctx, "1712345678")
+package cedula
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Result representa los datos de una cédula o RUC encontrados en el SRI
+type Result struct {
+	XMLName xml.Name `json:"-" xml:"cedulaResponse"`
+	// Nombre y Apellido se mantienen por compatibilidad; tienen el mismo valor que Nombres y Apellidos
+	Nombre   string `json:"nombre" xml:"nombre"`
+	Apellido string `json:"apellido" xml:"apellido"`
+	// Nombres y Apellidos se separan según el orden "APELLIDOS NOMBRES" del SRI, respetando
+	// los apellidos compuestos ("DE LA TORRE"); las razones sociales quedan completas en Nombres
+	Nombres   string `json:"nombres" xml:"nombres"`
+	Apellidos string `json:"apellidos" xml:"apellidos"`
+	// PrimerNombre, SegundoNombre, PrimerApellido y SegundoApellido son los componentes del nombre;
+	// quedan vacíos si no existen (y siempre en las razones sociales)
+	PrimerNombre    string     `json:"primerNombre" xml:"primerNombre"`
+	SegundoNombre   string     `json:"segundoNombre" xml:"segundoNombre"`
+	PrimerApellido  string     `json:"primerApellido" xml:"primerApellido"`
+	SegundoApellido string     `json:"segundoApellido" xml:"segundoApellido"`
+	Actividades     []Activity `json:"actividades,omitempty" xml:"actividades>actividad,omitempty"`
+	// NombresAnteriores solo se incluye con Client.Detailed; es una lista vacía si la fuente no la reporta
+	NombresAnteriores *[]string `json:"nombresAnteriores,omitempty" xml:"nombresAnteriores>nombre,omitempty"`
+	// NombreFormateado no lo llena Lookup; queda para que el llamador agregue el formato que necesite
+	NombreFormateado string `json:"nombreFormateado,omitempty" xml:"nombreFormateado,omitempty"`
+	// FechaInicioActividades solo se incluye con Client.Detailed (RFC3339, o el valor original si no se reconoce)
+	FechaInicioActividades string `json:"fechaInicioActividades,omitempty" xml:"fechaInicioActividades,omitempty"`
+	// Provincia es la provincia de emisión según los dos primeros dígitos de la identificación
+	Provincia string `json:"provincia,omitempty" xml:"provincia,omitempty"`
+	// Fuente indica qué fuente produjo los datos: SourceSRI o SourceFallback
+	Fuente string `json:"fuente" xml:"fuente"`
+	// DeRespaldo indica que los datos no vienen del SRI sino de la fuente de respaldo, que puede
+	// ser menos confiable; los clientes pueden marcarlos o volver a verificarlos
+	DeRespaldo bool `json:"fromFallback" xml:"fromFallback"`
+	// DigitoVerificadorValido indica si el dígito verificador de la identificación consultada es
+	// correcto; el SRI resuelve algunas identificaciones antiguas que no lo cumplen
+	DigitoVerificadorValido bool `json:"checkDigitValid" xml:"checkDigitValid"`
+	// TieneDeudas indica si el SRI reporta deudas pendientes para la identificación
+	TieneDeudas bool `json:"tieneDeudas" xml:"tieneDeudas"`
+	// MontoTotal es el valor total adeudado; se omite si el SRI no lo informa
+	MontoTotal float64 `json:"montoTotal,omitempty" xml:"montoTotal,omitempty"`
+	// ConsultadoEn es cuándo se obtuvieron los datos de la fuente (en UTC); en los resultados que
+	// salen de la caché indica su antigüedad
+	ConsultadoEn time.Time `json:"retrievedAt" xml:"retrievedAt"`
+}
+
+// Activity representa una actividad económica (código CIIU) registrada en el SRI
+type Activity struct {
+	Ciiu        string `json:"ciiu" xml:"ciiu"`
+	Descripcion string `json:"descripcion,omitempty" xml:"descripcion,omitempty"`
+}
+
+// NameResult representa la cédula encontrada en una consulta por nombres
+type NameResult struct {
+	XMLName   xml.Name `json:"-" xml:"nombresResponse"`
+	Cedula    string   `json:"cedula" xml:"cedula"`
+	Nombres   string   `json:"nombres" xml:"nombres"`
+	Apellidos string   `json:"apellidos" xml:"apellidos"`
+}
+
+// ErrNotFound se devuelve cuando el SRI no tiene datos para la identificación consultada
+var ErrNotFound = errors.New("cédula no encontrada")
+
+// ErrUpstream envuelve los errores del SRI: fallos de red, respuestas 5xx en todos los hosts o
+// respuestas que no se pueden interpretar
+var ErrUpstream = errors.New("error del SRI")
+
+// DefaultTimeout es el timeout de las peticiones al SRI cuando el Client no tiene un HTTPClient propio
+const DefaultTimeout = 30 * time.Second
+
+// Valores por defecto de los Client que no configuran HTTPClient o Hosts
+var (
+	clienteHTTPPorDefecto = &http.Client{Timeout: DefaultTimeout}
+	hostsPorDefecto       = NewHosts("")
+)
+
+// Client consulta la API del SRI. El valor cero es utilizable.
+type Client struct {
+	// HTTPClient realiza las peticiones; si es nil se usa uno con DefaultTimeout
+	HTTPClient *http.Client
+	// Hosts reparte las consultas entre las URLs base del SRI; si es nil se usa DefaultBaseURL
+	Hosts *Hosts
+	// Cache guarda los resultados exitosos para no repetir la llamada al SRI, con la vigencia de
+	// SetCacheTTL o la propia de su tipo (SetDebtCacheTTL, SetRUCCacheTTL); si es nil no se guarda nada
+	Cache Cache
+	// FallbackURL, si no está vacía, es la fuente de respaldo que se consulta cuando el SRI no
+	// devuelve el nombre (ver consultarRespaldo)
+	FallbackURL string
+	// UserAgents son los User-Agent entre los que se rota en las peticiones al SRI; si está vacío
+	// se usa DefaultUserAgents
+	UserAgents []string
+	// Headers reemplaza o agrega cabeceras a las que se envían al SRI (ver aplicarCabeceras)
+	Headers http.Header
+	// Detailed agrega las actividades económicas, los nombres anteriores y la fecha de inicio de actividades
+	Detailed bool
+	// BeforeCall, si no es nil, se invoca antes de cada llamada al SRI. Si devuelve un error la consulta
+	// se cancela con ese error; si no, release se invoca al terminar la llamada.
+	BeforeCall func(ctx context.Context) (release func(), err error)
+	// AfterCall, si no es nil, se invoca después de cada llamada al SRI con su duración y su error
+	AfterCall func(base string, duracion time.Duration, err error)
+	// Breaker, si no es nil, corta las consultas con ErrUnavailable mientras el SRI está fallando
+	Breaker *Breaker
+
+	// enVuelo agrupa las consultas simultáneas de una misma identificación en una sola llamada
+	enVuelo singleflight.Group
+	// ttlCache es la vigencia de los resultados que se guardan en Cache; 0 usa DefaultCacheTTL
+	ttlCache atomic.Int64
+	// ttlDeudas y ttlRUC son las vigencias propias de esos tipos de resultado; 0 usa ttlCache
+	ttlDeudas, ttlRUC atomic.Int64
+	// refrescoAnticipado es la fracción final de la vigencia en la que un acierto de la caché
+	// actualiza la entrada en segundo plano (ver SetRefreshAhead), guardada con math.Float64bits
+	refrescoAnticipado atomic.Uint64
+}
+
+// DefaultClient es el Client que usan Lookup y Plan
+var DefaultClient = &Client{}
+
+// Lookup consulta una cédula o RUC con DefaultClient
+func Lookup(ctx context.Context, id string) (*Result, error) {
+	return DefaultClient.Lookup(ctx, id)
+}
+
+// Plan devuelve las peticiones que haría Lookup con DefaultClient
+func Plan(ctx context.Context, id string) ([]*http.Request, error) {
+	return DefaultClient.Plan(ctx, id)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return clienteHTTPPorDefecto
+}
+
+func (c *Client) cache() Cache {
+	if c.Cache != nil {
+		return c.Cache
+	}
+	return sinCache{}
+}
+
+// SetCacheTTL cambia la vigencia de los resultados que se guarden en Cache a partir de ahora; un
+// ttl no positivo vuelve a DefaultCacheTTL. Es la vigencia de las cédulas sin deudas y la de los
+// tipos sin vigencia propia (ver SetDebtCacheTTL y SetRUCCacheTTL). Se puede llamar mientras hay
+// consultas en curso.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.ttlCache.Store(int64(max(ttl, 0)))
+}
+
+// SetDebtCacheTTL cambia la vigencia de los resultados con deudas pendientes, que cambian más
+// seguido que los nombres (al pagarse); un ttl no positivo vuelve a la de SetCacheTTL
+func (c *Client) SetDebtCacheTTL(ttl time.Duration) {
+	c.ttlDeudas.Store(int64(max(ttl, 0)))
+}
+
+// SetRUCCacheTTL cambia la vigencia de los resultados de RUC; un ttl no positivo vuelve a la de
+// SetCacheTTL
+func (c *Client) SetRUCCacheTTL(ttl time.Duration) {
+	c.ttlRUC.Store(int64(max(ttl, 0)))
+}
+
+func (c *Client) cacheTTL() time.Duration {
+	if ttl := time.Duration(c.ttlCache.Load()); ttl > 0 {
+		return ttl
+	}
+	return DefaultCacheTTL
+}
+
+// cacheTTLPara devuelve la vigencia del resultado de la identificación según su tipo: la de RUC
+// para los RUC y la de deudas para los que tienen deudas pendientes. Si ambas aplican se usa la
+// más corta, para que el dato más volátil no quede vencido en la caché.
+func (c *Client) cacheTTLPara(id string, resultado *Result) time.Duration {
+	ttl := c.cacheTTL()
+	if ruc := time.Duration(c.ttlRUC.Load()); ruc > 0 && len(id) == 13 {
+		ttl = ruc
+	}
+	if deudas := time.Duration(c.ttlDeudas.Load()); deudas > 0 && (resultado.TieneDeudas || resultado.MontoTotal > 0) {
+		ttl = min(ttl, deudas)
+	}
+	return ttl
+}
+
+func (c *Client) hosts() *Hosts {
+	if c.Hosts != nil {
+		return c.Hosts
+	}
+	return hostsPorDefecto
+}
+
+// Lookup consulta en el SRI los datos de una cédula o RUC ya normalizados y validados.
+// Se intenta cada URL base en orden, pasando a la siguiente si una falla; si el SRI no
+// tiene datos para la identificación se devuelve ErrNotFound. La cancelación o el vencimiento
+// del contexto interrumpen la petición en curso y se devuelven como ctx.Err(). Con el circuito
+// de Breaker abierto se devuelve ErrUnavailable sin llamar al SRI.
+//
+// Las consultas simultáneas de una misma identificación comparten una sola llamada al SRI y
+// reciben cada una su propia copia del resultado (o el mismo error). Los resultados de la caché
+// más viejos que la antigüedad de WithMaxAge se ignoran.
+func (c *Client) Lookup(ctx context.Context, id string) (*Result, error) {
+	if resultado, ok := c.cache().Get(id); ok {
+		if aceptaAntiguedad(ctx, resultado) {
+			c.refrescarAntesDeVencer(ctx, id, resultado)
+			return resultado, nil
+		}
+		LoggerFrom(ctx).Debug("Entrada de la caché más vieja que la aceptada por el cliente", "cedula", Redact(id))
+	}
+
+	canal := c.enVuelo.DoChan(id, func() (interface{}, error) {
+		return c.consultar(ctx, id)
+	})
+	select {
+	case compartida := <-canal:
+		if compartida.Err != nil {
+			// Si la llamada compartida se interrumpió por el contexto de otro llamador, este la
+			// repite con el suyo
+			if compartida.Shared && ctx.Err() == nil &&
+				(errors.Is(compartida.Err, context.Canceled) || errors.Is(compartida.Err, context.DeadlineExceeded)) {
+				return c.consultar(ctx, id)
+			}
+			return nil, compartida.Err
+		}
+		copia := *compartida.Val.(*Result)
+		return &copia, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// consultar realiza la consulta de Lookup sin pasar por la caché ni agrupar llamadas
+func (c *Client) consultar(ctx context.Context, id string) (*Result, error) {
+	registro := LoggerFrom(ctx).With("cedula", Redact(id))
+	if c.Breaker != nil {
+		if err := c.Breaker.permitir(registro); err != nil {
+			return nil, err
+		}
+	}
+
+	body, statusCode, err := c.consultarHosts(ctx, id)
+	if c.Breaker != nil {
+		// Las cancelaciones y los rechazos de BeforeCall no dicen nada sobre la salud del SRI
+		if err == nil || errors.Is(err, ErrUpstream) {
+			c.Breaker.registrar(err != nil, registro)
+		} else {
+			c.Breaker.liberar()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	registro.Debug("Respuesta del SRI", "estado", statusCode, "cuerpo", truncarCuerpo(body))
+
+	// Verificar el código de estado HTTP
+	var resultado *Result
+	err = ErrNotFound
+	if statusCode == http.StatusOK {
+		resultado, err = parsearRespuesta(body, id, c.Detailed, registro)
+	} else {
+		registro.Info("El SRI no tiene datos para la identificación", "estado", statusCode)
+	}
+
+	// Antes de dar la identificación por no encontrada se intenta la fuente de respaldo
+	if errors.Is(err, ErrNotFound) && c.FallbackURL != "" {
+		resultado, err = c.consultarRespaldo(ctx, id, registro)
+	}
+	if err == nil {
+		resultado.Provincia, _ = provinciaDeCedula(id)
+		resultado.DigitoVerificadorValido = CheckDigitValid(id)
+		resultado.DeRespaldo = resultado.Fuente == SourceFallback
+		resultado.ConsultadoEn = time.Now().UTC()
+	}
+	if err == nil {
+		c.cache().Set(id, resultado, c.cacheTTLPara(id, resultado))
+	}
+	return resultado, err
+}
+
+// consultarHosts intenta cada URL base en orden hasta que una responda y devuelve su cuerpo y
+// su código de estado. Si todas fallan devuelve el último error envuelto en ErrUpstream.
+func (c *Client) consultarHosts(ctx context.Context, id string) ([]byte, int, error) {
+	hosts := c.hosts()
+
+	var body []byte
+	var statusCode int
+	var ultimoError error
+	for _, base := range hosts.Order() {
+		liberar := func() {}
+		if c.BeforeCall != nil {
+			release, err := c.BeforeCall(ctx)
+			if err != nil {
+				return nil, 0, err
+			}
+			liberar = release
+		}
+
+		inicio := time.Now()
+		body, statusCode, ultimoError = c.consultarHost(ctx, base, id)
+		liberar()
+		if c.AfterCall != nil {
+			c.AfterCall(base, time.Since(inicio), ultimoError)
+		}
+		if ultimoError == nil {
+			hosts.MarkSuccess(base)
+			break
+		}
+		// Si el llamador canceló la consulta no se culpa al host ni se intentan los demás
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		hosts.MarkFailure(base)
+	}
+	if ultimoError != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrUpstream, ultimoError)
+	}
+	return body, statusCode, nil
+}
+
+// Plan devuelve, sin ejecutarlas, las peticiones que Lookup haría para la identificación
+// en el orden en que se intentarían las URLs base
+func (c *Client) Plan(ctx context.Context, id string) ([]*http.Request, error) {
+	var peticiones []*http.Request
+	for _, base := range c.hosts().Order() {
+		req, err := c.construirPeticion(ctx, base, id)
+		if err != nil {
+			return nil, err
+		}
+		peticiones = append(peticiones, req)
+	}
+	return peticiones, nil
+}
+
+// Ping comprueba con una petición HEAD que alguna URL base del SRI responda. Cualquier
+// respuesta cuenta como alcanzable salvo 502, 503 y 504, que indican que el SRI o el proxy
+// delante de él no están disponibles; no se consume ninguna consulta.
+func (c *Client) Ping(ctx context.Context) error {
+	var ultimoError error
+	for _, base := range c.hosts().Order() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, base, nil)
+		if err != nil {
+			return err
+		}
+		c.aplicarCabeceras(req)
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			ultimoError = err
+			continue
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return nil
+		}
+		ultimoError = fmt.Errorf("error del servidor del SRI: código %d", resp.StatusCode)
+	}
+	return ultimoError
+}
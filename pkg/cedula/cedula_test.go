@@ -0,0 +1,131 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// respuestaSRIFalsa es el cuerpo que devuelven los servidores de prueba del SRI
+const respuestaSRIFalsa = `{"contribuyente":{"identificacion":"1710034065","denominacion":"PEREZ LOPEZ JUAN CARLOS","clase":"OTROS"}}`
+
+// servidorSRI levanta un servidor de prueba que responde como el SRI y cuenta las peticiones
+// que recibe. Si manejador no es nil se ejecuta antes de responder; si escribe la respuesta
+// debe devolver true.
+func servidorSRI(t *testing.T, manejador func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var peticiones atomic.Int32
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peticiones.Add(1)
+		if manejador != nil && manejador(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(respuestaSRIFalsa))
+	}))
+	t.Cleanup(servidor.Close)
+	return servidor, &peticiones
+}
+
+func TestLookupDigitoVerificadorValido(t *testing.T) {
+	servidor, _ := servidorSRI(t, nil)
+	cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+	// El SRI resuelve también identificaciones antiguas con el dígito verificador incorrecto
+	for id, valido := range map[string]bool{"1710034065": true, "1710034064": false} {
+		resultado, err := cliente.Lookup(context.Background(), id)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if resultado.DigitoVerificadorValido != valido {
+			t.Errorf("%s: checkDigitValid = %t, se esperaba %t", id, resultado.DigitoVerificadorValido, valido)
+		}
+	}
+}
+
+func TestLookupAgrupaConsultasSimultaneas(t *testing.T) {
+	const consultas = 20
+	liberar := make(chan struct{})
+	servidor, peticiones := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		<-liberar
+		return false
+	})
+	cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+	var grupo sync.WaitGroup
+	resultados := make([]*Result, consultas)
+	errores := make([]error, consultas)
+	for i := 0; i < consultas; i++ {
+		grupo.Add(1)
+		go func(i int) {
+			defer grupo.Done()
+			resultados[i], errores[i] = cliente.Lookup(context.Background(), "1710034065")
+		}(i)
+	}
+	// Se da tiempo a que todas las consultas se sumen a la llamada en curso antes de responder
+	time.Sleep(100 * time.Millisecond)
+	close(liberar)
+	grupo.Wait()
+
+	if peticiones.Load() != 1 {
+		t.Errorf("peticiones al SRI = %d, se esperaba 1", peticiones.Load())
+	}
+	for i := range resultados {
+		if errores[i] != nil || resultados[i].Apellidos != "PEREZ LOPEZ" {
+			t.Fatalf("consulta %d: %+v, %v", i, resultados[i], errores[i])
+		}
+	}
+	// Cada llamador recibe su propia copia
+	resultados[0].Nombre = "OTRO"
+	if resultados[1].Nombre == "OTRO" {
+		t.Error("las consultas agrupadas comparten el mismo resultado")
+	}
+}
+
+func TestLookupReintentaSiOtroLlamadorCancela(t *testing.T) {
+	liberar := make(chan struct{})
+	var llamadas atomic.Int32
+	servidor, peticiones := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if llamadas.Add(1) == 1 {
+			// La primera petición espera hasta que su llamador la cancele
+			select {
+			case <-r.Context().Done():
+			case <-liberar:
+			}
+			return true
+		}
+		return false
+	})
+	defer close(liberar)
+	cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	primero := make(chan error, 1)
+	go func() {
+		_, err := cliente.Lookup(ctx, "1710034065")
+		primero <- err
+	}()
+	for peticiones.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	segundo := make(chan error, 1)
+	go func() {
+		_, err := cliente.Lookup(context.Background(), "1710034065")
+		segundo <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancelar()
+
+	if err := <-primero; !errors.Is(err, context.Canceled) {
+		t.Errorf("primer llamador: err = %v, se esperaba context.Canceled", err)
+	}
+	if err := <-segundo; err != nil {
+		t.Errorf("el segundo llamador debería repetir la consulta con su contexto: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package cedula
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable se devuelve sin llamar al SRI mientras el circuito está abierto
+var ErrUnavailable = errors.New("el SRI no está disponible")
+
+// Valores por defecto de NewBreaker
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// Estados del circuito
+const (
+	circuitoCerrado     = "cerrado"
+	circuitoAbierto     = "abierto"
+	circuitoSemiabierto = "semiabierto"
+)
+
+// Breaker es un circuit breaker alrededor de las llamadas al SRI. Se abre después de umbral
+// consultas fallidas seguidas y, mientras está abierto, las consultas fallan de inmediato con
+// ErrUnavailable en lugar de esperar el timeout. Pasado el enfriamiento deja pasar una consulta
+// de prueba (semiabierto): si funciona el circuito se cierra y si no vuelve a abrirse.
+type Breaker struct {
+	umbral       int
+	enfriamiento time.Duration
+	ahora        func() time.Time
+
+	mu        sync.Mutex
+	estado    string
+	fallos    int
+	abiertoEn time.Time
+	// enPrueba indica que ya hay una consulta de prueba en curso en el estado semiabierto
+	enPrueba bool
+}
+
+// NewBreaker crea un circuito cerrado que se abre tras umbral fallos seguidos y espera
+// enfriamiento antes de volver a intentar
+func NewBreaker(umbral int, enfriamiento time.Duration) *Breaker {
+	return &Breaker{umbral: umbral, enfriamiento: enfriamiento, ahora: time.Now, estado: circuitoCerrado}
+}
+
+// permitir indica si la consulta puede llamar al SRI. Con el circuito abierto devuelve
+// ErrUnavailable hasta que pase el enfriamiento; después deja pasar una sola consulta de prueba.
+func (b *Breaker) permitir(registro *slog.Logger) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.estado {
+	case circuitoAbierto:
+		if b.ahora().Sub(b.abiertoEn) < b.enfriamiento {
+			return ErrUnavailable
+		}
+		b.cambiarEstado(circuitoSemiabierto, registro)
+		b.enPrueba = true
+		return nil
+	case circuitoSemiabierto:
+		if b.enPrueba {
+			return ErrUnavailable
+		}
+		b.enPrueba = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// registrar anota el resultado de una consulta que permitir dejó pasar. Solo cuentan como
+// fallos los errores del SRI, no las identificaciones inexistentes ni las cancelaciones.
+func (b *Breaker) registrar(fallo bool, registro *slog.Logger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.enPrueba = false
+	if !fallo {
+		b.fallos = 0
+		if b.estado != circuitoCerrado {
+			b.cambiarEstado(circuitoCerrado, registro)
+		}
+		return
+	}
+
+	b.fallos++
+	if b.estado == circuitoSemiabierto || b.fallos >= b.umbral {
+		b.abiertoEn = b.ahora()
+		if b.estado != circuitoAbierto {
+			b.cambiarEstado(circuitoAbierto, registro)
+		}
+	}
+}
+
+// liberar descarta la consulta de prueba sin anotar un resultado (por ejemplo, si el llamador la canceló)
+func (b *Breaker) liberar() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enPrueba = false
+}
+
+// cambiarEstado registra la transición en los logs; se invoca con mu tomado
+func (b *Breaker) cambiarEstado(estado string, registro *slog.Logger) {
+	anterior := b.estado
+	b.estado = estado
+	if estado == circuitoAbierto {
+		registro.Warn("Circuito del SRI abierto; las consultas fallarán de inmediato", "anterior", anterior, "fallos", b.fallos, "enfriamiento", b.enfriamiento.String())
+	} else {
+		registro.Info("Cambio de estado del circuito del SRI", "anterior", anterior, "estado", estado)
+	}
+}
@@ -0,0 +1,113 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerAbreSemiabreYCierra(t *testing.T) {
+	var caido atomic.Bool
+	caido.Store(true)
+	servidor, peticiones := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if caido.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	})
+	circuito := NewBreaker(2, 30*time.Second)
+	ahora, adelantar := relojFijo()
+	circuito.ahora = ahora
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Breaker: circuito}
+	consultar := func() error {
+		_, err := cliente.Lookup(context.Background(), "1710034065")
+		return err
+	}
+
+	// Dos fallos seguidos abren el circuito
+	for i := 0; i < 2; i++ {
+		if err := consultar(); !errors.Is(err, ErrUpstream) {
+			t.Fatalf("consulta %d: err = %v, se esperaba ErrUpstream", i, err)
+		}
+	}
+	if circuito.estado != circuitoAbierto {
+		t.Fatalf("estado = %s, se esperaba abierto", circuito.estado)
+	}
+
+	// Abierto: falla de inmediato sin llamar al SRI
+	if err := consultar(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, se esperaba ErrUnavailable", err)
+	}
+	if peticiones.Load() != 2 {
+		t.Errorf("peticiones = %d, el circuito abierto no debería llamar al SRI", peticiones.Load())
+	}
+
+	// Pasado el enfriamiento deja pasar una prueba; si falla vuelve a abrirse
+	adelantar(30 * time.Second)
+	if err := consultar(); !errors.Is(err, ErrUpstream) {
+		t.Fatalf("prueba fallida: err = %v, se esperaba ErrUpstream", err)
+	}
+	if circuito.estado != circuitoAbierto {
+		t.Fatalf("estado = %s, la prueba fallida debería reabrir el circuito", circuito.estado)
+	}
+	if err := consultar(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, se esperaba ErrUnavailable tras reabrir", err)
+	}
+
+	// Con el SRI recuperado, la prueba exitosa cierra el circuito
+	caido.Store(false)
+	adelantar(30 * time.Second)
+	if err := consultar(); err != nil {
+		t.Fatalf("prueba exitosa: %v", err)
+	}
+	if circuito.estado != circuitoCerrado || circuito.fallos != 0 {
+		t.Errorf("estado = %s con %d fallos, se esperaba cerrado sin fallos", circuito.estado, circuito.fallos)
+	}
+	if err := consultar(); err != nil {
+		t.Errorf("con el circuito cerrado: %v", err)
+	}
+}
+
+func TestBreakerSemiabiertoDejaPasarUnaSolaPrueba(t *testing.T) {
+	circuito := NewBreaker(1, time.Second)
+	ahora, adelantar := relojFijo()
+	circuito.ahora = ahora
+	registro := LoggerFrom(context.Background())
+
+	circuito.registrar(true, registro)
+	adelantar(time.Second)
+	if err := circuito.permitir(registro); err != nil {
+		t.Fatalf("la primera prueba debería pasar: %v", err)
+	}
+	if err := circuito.permitir(registro); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, solo debería pasar una prueba a la vez", err)
+	}
+
+	// Si la prueba se cancela, otra consulta puede hacerla
+	circuito.liberar()
+	if err := circuito.permitir(registro); err != nil {
+		t.Errorf("tras liberar la prueba debería pasar otra: %v", err)
+	}
+}
+
+func TestBreakerNoCuentaNoEncontradas(t *testing.T) {
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusNotFound)
+		return true
+	})
+	circuito := NewBreaker(1, time.Minute)
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Breaker: circuito}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cliente.Lookup(context.Background(), "1710034065"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("err = %v, se esperaba ErrNotFound", err)
+		}
+	}
+	if circuito.estado != circuitoCerrado {
+		t.Errorf("estado = %s, las identificaciones inexistentes no deberían abrir el circuito", circuito.estado)
+	}
+}
@@ -0,0 +1,56 @@
+package cedula
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// encodingsAceptados se anuncia en Accept-Encoding. Al fijarlo explícitamente el transporte de
+// Go deja de descomprimir por su cuenta, así que leerCuerpo se encarga de hacerlo.
+const encodingsAceptados = "gzip, deflate"
+
+// leerCuerpo lee el cuerpo de una respuesta y lo descomprime según su Content-Encoding (gzip o deflate)
+func leerCuerpo(resp *http.Response) ([]byte, error) {
+	var lector io.Reader = resp.Body
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("cuerpo gzip inválido: %v", err)
+		}
+		defer gz.Close()
+		lector = gz
+	case "deflate":
+		// "deflate" suele enviarse con el envoltorio zlib, pero algunos servidores mandan deflate puro
+		buffer := bufio.NewReader(resp.Body)
+		if cabecera, err := buffer.Peek(2); err == nil && esCabeceraZlib(cabecera) {
+			zr, err := zlib.NewReader(buffer)
+			if err != nil {
+				return nil, fmt.Errorf("cuerpo deflate inválido: %v", err)
+			}
+			defer zr.Close()
+			lector = zr
+		} else {
+			fr := flate.NewReader(buffer)
+			defer fr.Close()
+			lector = fr
+		}
+	default:
+		return nil, fmt.Errorf("Content-Encoding no soportado: %s", resp.Header.Get("Content-Encoding"))
+	}
+
+	return io.ReadAll(lector)
+}
+
+// esCabeceraZlib indica si los dos primeros bytes corresponden a una cabecera zlib válida
+func esCabeceraZlib(cabecera []byte) bool {
+	return cabecera[0]&0x0f == 8 && (uint16(cabecera[0])<<8|uint16(cabecera[1]))%31 == 0
+}
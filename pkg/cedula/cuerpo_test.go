@@ -0,0 +1,78 @@
+package cedula
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// comprimir codifica cuerpo con el Content-Encoding indicado
+func comprimir(t *testing.T, encoding string, cuerpo []byte) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	var escritor io.WriteCloser
+	switch encoding {
+	case "gzip":
+		escritor = gzip.NewWriter(&buffer)
+	case "deflate":
+		escritor = zlib.NewWriter(&buffer)
+	case "deflate-puro":
+		escritor, _ = flate.NewWriter(&buffer, flate.DefaultCompression)
+	default:
+		return cuerpo
+	}
+	escritor.Write(cuerpo)
+	escritor.Close()
+	return buffer.Bytes()
+}
+
+func TestLookupCuerpoComprimido(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate", "deflate-puro", ""} {
+		t.Run(encoding, func(t *testing.T) {
+			var acceptEncoding string
+			servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				if encoding != "" {
+					cabecera := encoding
+					if encoding == "deflate-puro" {
+						cabecera = "deflate"
+					}
+					w.Header().Set("Content-Encoding", cabecera)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(comprimir(t, encoding, []byte(respuestaSRIFalsa)))
+				return true
+			})
+			cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+			resultado, err := cliente.Lookup(context.Background(), "1710034065")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resultado.Nombres != "JUAN CARLOS" || resultado.Apellidos != "PEREZ LOPEZ" {
+				t.Errorf("resultado = %+v", resultado)
+			}
+			if acceptEncoding != encodingsAceptados {
+				t.Errorf("Accept-Encoding = %q, se esperaba %q", acceptEncoding, encodingsAceptados)
+			}
+		})
+	}
+}
+
+func TestLookupEncodingNoSoportado(t *testing.T) {
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte(respuestaSRIFalsa))
+		return true
+	})
+	cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); err == nil {
+		t.Error("se esperaba un error con Content-Encoding br")
+	}
+}
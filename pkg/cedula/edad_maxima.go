@@ -0,0 +1,27 @@
+package cedula
+
+import (
+	"context"
+	"time"
+)
+
+// claveEdadMaxima es la clave de la antigüedad máxima aceptada en el contexto
+type claveEdadMaxima struct{}
+
+// WithMaxAge devuelve una copia del contexto con la antigüedad máxima que el llamador acepta en
+// los resultados de la caché: las consultas hechas con él ignoran las entradas más viejas y
+// vuelven a consultar la fuente, actualizando la caché. Con 0 siempre se consulta la fuente.
+func WithMaxAge(ctx context.Context, edad time.Duration) context.Context {
+	return context.WithValue(ctx, claveEdadMaxima{}, max(edad, 0))
+}
+
+// aceptaAntiguedad indica si el resultado de la caché es lo bastante reciente para la antigüedad
+// máxima del contexto. Sin antigüedad máxima se acepta cualquier entrada vigente; con ella, las
+// entradas sin fecha de consulta se descartan porque no se sabe cuán viejas son.
+func aceptaAntiguedad(ctx context.Context, resultado *Result) bool {
+	edad, ok := ctx.Value(claveEdadMaxima{}).(time.Duration)
+	if !ok {
+		return true
+	}
+	return !resultado.ConsultadoEn.IsZero() && time.Since(resultado.ConsultadoEn) <= edad
+}
@@ -0,0 +1,53 @@
+package cedula
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLookupRespetaLaAntiguedadMaxima(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		antiguedad time.Duration
+		sinFecha   bool
+		ctx        func(context.Context) context.Context
+		refresca   bool
+	}{
+		{"sin antigüedad máxima", 50 * time.Minute, false, func(ctx context.Context) context.Context { return ctx }, false},
+		{"entrada más reciente que max-age", 2 * time.Minute, false, func(ctx context.Context) context.Context { return WithMaxAge(ctx, 5*time.Minute) }, false},
+		{"entrada más vieja que max-age", 10 * time.Minute, false, func(ctx context.Context) context.Context { return WithMaxAge(ctx, 5*time.Minute) }, true},
+		{"max-age cero", time.Second, false, func(ctx context.Context) context.Context { return WithMaxAge(ctx, 0) }, true},
+		{"entrada sin fecha de consulta", 0, true, func(ctx context.Context) context.Context { return WithMaxAge(ctx, time.Hour) }, true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			servidor, peticiones := servidorSRI(t, nil)
+			cache := NewCache(10)
+			cliente := &Client{Hosts: NewHosts(servidor.URL), Cache: cache}
+			guardado := resultadoPrueba("GUARDADO")
+			if !caso.sinFecha {
+				guardado.ConsultadoEn = time.Now().Add(-caso.antiguedad).UTC()
+			}
+			cache.Set("1710034065", guardado, time.Hour)
+
+			resultado, err := cliente.Lookup(caso.ctx(context.Background()), "1710034065")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if caso.refresca {
+				if peticiones.Load() != 1 || resultado.Nombres != "JUAN CARLOS" {
+					t.Errorf("peticiones = %d, nombres = %q; se esperaba una consulta nueva al SRI", peticiones.Load(), resultado.Nombres)
+				}
+				// La consulta nueva reemplaza la entrada vieja de la caché
+				if actual, _ := cache.Get("1710034065"); actual == nil || actual.Nombres != "JUAN CARLOS" {
+					t.Errorf("caché = %+v, se esperaba el resultado nuevo", actual)
+				}
+				return
+			}
+			if peticiones.Load() != 0 || resultado.Nombre != "GUARDADO" {
+				t.Errorf("peticiones = %d, nombre = %q; se esperaba el resultado de la caché", peticiones.Load(), resultado.Nombre)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cedula
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL es la URL base de la API del SRI cuando no se configuran otras
+const DefaultBaseURL = "https://srienlinea.sri.gob.ec/movil-servicios/api/v1.0"
+
+// enfriamientoHost es el tiempo durante el cual un host que falló se considera no saludable
+const enfriamientoHost = 30 * time.Second
+
+// host guarda el estado de salud de una URL base del SRI
+type host struct {
+	base        string
+	fallos      int
+	ultimoFallo time.Time
+}
+
+// Hosts reparte las consultas entre varias URLs base del SRI (espejos o proxies)
+// en round-robin, dejando al final los hosts que fallaron recientemente
+type Hosts struct {
+	mu        sync.Mutex
+	hosts     []*host
+	siguiente int
+}
+
+// NewHosts crea un balanceador a partir de una lista de URLs base separadas por comas.
+// Si la lista está vacía se usa DefaultBaseURL.
+func NewHosts(urls string) *Hosts {
+	b := &Hosts{}
+	for _, base := range strings.Split(urls, ",") {
+		base = strings.TrimRight(strings.TrimSpace(base), "/")
+		if base != "" {
+			b.hosts = append(b.hosts, &host{base: base})
+		}
+	}
+	if len(b.hosts) == 0 {
+		b.hosts = append(b.hosts, &host{base: DefaultBaseURL})
+	}
+	return b
+}
+
+// Order devuelve las URLs base en el orden en que deben intentarse para una consulta.
+// Cada llamada avanza el round-robin; los hosts en enfriamiento se mueven al final.
+func (b *Hosts) Order() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	inicio := b.siguiente
+	b.siguiente = (b.siguiente + 1) % len(b.hosts)
+
+	ahora := time.Now()
+	var saludables, enEnfriamiento []string
+	for i := range b.hosts {
+		h := b.hosts[(inicio+i)%len(b.hosts)]
+		if h.fallos > 0 && ahora.Sub(h.ultimoFallo) < enfriamientoHost {
+			enEnfriamiento = append(enEnfriamiento, h.base)
+		} else {
+			saludables = append(saludables, h.base)
+		}
+	}
+	return append(saludables, enEnfriamiento...)
+}
+
+// MarkFailure marca un host como fallido para que se intente después de los demás
+func (b *Hosts) MarkFailure(base string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range b.hosts {
+		if h.base == base {
+			h.fallos++
+			h.ultimoFallo = time.Now()
+		}
+	}
+}
+
+// MarkSuccess restablece el estado de salud de un host
+func (b *Hosts) MarkSuccess(base string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range b.hosts {
+		if h.base == base {
+			h.fallos = 0
+		}
+	}
+}
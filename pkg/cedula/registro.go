@@ -0,0 +1,41 @@
+package cedula
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// longitudMaximaCuerpoLog es la cantidad de caracteres de la respuesta del SRI que se registran en debug
+const longitudMaximaCuerpoLog = 500
+
+// claveRegistro es la clave del logger en el contexto
+type claveRegistro struct{}
+
+// WithLogger devuelve una copia del contexto con el logger que usarán las consultas hechas
+// con él, por ejemplo uno con el ID de la petición HTTP que las originó
+func WithLogger(ctx context.Context, registro *slog.Logger) context.Context {
+	return context.WithValue(ctx, claveRegistro{}, registro)
+}
+
+// LoggerFrom devuelve el logger guardado con WithLogger, o slog.Default() si no hay uno
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if registro, ok := ctx.Value(claveRegistro{}).(*slog.Logger); ok {
+		return registro
+	}
+	return slog.Default()
+}
+
+// Redact oculta una identificación para los logs: solo conserva los dos primeros dígitos
+// (la provincia) y los dos últimos
+func Redact(id string) string {
+	if len(id) <= 4 {
+		return strings.Repeat("*", len(id))
+	}
+	return id[:2] + strings.Repeat("*", len(id)-4) + id[len(id)-2:]
+}
+
+// truncarCuerpo recorta la respuesta del SRI a longitudMaximaCuerpoLog caracteres para los logs
+func truncarCuerpo(body []byte) string {
+	return string(body[:min(longitudMaximaCuerpoLog, len(body))])
+}
@@ -0,0 +1,85 @@
+package cedula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Valores de Result.Fuente
+const (
+	// SourceSRI indica que los datos vienen de la API pública del SRI
+	SourceSRI = "SRI"
+	// SourceFallback indica que los datos vienen de la fuente de respaldo (Client.FallbackURL)
+	SourceFallback = "respaldo"
+)
+
+// respuestaRespaldo es la respuesta JSON esperada de la fuente de respaldo. El nombre viene
+// completo, en el mismo orden que en el SRI ("APELLIDOS NOMBRES").
+type respuestaRespaldo struct {
+	NombreCompleto string `json:"nombreCompleto"`
+}
+
+// consultarRespaldo consulta la identificación en la fuente de respaldo con una petición GET a
+// FallbackURL con el parámetro cedula. Un 404 o un nombre vacío se informan como ErrNotFound;
+// los fallos de la fuente también, porque el SRI ya respondió que no tiene los datos.
+func (c *Client) consultarRespaldo(ctx context.Context, id string, registro *slog.Logger) (*Result, error) {
+	direccion, err := url.Parse(c.FallbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de la fuente de respaldo inválida: %v", err)
+	}
+	parametros := direccion.Query()
+	parametros.Set("cedula", id)
+	direccion.RawQuery = parametros.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, direccion.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear la petición de respaldo: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", encodingsAceptados)
+
+	registro.Debug("Consultando la fuente de respaldo", "host", direccion.Host)
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// Se descarta la URL, que incluye la identificación sin ocultar
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		registro.Warn("Fallo de la fuente de respaldo", "error", err)
+		return nil, ErrNotFound
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		registro.Info("La fuente de respaldo no tiene datos para la identificación", "estado", resp.StatusCode)
+		return nil, ErrNotFound
+	}
+
+	body, err := leerCuerpo(resp)
+	if err != nil {
+		registro.Warn("Fallo de la fuente de respaldo", "error", err)
+		return nil, ErrNotFound
+	}
+	var datos respuestaRespaldo
+	if err := json.Unmarshal(body, &datos); err != nil {
+		registro.Warn("Respuesta inválida de la fuente de respaldo", "error", err)
+		return nil, ErrNotFound
+	}
+	nombreCompleto := strings.TrimSpace(datos.NombreCompleto)
+	if nombreCompleto == "" {
+		return nil, ErrNotFound
+	}
+
+	registro.Info("Datos encontrados en la fuente de respaldo")
+	return nuevoResultado(nombreCompleto, PersonType(id), SourceFallback), nil
+}
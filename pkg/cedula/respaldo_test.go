@@ -0,0 +1,146 @@
+package cedula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// servidorRespaldo levanta una fuente de respaldo de prueba que responde con estado y cuerpo y
+// cuenta las peticiones; la última cédula consultada se guarda en consultada
+func servidorRespaldo(t *testing.T, estado int, cuerpo string) (url string, peticiones *atomic.Int32, consultada *atomic.Value) {
+	t.Helper()
+	peticiones, consultada = &atomic.Int32{}, &atomic.Value{}
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peticiones.Add(1)
+		consultada.Store(r.URL.Query().Get("cedula"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(estado)
+		w.Write([]byte(cuerpo))
+	}))
+	t.Cleanup(servidor.Close)
+	return servidor.URL + "/buscar?origen=prueba", peticiones, consultada
+}
+
+// sriSinDatos responde 404 a todas las consultas
+func sriSinDatos(w http.ResponseWriter, r *http.Request) bool {
+	w.WriteHeader(http.StatusNotFound)
+	return true
+}
+
+func TestLookupUsaElRespaldoSiElSRINoTieneDatos(t *testing.T) {
+	sri, _ := servidorSRI(t, sriSinDatos)
+	respaldo, peticiones, consultada := servidorRespaldo(t, http.StatusOK, `{"nombreCompleto":" GARCIA MORA ANA LUCIA "}`)
+	cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: respaldo}
+
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Fuente != SourceFallback {
+		t.Errorf("fuente = %q, se esperaba %q", resultado.Fuente, SourceFallback)
+	}
+	if resultado.Nombres != "ANA LUCIA" || resultado.Apellidos != "GARCIA MORA" {
+		t.Errorf("nombres = %q, apellidos = %q", resultado.Nombres, resultado.Apellidos)
+	}
+	if !resultado.DeRespaldo {
+		t.Error("fromFallback = false, se esperaba true para los datos de la fuente de respaldo")
+	}
+	if resultado.Provincia == "" || !resultado.DigitoVerificadorValido {
+		t.Errorf("el resultado del respaldo debe completarse como el del SRI: %+v", resultado)
+	}
+	if peticiones.Load() != 1 || consultada.Load() != "1710034065" {
+		t.Errorf("peticiones al respaldo = %d, cédula = %v", peticiones.Load(), consultada.Load())
+	}
+}
+
+func TestLookupUsaElRespaldoSiElSRINoTraeElNombre(t *testing.T) {
+	sri, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.Write([]byte(`{"contribuyente":{"denominacion":""}}`))
+		return true
+	})
+	respaldo, _, _ := servidorRespaldo(t, http.StatusOK, `{"nombreCompleto":"GARCIA MORA ANA LUCIA"}`)
+	cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: respaldo}
+
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Fuente != SourceFallback || !resultado.DeRespaldo {
+		t.Errorf("fuente = %q, fromFallback = %v; se esperaba %q y true", resultado.Fuente, resultado.DeRespaldo, SourceFallback)
+	}
+}
+
+func TestLookupNoUsaElRespaldoSiElSRITieneDatos(t *testing.T) {
+	sri, _ := servidorSRI(t, nil)
+	respaldo, peticiones, _ := servidorRespaldo(t, http.StatusOK, `{"nombreCompleto":"GARCIA MORA ANA LUCIA"}`)
+	cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: respaldo}
+
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Fuente != SourceSRI || resultado.DeRespaldo {
+		t.Errorf("fuente = %q, fromFallback = %v; se esperaba %q y false", resultado.Fuente, resultado.DeRespaldo, SourceSRI)
+	}
+	if peticiones.Load() != 0 {
+		t.Errorf("peticiones al respaldo = %d, se esperaba 0", peticiones.Load())
+	}
+}
+
+func TestLookupRespaldoSinDatos(t *testing.T) {
+	casos := []struct {
+		nombre string
+		estado int
+		cuerpo string
+	}{
+		{"404", http.StatusNotFound, `{}`},
+		{"error del respaldo", http.StatusInternalServerError, `{}`},
+		{"nombre vacío", http.StatusOK, `{"nombreCompleto":"  "}`},
+		{"JSON inválido", http.StatusOK, `<html>`},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			sri, _ := servidorSRI(t, sriSinDatos)
+			respaldo, _, _ := servidorRespaldo(t, caso.estado, caso.cuerpo)
+			cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: respaldo}
+
+			if _, err := cliente.Lookup(context.Background(), "1710034065"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("error = %v, se esperaba ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestLookupRespaldoCaido(t *testing.T) {
+	sri, _ := servidorSRI(t, sriSinDatos)
+	caido := httptest.NewServer(http.NotFoundHandler())
+	caido.Close()
+	cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: caido.URL}
+
+	if _, err := cliente.Lookup(context.Background(), "1710034065"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, se esperaba ErrNotFound", err)
+	}
+}
+
+func TestLookupRespaldoDesdeLaCacheConservaLaMarca(t *testing.T) {
+	sri, _ := servidorSRI(t, sriSinDatos)
+	respaldo, peticiones, _ := servidorRespaldo(t, http.StatusOK, `{"nombreCompleto":"GARCIA MORA ANA LUCIA"}`)
+	cliente := &Client{Hosts: NewHosts(sri.URL), FallbackURL: respaldo, Cache: NewCompactCache(10)}
+
+	for i := 0; i < 2; i++ {
+		resultado, err := cliente.Lookup(context.Background(), "1710034065")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resultado.DeRespaldo {
+			t.Errorf("consulta %d: fromFallback = false, se esperaba true", i+1)
+		}
+	}
+	if peticiones.Load() != 1 {
+		t.Errorf("peticiones al respaldo = %d, se esperaba 1 (la segunda sale de la caché)", peticiones.Load())
+	}
+}
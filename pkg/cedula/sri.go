@@ -0,0 +1,254 @@
+package cedula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// construirPeticion arma la petición HTTP a la API del SRI para una cédula o RUC, sin ejecutarla
+func (c *Client) construirPeticion(ctx context.Context, base, id string) (*http.Request, error) {
+	// Construir la URL de la API del SRI
+	timestamp := time.Now().UnixMilli()
+	urlSRI := fmt.Sprintf("%s/deudas/porIdentificacion/%s/?tipoPersona=%s&_=%d", base, id, PersonType(id), timestamp)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", urlSRI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configurar headers para simular un navegador real
+	c.aplicarCabeceras(req)
+
+	return req, nil
+}
+
+// consultarHost realiza la petición a una URL base del SRI y devuelve el cuerpo y el código
+// de estado. Los errores de red y las respuestas 5xx se reportan como error para poder
+// pasar al siguiente host.
+func (c *Client) consultarHost(ctx context.Context, base, id string) ([]byte, int, error) {
+	// Crear petición HTTP hacia la API del SRI
+	req, err := c.construirPeticion(ctx, base, id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al crear la petición: %v", err)
+	}
+
+	LoggerFrom(ctx).Debug("Consultando API del SRI", "host", base)
+
+	// Realizar la petición
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		// Se descarta la URL (que incluye un timestamp) para que errores idénticos se puedan agrupar
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, 0, fmt.Errorf("error al realizar la petición: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, resp.StatusCode, fmt.Errorf("error del servidor del SRI: código %d", resp.StatusCode)
+	}
+
+	// Leer la respuesta, descomprimiéndola si viene con gzip o deflate
+	body, err := leerCuerpo(resp)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al leer la respuesta: %v", err)
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// respuestaSRI es la estructura de la respuesta JSON del SRI
+type respuestaSRI struct {
+	Contribuyente struct {
+		Identificacion  string `json:"identificacion"`
+		Denominacion    string `json:"denominacion"`
+		NombreComercial string `json:"nombreComercial"`
+		Clase           string `json:"clase"`
+		// Actividad principal y lista de actividades registradas (pueden no venir)
+		ActividadEconomica    *Activity  `json:"actividadEconomica"`
+		ActividadesEconomicas []Activity `json:"actividadesEconomicas"`
+		// Nombres registrados anteriormente (por matrimonio o cambio legal), si la fuente los expone
+		NombresAnteriores []string `json:"nombresAnteriores"`
+		// Fecha de inicio de actividades; puede venir como texto o como milisegundos desde epoch
+		FechaInicioActividades json.RawMessage `json:"fechaInicioActividades"`
+	} `json:"contribuyente"`
+	// Deuda pendiente del contribuyente; viene null o no viene si no tiene deudas
+	Deuda *deudaSRI `json:"deuda"`
+}
+
+// deudaSRI es el bloque de deuda de la respuesta del SRI
+type deudaSRI struct {
+	// Valor adeudado; puede venir como número o como texto
+	Valor json.RawMessage `json:"valor"`
+}
+
+// parsearRespuesta interpreta el cuerpo de una respuesta 200 del SRI para la identificación consultada
+func parsearRespuesta(body []byte, id string, detallada bool, registro *slog.Logger) (*Result, error) {
+	var sriData respuestaSRI
+	if err := json.Unmarshal(body, &sriData); err != nil {
+		registro.Error("Error al parsear la respuesta del SRI", "error", err)
+		return nil, fmt.Errorf("%w: error al procesar la respuesta del servidor", ErrUpstream)
+	}
+
+	// Verificar que se encontraron datos
+	nombreCompleto := ""
+	if sriData.Contribuyente.Denominacion != "" {
+		nombreCompleto = sriData.Contribuyente.Denominacion
+	} else if sriData.Contribuyente.NombreComercial != "" {
+		nombreCompleto = sriData.Contribuyente.NombreComercial
+	}
+
+	if nombreCompleto == "" {
+		registro.Info("No se encontró información del nombre en la respuesta")
+		return nil, ErrNotFound
+	}
+
+	registro.Info("Datos encontrados", "clase", sriData.Contribuyente.Clase)
+
+	nombreCompleto = strings.TrimSpace(nombreCompleto)
+	respuesta := nuevoResultado(nombreCompleto, PersonType(id), SourceSRI)
+
+	if sriData.Deuda != nil {
+		respuesta.TieneDeudas = true
+		respuesta.MontoTotal = montoSRI(sriData.Deuda.Valor)
+	}
+
+	if detallada {
+		respuesta.Actividades = combinarActividades(sriData.Contribuyente.ActividadEconomica, sriData.Contribuyente.ActividadesEconomicas)
+		nombresAnteriores := limpiarNombresAnteriores(sriData.Contribuyente.NombresAnteriores, nombreCompleto)
+		respuesta.NombresAnteriores = &nombresAnteriores
+		respuesta.FechaInicioActividades = formatearFechaSRI(sriData.Contribuyente.FechaInicioActividades)
+	}
+
+	return respuesta, nil
+}
+
+// separarNombre divide el nombre completo del SRI en nombre y apellido según el tipo de persona.
+// El SRI publica las personas naturales como "APELLIDOS NOMBRES".
+func separarNombre(nombreCompleto, tipoPersona string) (nombre, apellido string) {
+	if tipoPersona == LegalEntity {
+		// Las razones sociales no se separan en nombre y apellido
+		return nombreCompleto, ""
+	}
+	apellido, nombre = separarApellidosNombres(nombreCompleto)
+	return nombre, apellido
+}
+
+// nuevoResultado arma el resultado con los campos de nombre de una fuente a partir del nombre
+// completo en el orden "APELLIDOS NOMBRES"
+func nuevoResultado(nombreCompleto, tipoPersona, fuente string) *Result {
+	nombre, apellido := separarNombre(nombreCompleto, tipoPersona)
+	resultado := &Result{
+		Nombre:    nombre,
+		Apellido:  apellido,
+		Nombres:   nombre,
+		Apellidos: apellido,
+		Fuente:    fuente,
+	}
+	if tipoPersona != LegalEntity {
+		resultado.PrimerApellido, resultado.SegundoApellido, resultado.PrimerNombre, resultado.SegundoNombre = componentesNombre(nombreCompleto)
+	}
+	return resultado
+}
+
+// formatosFechaSRI son los formatos de fecha en texto que se reconocen en las respuestas del SRI
+var formatosFechaSRI = []string{time.RFC3339, "2006-01-02", "02/01/2006", "2006-01-02 15:04:05"}
+
+// formatearFechaSRI convierte una fecha del SRI (texto o milisegundos desde epoch) a RFC3339 en la
+// hora de Ecuador. Si el formato no se reconoce se devuelve el texto original; si no viene, una
+// cadena vacía.
+func formatearFechaSRI(valor json.RawMessage) string {
+	if len(valor) == 0 || string(valor) == "null" {
+		return ""
+	}
+
+	var milisegundos int64
+	if err := json.Unmarshal(valor, &milisegundos); err == nil {
+		return time.UnixMilli(milisegundos).In(zonaEcuador).Format(time.RFC3339)
+	}
+
+	var texto string
+	if err := json.Unmarshal(valor, &texto); err != nil {
+		return ""
+	}
+	texto = strings.TrimSpace(texto)
+	for _, formato := range formatosFechaSRI {
+		if fecha, err := time.ParseInLocation(formato, texto, zonaEcuador); err == nil {
+			return fecha.In(zonaEcuador).Format(time.RFC3339)
+		}
+	}
+	return texto
+}
+
+// montoSRI convierte un valor monetario del SRI (número o texto, con punto o coma decimal) a
+// float64. Si no viene o no se puede interpretar se devuelve 0.
+func montoSRI(valor json.RawMessage) float64 {
+	var monto float64
+	if err := json.Unmarshal(valor, &monto); err == nil {
+		return monto
+	}
+
+	var texto string
+	if err := json.Unmarshal(valor, &texto); err != nil {
+		return 0
+	}
+	monto, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(texto), ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return monto
+}
+
+// zonaEcuador es la zona horaria continental de Ecuador (UTC-5, sin horario de verano)
+var zonaEcuador = time.FixedZone("ECT", -5*60*60)
+
+// limpiarNombresAnteriores normaliza la lista de nombres anteriores, descartando vacíos,
+// repetidos y el nombre actual
+func limpiarNombresAnteriores(nombres []string, nombreActual string) []string {
+	vistos := map[string]bool{strings.ToUpper(nombreActual): true}
+	limpios := []string{}
+	for _, nombre := range nombres {
+		nombre = strings.Join(strings.Fields(nombre), " ")
+		clave := strings.ToUpper(nombre)
+		if nombre == "" || vistos[clave] {
+			continue
+		}
+		vistos[clave] = true
+		limpios = append(limpios, nombre)
+	}
+	return limpios
+}
+
+// combinarActividades une la actividad principal con la lista de actividades,
+// eliminando códigos CIIU vacíos o repetidos y conservando el orden de aparición
+func combinarActividades(principal *Activity, lista []Activity) []Activity {
+	candidatas := lista
+	if principal != nil {
+		candidatas = append([]Activity{*principal}, lista...)
+	}
+
+	vistos := make(map[string]bool)
+	actividades := []Activity{}
+	for _, actividad := range candidatas {
+		codigo := strings.TrimSpace(actividad.Ciiu)
+		if codigo == "" || vistos[codigo] {
+			continue
+		}
+		vistos[codigo] = true
+		actividades = append(actividades, Activity{
+			Ciiu:        codigo,
+			Descripcion: strings.TrimSpace(actividad.Descripcion),
+		})
+	}
+	return actividades
+}
@@ -0,0 +1,193 @@
+package cedula
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// consultarCuerpoSRI consulta id con un Client detallado contra un SRI de prueba que responde
+// siempre con cuerpo
+func consultarCuerpoSRI(t *testing.T, id, cuerpo string) *Result {
+	t.Helper()
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(cuerpo))
+		return true
+	})
+	cliente := &Client{Hosts: NewHosts(servidor.URL), Detailed: true}
+	resultado, err := cliente.Lookup(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resultado
+}
+
+func TestLookupCombinaActividades(t *testing.T) {
+	cuerpo := `{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS",
+		"actividadEconomica":{"ciiu":" G4711 ","descripcion":" VENTA AL POR MENOR "},
+		"actividadesEconomicas":[
+			{"ciiu":"G4711","descripcion":"VENTA AL POR MENOR (REPETIDA)"},
+			{"ciiu":"","descripcion":"SIN CODIGO"},
+			{"ciiu":"M6920","descripcion":"CONTABILIDAD"},
+			{"ciiu":"I5610","descripcion":"RESTAURANTES"},
+			{"ciiu":"M6920","descripcion":"CONTABILIDAD"}
+		]}}`
+
+	resultado := consultarCuerpoSRI(t, "1710034065", cuerpo)
+	esperadas := []Activity{
+		{Ciiu: "G4711", Descripcion: "VENTA AL POR MENOR"},
+		{Ciiu: "M6920", Descripcion: "CONTABILIDAD"},
+		{Ciiu: "I5610", Descripcion: "RESTAURANTES"},
+	}
+	if !reflect.DeepEqual(resultado.Actividades, esperadas) {
+		t.Errorf("actividades = %+v\nse esperaba   %+v", resultado.Actividades, esperadas)
+	}
+}
+
+func TestCombinarActividades(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		principal *Activity
+		lista     []Activity
+		ciius     []string
+	}{
+		{"sin actividades", nil, nil, []string{}},
+		{"solo la principal", &Activity{Ciiu: "G4711"}, nil, []string{"G4711"}},
+		{"solo la lista", nil, []Activity{{Ciiu: "A0111"}, {Ciiu: "B0510"}}, []string{"A0111", "B0510"}},
+		{"principal repetida en la lista", &Activity{Ciiu: "A0111"}, []Activity{{Ciiu: "B0510"}, {Ciiu: "A0111"}}, []string{"A0111", "B0510"}},
+		{"principal sin código", &Activity{Ciiu: " "}, []Activity{{Ciiu: "B0510"}}, []string{"B0510"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			ciius := []string{}
+			for _, actividad := range combinarActividades(caso.principal, caso.lista) {
+				ciius = append(ciius, actividad.Ciiu)
+			}
+			if !reflect.DeepEqual(ciius, caso.ciius) {
+				t.Errorf("CIIU = %v, se esperaba %v", ciius, caso.ciius)
+			}
+		})
+	}
+}
+
+func TestLookupDeudas(t *testing.T) {
+	casos := []struct {
+		nombre      string
+		deuda       string
+		tieneDeudas bool
+		monto       float64
+	}{
+		{"sin bloque de deuda", ``, false, 0},
+		{"deuda null", `,"deuda":null`, false, 0},
+		{"monto numérico", `,"deuda":{"valor":152.75}`, true, 152.75},
+		{"monto en texto con coma decimal", `,"deuda":{"valor":"98,40"}`, true, 98.40},
+		{"monto ilegible", `,"deuda":{"valor":"pendiente"}`, true, 0},
+		{"deuda sin valor", `,"deuda":{}`, true, 0},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			cuerpo := `{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS"}` + caso.deuda + `}`
+			resultado := consultarCuerpoSRI(t, "1710034065", cuerpo)
+			if resultado.TieneDeudas != caso.tieneDeudas || resultado.MontoTotal != caso.monto {
+				t.Errorf("tieneDeudas = %v, montoTotal = %v; se esperaba %v, %v",
+					resultado.TieneDeudas, resultado.MontoTotal, caso.tieneDeudas, caso.monto)
+			}
+		})
+	}
+}
+
+func TestFormatearFechaSRI(t *testing.T) {
+	casos := []struct {
+		valor    string
+		esperado string
+	}{
+		// 1584284400000 ms son las 15:00 UTC del 15/03/2020, las 10:00 en Ecuador
+		{`1584284400000`, "2020-03-15T10:00:00-05:00"},
+		{`0`, "1969-12-31T19:00:00-05:00"},
+		{`"2020-03-15"`, "2020-03-15T00:00:00-05:00"},
+		{`"15/03/2020"`, "2020-03-15T00:00:00-05:00"},
+		{`"2020-03-15 10:30:00"`, "2020-03-15T10:30:00-05:00"},
+		{`" 2020-03-15 "`, "2020-03-15T00:00:00-05:00"},
+		{`"2020-03-15T15:00:00Z"`, "2020-03-15T10:00:00-05:00"},
+		{`"2020-03-15T10:00:00-05:00"`, "2020-03-15T10:00:00-05:00"},
+		// Formatos desconocidos se devuelven tal cual
+		{`"15 de marzo de 2020"`, "15 de marzo de 2020"},
+		{`"2020/03/15"`, "2020/03/15"},
+		{`null`, ""},
+		{``, ""},
+		{`{"fecha":1}`, ""},
+	}
+	for _, caso := range casos {
+		if got := formatearFechaSRI(json.RawMessage(caso.valor)); got != caso.esperado {
+			t.Errorf("formatearFechaSRI(%s) = %q, se esperaba %q", caso.valor, got, caso.esperado)
+		}
+	}
+}
+
+func TestLookupFechaInicioActividades(t *testing.T) {
+	cuerpo := `{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS","fechaInicioActividades":1584284400000}}`
+	resultado := consultarCuerpoSRI(t, "1710034065", cuerpo)
+	if resultado.FechaInicioActividades != "2020-03-15T10:00:00-05:00" {
+		t.Errorf("fechaInicioActividades = %q", resultado.FechaInicioActividades)
+	}
+}
+
+func TestLookupNombresAnteriores(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		anteriores string
+		esperados  []string
+		json       string
+	}{
+		{"presentes", `,"nombresAnteriores":["PEREZ JUAN", "  PEREZ   LOPEZ JUAN  ", "perez juan", "", "PEREZ LOPEZ JUAN CARLOS"]`,
+			[]string{"PEREZ JUAN", "PEREZ LOPEZ JUAN"}, `"nombresAnteriores":["PEREZ JUAN","PEREZ LOPEZ JUAN"]`},
+		{"ausentes", ``, []string{}, `"nombresAnteriores":[]`},
+		{"null", `,"nombresAnteriores":null`, []string{}, `"nombresAnteriores":[]`},
+		{"lista vacía", `,"nombresAnteriores":[]`, []string{}, `"nombresAnteriores":[]`},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			cuerpo := `{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS"` + caso.anteriores + `}}`
+			resultado := consultarCuerpoSRI(t, "1710034065", cuerpo)
+			if resultado.NombresAnteriores == nil {
+				t.Fatal("con Detailed nombresAnteriores no debe ser nil")
+			}
+			if !reflect.DeepEqual(*resultado.NombresAnteriores, caso.esperados) {
+				t.Errorf("nombresAnteriores = %q, se esperaba %q", *resultado.NombresAnteriores, caso.esperados)
+			}
+
+			// Sin nombres anteriores se publica una lista vacía, no se omite el campo
+			datos, err := json.Marshal(resultado)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(datos), caso.json) {
+				t.Errorf("JSON = %s, se esperaba %s", datos, caso.json)
+			}
+		})
+	}
+}
+
+func TestLookupSinDetalleOmiteNombresAnteriores(t *testing.T) {
+	servidor, _ := servidorSRI(t, func(w http.ResponseWriter, r *http.Request) bool {
+		w.Write([]byte(`{"contribuyente":{"denominacion":"PEREZ LOPEZ JUAN CARLOS","nombresAnteriores":["PEREZ JUAN"]}}`))
+		return true
+	})
+	cliente := &Client{Hosts: NewHosts(servidor.URL)}
+
+	resultado, err := cliente.Lookup(context.Background(), "1710034065")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.NombresAnteriores != nil {
+		t.Errorf("sin Detailed no se deben incluir los nombres anteriores: %q", *resultado.NombresAnteriores)
+	}
+	datos, _ := json.Marshal(resultado)
+	if strings.Contains(string(datos), "nombresAnteriores") {
+		t.Errorf("JSON = %s, no se esperaba nombresAnteriores", datos)
+	}
+}
@@ -0,0 +1,183 @@
+package cedula
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tipos de persona que acepta el parámetro tipoPersona de la API del SRI
+const (
+	NaturalPerson = "N"
+	LegalEntity   = "J"
+)
+
+// Normalize limpia los espacios de la cédula y acepta el formato con el dígito verificador
+// separado por guion ("171234567-8"), devolviéndola en su forma estándar de 10 dígitos.
+// Devuelve false si hay guiones en cualquier otra posición.
+func Normalize(cedula string) (string, bool) {
+	cedula = strings.TrimSpace(cedula)
+	if !strings.Contains(cedula, "-") {
+		return cedula, true
+	}
+
+	partes := strings.Split(cedula, "-")
+	if len(partes) != 2 || len(partes[0]) != 9 || len(partes[1]) != 1 {
+		return cedula, false
+	}
+	return partes[0] + partes[1], true
+}
+
+// ValidateCedula valida una cédula ecuatoriana: 10 dígitos, código de provincia válido,
+// tercer dígito de persona natural y dígito verificador (módulo 10)
+func ValidateCedula(cedula string) bool {
+	// Verificar que tenga exactamente 10 dígitos
+	if len(cedula) != 10 {
+		return false
+	}
+
+	// Verificar que todos los caracteres sean números
+	match, _ := regexp.MatchString("^[0-9]+$", cedula)
+	if !match {
+		return false
+	}
+
+	// Verificar el código de provincia (01 a 24, o 30 para ecuatorianos registrados en el exterior)
+	if _, err := provinciaDeCedula(cedula); err != nil {
+		return false
+	}
+
+	// Verificar que el tercer dígito corresponda a una persona natural (0 a 5)
+	if cedula[2]-'0' >= 6 {
+		return false
+	}
+
+	return digitoVerificadorValido(cedula)
+}
+
+// digitoVerificadorValido aplica el módulo 10 con los coeficientes 2,1,2,1,2,1,2,1,2 sobre los
+// nueve primeros dígitos y compara el resultado con el décimo
+func digitoVerificadorValido(cedula string) bool {
+	suma := 0
+	for i := 0; i < 9; i++ {
+		producto := int(cedula[i]-'0') * (2 - i%2)
+		if producto >= 10 {
+			producto -= 9
+		}
+		suma += producto
+	}
+
+	verificador := (10 - suma%10) % 10
+	return verificador == int(cedula[9]-'0')
+}
+
+// patronRUC verifica que el RUC tenga exactamente 13 dígitos
+var patronRUC = regexp.MustCompile("^[0-9]{13}$")
+
+// patronCedula verifica que la cédula tenga exactamente 10 dígitos
+var patronCedula = regexp.MustCompile("^[0-9]{10}$")
+
+// Motivos por los que CheckRUC rechaza un RUC
+var (
+	ErrRUCFormat        = errors.New("el RUC debe tener 13 dígitos")
+	ErrRUCProvince      = errors.New("el código de provincia del RUC no existe")
+	ErrRUCType          = errors.New("el tercer dígito del RUC no corresponde a ningún tipo de contribuyente")
+	ErrRUCCheckDigit    = errors.New("el dígito verificador del RUC no es válido")
+	ErrRUCEstablishment = errors.New("el código de establecimiento del RUC debe ser 001 o mayor")
+)
+
+// ValidateRUC valida un RUC ecuatoriano de 13 dígitos; ver CheckRUC para el motivo del rechazo
+func ValidateRUC(ruc string) bool {
+	return CheckRUC(ruc) == nil
+}
+
+// CheckRUC valida un RUC ecuatoriano de 13 dígitos según el tipo indicado por el tercer dígito:
+//   - 0 a 5: persona natural (cédula válida seguida del establecimiento)
+//   - 6: entidad pública (módulo 11 sobre los 8 primeros dígitos, verificador en la posición 9)
+//   - 9: sociedad privada o extranjera (módulo 11 sobre los 9 primeros dígitos, verificador en la posición 10)
+//
+// El código de establecimiento final no puede ser cero. Devuelve nil si el RUC es válido o uno
+// de los errores ErrRUC* con el motivo del rechazo.
+func CheckRUC(ruc string) error {
+	if !patronRUC.MatchString(ruc) {
+		return ErrRUCFormat
+	}
+
+	if _, err := provinciaDeCedula(ruc); err != nil {
+		return ErrRUCProvince
+	}
+
+	verificadorValido, establecimiento, ok := verificadorRUC(ruc)
+	if !ok {
+		return ErrRUCType
+	}
+
+	if !verificadorValido {
+		return ErrRUCCheckDigit
+	}
+	if !establecimientoValido(establecimiento) {
+		return ErrRUCEstablishment
+	}
+	return nil
+}
+
+// verificadorRUC revisa el dígito verificador de un RUC de 13 dígitos según el tipo indicado por
+// su tercer dígito y devuelve también su código de establecimiento; ok es false si el tercer
+// dígito no corresponde a ningún tipo de contribuyente
+func verificadorRUC(ruc string) (valido bool, establecimiento string, ok bool) {
+	switch tercero := ruc[2] - '0'; {
+	case tercero < 6:
+		return digitoVerificadorValido(ruc[:10]), ruc[10:], true
+	case tercero == 6:
+		return verificadorModulo11(ruc[:8], []int{3, 2, 7, 6, 5, 4, 3, 2}, ruc[8]), ruc[9:], true
+	case tercero == 9:
+		return verificadorModulo11(ruc[:9], []int{4, 3, 2, 7, 6, 5, 4, 3, 2}, ruc[9]), ruc[10:], true
+	default:
+		return false, "", false
+	}
+}
+
+// CheckDigitValid indica si el dígito verificador de una cédula de 10 dígitos o de un RUC de 13
+// es correcto, sin revisar la provincia, el tipo de persona ni el establecimiento
+func CheckDigitValid(id string) bool {
+	switch {
+	case patronCedula.MatchString(id):
+		return digitoVerificadorValido(id)
+	case patronRUC.MatchString(id):
+		valido, _, ok := verificadorRUC(id)
+		return ok && valido
+	default:
+		return false
+	}
+}
+
+// verificadorModulo11 calcula el dígito verificador módulo 11 de los dígitos con los coeficientes
+// dados y lo compara con el dígito esperado. Un resultado de 10 nunca es válido.
+func verificadorModulo11(digitos string, coeficientes []int, esperado byte) bool {
+	suma := 0
+	for i, coeficiente := range coeficientes {
+		suma += int(digitos[i]-'0') * coeficiente
+	}
+
+	verificador := 11 - suma%11
+	if verificador == 11 {
+		verificador = 0
+	}
+	return verificador < 10 && verificador == int(esperado-'0')
+}
+
+// establecimientoValido indica si el código de establecimiento del RUC es distinto de cero
+func establecimientoValido(codigo string) bool {
+	numero, err := strconv.Atoi(codigo)
+	return err == nil && numero > 0
+}
+
+// PersonType devuelve el tipoPersona del SRI para una identificación: las cédulas y los RUC
+// de personas naturales son NaturalPerson; los RUC de sociedades y entidades públicas son LegalEntity
+func PersonType(identificacion string) string {
+	if len(identificacion) == 13 && identificacion[2]-'0' >= 6 {
+		return LegalEntity
+	}
+	return NaturalPerson
+}
@@ -0,0 +1,161 @@
+package cedula
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRUCEstablecimiento(t *testing.T) {
+	casos := []struct {
+		nombre string
+		ruc    string
+		err    error
+	}{
+		{"natural 001", "1710034065001", nil},
+		{"natural 000", "1710034065000", ErrRUCEstablishment},
+		{"privada 000", "1790000001000", ErrRUCEstablishment},
+		{"pública 0000", "1760000070000", ErrRUCEstablishment},
+		{"sufijo no numérico", "1710034065A01", ErrRUCFormat},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if err := CheckRUC(caso.ruc); !errors.Is(err, caso.err) {
+				t.Errorf("CheckRUC(%q) = %v, se esperaba %v", caso.ruc, err, caso.err)
+			}
+			if ValidateRUC(caso.ruc) != (caso.err == nil) {
+				t.Errorf("ValidateRUC(%q) no coincide con CheckRUC", caso.ruc)
+			}
+		})
+	}
+}
+
+func TestValidateCedula(t *testing.T) {
+	casos := []struct {
+		nombre string
+		cedula string
+		valida bool
+	}{
+		{"válida Pichincha", "1710034065", true},
+		{"válida Guayas", "0912345675", true},
+		{"válida Azuay con verificador 0", "0102030400", true},
+		{"válida Santa Elena", "2401010109", true},
+		{"provincia 30 exterior", "3000000004", true},
+		{"provincia 00", "0001010107", false},
+		{"provincia 25", "2501010108", false},
+		{"tercer dígito 6", "1765432107", false},
+		{"tercer dígito 9", "1790000001", false},
+		{"verificador incorrecto", "1710034064", false},
+		{"nueve dígitos", "171003406", false},
+		{"once dígitos", "17100340650", false},
+		{"con letras", "17100340A5", false},
+		{"vacía", "", false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if got := ValidateCedula(caso.cedula); got != caso.valida {
+				t.Errorf("ValidateCedula(%q) = %t, se esperaba %t", caso.cedula, got, caso.valida)
+			}
+		})
+	}
+}
+
+func TestDigitoVerificadorValido(t *testing.T) {
+	// Cada dígito verificador de 0 a 9 es válido para exactamente una de las diez variantes
+	for _, base := range []string{"171003406", "091234567", "240101010"} {
+		validos := 0
+		for d := '0'; d <= '9'; d++ {
+			if digitoVerificadorValido(base + string(d)) {
+				validos++
+			}
+		}
+		if validos != 1 {
+			t.Errorf("%s: %d dígitos verificadores válidos, se esperaba 1", base, validos)
+		}
+	}
+}
+
+func TestCheckRUC(t *testing.T) {
+	casos := []struct {
+		nombre string
+		ruc    string
+		err    error
+	}{
+		{"persona natural", "1710034065001", nil},
+		{"sociedad privada", "1790000001001", nil},
+		{"entidad pública", "1760000070001", nil},
+		{"natural con verificador incorrecto", "1710034064001", ErrRUCCheckDigit},
+		{"privada con verificador incorrecto", "1790000002001", ErrRUCCheckDigit},
+		{"pública con verificador incorrecto", "1760000080001", ErrRUCCheckDigit},
+		{"módulo 11 igual a 10", "0990000000001", ErrRUCCheckDigit},
+		{"natural con establecimiento 000", "1710034065000", ErrRUCEstablishment},
+		{"privada con establecimiento 000", "1790000001000", ErrRUCEstablishment},
+		{"tercer dígito 7", "1770000001001", ErrRUCType},
+		{"tercer dígito 8", "1780000001001", ErrRUCType},
+		{"provincia 25", "2590000001001", ErrRUCProvince},
+		{"provincia 00", "0090000001001", ErrRUCProvince},
+		{"doce dígitos", "179000000100", ErrRUCFormat},
+		{"cédula", "1710034065", ErrRUCFormat},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if err := CheckRUC(caso.ruc); !errors.Is(err, caso.err) {
+				t.Errorf("CheckRUC(%q) = %v, se esperaba %v", caso.ruc, err, caso.err)
+			}
+		})
+	}
+}
+
+func TestPersonType(t *testing.T) {
+	casos := []struct {
+		identificacion string
+		tipo           string
+	}{
+		{"1710034065", NaturalPerson},
+		{"1710034065001", NaturalPerson},
+		{"1790000001001", LegalEntity},
+		{"1760000070001", LegalEntity},
+		// Las cédulas nunca son de persona jurídica, aunque el tercer dígito sea 6 o mayor
+		{"1765432107", NaturalPerson},
+	}
+	for _, caso := range casos {
+		if tipo := PersonType(caso.identificacion); tipo != caso.tipo {
+			t.Errorf("PersonType(%q) = %q, se esperaba %q", caso.identificacion, tipo, caso.tipo)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	casos := []struct {
+		entrada     string
+		normalizada string
+		ok          bool
+	}{
+		{"1710034065", "1710034065", true},
+		{"  1710034065 ", "1710034065", true},
+		// Dígito verificador separado por guion
+		{"171003406-5", "1710034065", true},
+		{" 171003406-5 ", "1710034065", true},
+		{"091234567-5", "0912345675", true},
+		// Guiones en otra posición o repetidos
+		{"17100340-65", "17100340-65", false},
+		{"1710-034065", "1710-034065", false},
+		{"171003406--5", "171003406--5", false},
+		{"17-1003406-5", "17-1003406-5", false},
+		{"171003406-", "171003406-", false},
+		{"-1710034065", "-1710034065", false},
+		{"171003406-55", "171003406-55", false},
+		// Otros separadores no se reconocen; la validación posterior los rechaza
+		{"171003406.5", "171003406.5", true},
+		{"171003406 5", "171003406 5", true},
+		{"171003406–5", "171003406–5", true},
+	}
+	for _, caso := range casos {
+		normalizada, ok := Normalize(caso.entrada)
+		if normalizada != caso.normalizada || ok != caso.ok {
+			t.Errorf("Normalize(%q) = %q, %t; se esperaba %q, %t", caso.entrada, normalizada, ok, caso.normalizada, caso.ok)
+		}
+		if ok && normalizada != "1710034065" && normalizada != "0912345675" && ValidateCedula(normalizada) {
+			t.Errorf("ValidateCedula(%q) aceptó un separador no reconocido", normalizada)
+		}
+	}
+}
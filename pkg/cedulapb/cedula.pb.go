@@ -0,0 +1,588 @@
+// Mensajes protobuf de las respuestas de la API de consulta de cédulas.
+// Para regenerar el código Go:
+//   protoc --go_out=. --go_opt=module=consulta-cedula-app proto/cedula.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: proto/cedula.proto
+
+package cedulapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ActividadEconomica representa una actividad económica (código CIIU) registrada en el SRI
+type ActividadEconomica struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ciiu        string `protobuf:"bytes,1,opt,name=ciiu,proto3" json:"ciiu,omitempty"`
+	Descripcion string `protobuf:"bytes,2,opt,name=descripcion,proto3" json:"descripcion,omitempty"`
+}
+
+func (x *ActividadEconomica) Reset() {
+	*x = ActividadEconomica{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_cedula_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActividadEconomica) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActividadEconomica) ProtoMessage() {}
+
+func (x *ActividadEconomica) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cedula_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActividadEconomica.ProtoReflect.Descriptor instead.
+func (*ActividadEconomica) Descriptor() ([]byte, []int) {
+	return file_proto_cedula_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ActividadEconomica) GetCiiu() string {
+	if x != nil {
+		return x.Ciiu
+	}
+	return ""
+}
+
+func (x *ActividadEconomica) GetDescripcion() string {
+	if x != nil {
+		return x.Descripcion
+	}
+	return ""
+}
+
+// CedulaResponse representa la respuesta exitosa de la consulta por cédula
+type CedulaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nombre                 string                `protobuf:"bytes,1,opt,name=nombre,proto3" json:"nombre,omitempty"`
+	Apellido               string                `protobuf:"bytes,2,opt,name=apellido,proto3" json:"apellido,omitempty"`
+	Actividades            []*ActividadEconomica `protobuf:"bytes,3,rep,name=actividades,proto3" json:"actividades,omitempty"`
+	NombresAnteriores      []string              `protobuf:"bytes,4,rep,name=nombres_anteriores,json=nombresAnteriores,proto3" json:"nombres_anteriores,omitempty"`
+	NombreFormateado       string                `protobuf:"bytes,5,opt,name=nombre_formateado,json=nombreFormateado,proto3" json:"nombre_formateado,omitempty"`
+	FechaInicioActividades string                `protobuf:"bytes,6,opt,name=fecha_inicio_actividades,json=fechaInicioActividades,proto3" json:"fecha_inicio_actividades,omitempty"`
+	TieneDeudas            bool                  `protobuf:"varint,7,opt,name=tiene_deudas,json=tieneDeudas,proto3" json:"tiene_deudas,omitempty"`
+	MontoTotal             float64               `protobuf:"fixed64,8,opt,name=monto_total,json=montoTotal,proto3" json:"monto_total,omitempty"`
+	Nombres                string                `protobuf:"bytes,9,opt,name=nombres,proto3" json:"nombres,omitempty"`
+	Apellidos              string                `protobuf:"bytes,10,opt,name=apellidos,proto3" json:"apellidos,omitempty"`
+	Fuente                 string                `protobuf:"bytes,11,opt,name=fuente,proto3" json:"fuente,omitempty"`
+	Provincia              string                `protobuf:"bytes,12,opt,name=provincia,proto3" json:"provincia,omitempty"`
+	PrimerNombre           string                `protobuf:"bytes,13,opt,name=primer_nombre,json=primerNombre,proto3" json:"primer_nombre,omitempty"`
+	SegundoNombre          string                `protobuf:"bytes,14,opt,name=segundo_nombre,json=segundoNombre,proto3" json:"segundo_nombre,omitempty"`
+	PrimerApellido         string                `protobuf:"bytes,15,opt,name=primer_apellido,json=primerApellido,proto3" json:"primer_apellido,omitempty"`
+	SegundoApellido        string                `protobuf:"bytes,16,opt,name=segundo_apellido,json=segundoApellido,proto3" json:"segundo_apellido,omitempty"`
+	CheckDigitValid        bool                  `protobuf:"varint,17,opt,name=check_digit_valid,json=checkDigitValid,proto3" json:"check_digit_valid,omitempty"`
+	FromFallback           bool                  `protobuf:"varint,18,opt,name=from_fallback,json=fromFallback,proto3" json:"from_fallback,omitempty"`
+	RetrievedAt            string                `protobuf:"bytes,19,opt,name=retrieved_at,json=retrievedAt,proto3" json:"retrieved_at,omitempty"`
+}
+
+func (x *CedulaResponse) Reset() {
+	*x = CedulaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_cedula_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CedulaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CedulaResponse) ProtoMessage() {}
+
+func (x *CedulaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cedula_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CedulaResponse.ProtoReflect.Descriptor instead.
+func (*CedulaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cedula_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CedulaResponse) GetNombre() string {
+	if x != nil {
+		return x.Nombre
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetApellido() string {
+	if x != nil {
+		return x.Apellido
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetActividades() []*ActividadEconomica {
+	if x != nil {
+		return x.Actividades
+	}
+	return nil
+}
+
+func (x *CedulaResponse) GetNombresAnteriores() []string {
+	if x != nil {
+		return x.NombresAnteriores
+	}
+	return nil
+}
+
+func (x *CedulaResponse) GetNombreFormateado() string {
+	if x != nil {
+		return x.NombreFormateado
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetFechaInicioActividades() string {
+	if x != nil {
+		return x.FechaInicioActividades
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetTieneDeudas() bool {
+	if x != nil {
+		return x.TieneDeudas
+	}
+	return false
+}
+
+func (x *CedulaResponse) GetMontoTotal() float64 {
+	if x != nil {
+		return x.MontoTotal
+	}
+	return 0
+}
+
+func (x *CedulaResponse) GetNombres() string {
+	if x != nil {
+		return x.Nombres
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetApellidos() string {
+	if x != nil {
+		return x.Apellidos
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetFuente() string {
+	if x != nil {
+		return x.Fuente
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetProvincia() string {
+	if x != nil {
+		return x.Provincia
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetPrimerNombre() string {
+	if x != nil {
+		return x.PrimerNombre
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetSegundoNombre() string {
+	if x != nil {
+		return x.SegundoNombre
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetPrimerApellido() string {
+	if x != nil {
+		return x.PrimerApellido
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetSegundoApellido() string {
+	if x != nil {
+		return x.SegundoApellido
+	}
+	return ""
+}
+
+func (x *CedulaResponse) GetCheckDigitValid() bool {
+	if x != nil {
+		return x.CheckDigitValid
+	}
+	return false
+}
+
+func (x *CedulaResponse) GetFromFallback() bool {
+	if x != nil {
+		return x.FromFallback
+	}
+	return false
+}
+
+func (x *CedulaResponse) GetRetrievedAt() string {
+	if x != nil {
+		return x.RetrievedAt
+	}
+	return ""
+}
+
+// ErrorCampo describe el problema de validación de un campo de la petición
+type ErrorCampo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Campo   string `protobuf:"bytes,1,opt,name=campo,proto3" json:"campo,omitempty"`
+	Mensaje string `protobuf:"bytes,2,opt,name=mensaje,proto3" json:"mensaje,omitempty"`
+}
+
+func (x *ErrorCampo) Reset() {
+	*x = ErrorCampo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_cedula_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ErrorCampo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorCampo) ProtoMessage() {}
+
+func (x *ErrorCampo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cedula_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorCampo.ProtoReflect.Descriptor instead.
+func (*ErrorCampo) Descriptor() ([]byte, []int) {
+	return file_proto_cedula_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ErrorCampo) GetCampo() string {
+	if x != nil {
+		return x.Campo
+	}
+	return ""
+}
+
+func (x *ErrorCampo) GetMensaje() string {
+	if x != nil {
+		return x.Mensaje
+	}
+	return ""
+}
+
+// ErrorResponse representa la respuesta de error estándar
+type ErrorResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Error     string        `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Code      string        `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Timestamp string        `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Campos    []*ErrorCampo `protobuf:"bytes,4,rep,name=campos,proto3" json:"campos,omitempty"`
+	RequestId string        `protobuf:"bytes,5,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *ErrorResponse) Reset() {
+	*x = ErrorResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_cedula_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorResponse) ProtoMessage() {}
+
+func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cedula_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
+func (*ErrorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cedula_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ErrorResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ErrorResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorResponse) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *ErrorResponse) GetCampos() []*ErrorCampo {
+	if x != nil {
+		return x.Campos
+	}
+	return nil
+}
+
+func (x *ErrorResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+var File_proto_cedula_proto protoreflect.FileDescriptor
+
+var file_proto_cedula_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x22, 0x4a, 0x0a, 0x12,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x64, 0x61, 0x64, 0x45, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x69,
+	0x63, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x69, 0x69, 0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x63, 0x69, 0x69, 0x75, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x63, 0x69, 0x6f, 0x6e, 0x22, 0xde, 0x05, 0x0a, 0x0e, 0x43, 0x65, 0x64,
+	0x75, 0x6c, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6e,
+	0x6f, 0x6d, 0x62, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x6d,
+	0x62, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x12,
+	0x3c, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x64, 0x61, 0x64, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x2e, 0x41, 0x63,
+	0x74, 0x69, 0x76, 0x69, 0x64, 0x61, 0x64, 0x45, 0x63, 0x6f, 0x6e, 0x6f, 0x6d, 0x69, 0x63, 0x61,
+	0x52, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x64, 0x61, 0x64, 0x65, 0x73, 0x12, 0x2d, 0x0a,
+	0x12, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f,
+	0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x6e, 0x6f, 0x6d, 0x62, 0x72,
+	0x65, 0x73, 0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11,
+	0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x65, 0x61, 0x64,
+	0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x46,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x65, 0x61, 0x64, 0x6f, 0x12, 0x38, 0x0a, 0x18, 0x66, 0x65, 0x63,
+	0x68, 0x61, 0x5f, 0x69, 0x6e, 0x69, 0x63, 0x69, 0x6f, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69,
+	0x64, 0x61, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x66, 0x65, 0x63,
+	0x68, 0x61, 0x49, 0x6e, 0x69, 0x63, 0x69, 0x6f, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x64, 0x61,
+	0x64, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x65, 0x6e, 0x65, 0x5f, 0x64, 0x65, 0x75,
+	0x64, 0x61, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x74, 0x69, 0x65, 0x6e, 0x65,
+	0x44, 0x65, 0x75, 0x64, 0x61, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x6f, 0x6e, 0x74, 0x6f, 0x5f,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x6f, 0x6e,
+	0x74, 0x6f, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x6f, 0x6d, 0x62, 0x72,
+	0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x73, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x66, 0x75, 0x65, 0x6e, 0x74, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x66, 0x75, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x6e, 0x63, 0x69, 0x61, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x76,
+	0x69, 0x6e, 0x63, 0x69, 0x61, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x69, 0x6d, 0x65, 0x72, 0x5f,
+	0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x72,
+	0x69, 0x6d, 0x65, 0x72, 0x4e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x65,
+	0x67, 0x75, 0x6e, 0x64, 0x6f, 0x5f, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x73, 0x65, 0x67, 0x75, 0x6e, 0x64, 0x6f, 0x4e, 0x6f, 0x6d, 0x62, 0x72,
+	0x65, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x69, 0x6d, 0x65, 0x72, 0x5f, 0x61, 0x70, 0x65, 0x6c,
+	0x6c, 0x69, 0x64, 0x6f, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x72, 0x69, 0x6d,
+	0x65, 0x72, 0x41, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x65,
+	0x67, 0x75, 0x6e, 0x64, 0x6f, 0x5f, 0x61, 0x70, 0x65, 0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x65, 0x67, 0x75, 0x6e, 0x64, 0x6f, 0x41, 0x70, 0x65,
+	0x6c, 0x6c, 0x69, 0x64, 0x6f, 0x12, 0x2a, 0x0a, 0x11, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x64,
+	0x69, 0x67, 0x69, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x44, 0x69, 0x67, 0x69, 0x74, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61,
+	0x63, 0x6b, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x46, 0x61,
+	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65,
+	0x76, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65,
+	0x74, 0x72, 0x69, 0x65, 0x76, 0x65, 0x64, 0x41, 0x74, 0x22, 0x3c, 0x0a, 0x0a, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x43, 0x61, 0x6d, 0x70, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x6d, 0x70, 0x6f,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x6e, 0x73, 0x61, 0x6a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6d, 0x65, 0x6e, 0x73, 0x61, 0x6a, 0x65, 0x22, 0xa2, 0x01, 0x0a, 0x0d, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x2a, 0x0a, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x43, 0x61, 0x6d, 0x70, 0x6f, 0x52, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x42, 0x22, 0x5a, 0x20,
+	0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x2d, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x2d,
+	0x61, 0x70, 0x70, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x63, 0x65, 0x64, 0x75, 0x6c, 0x61, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_cedula_proto_rawDescOnce sync.Once
+	file_proto_cedula_proto_rawDescData = file_proto_cedula_proto_rawDesc
+)
+
+func file_proto_cedula_proto_rawDescGZIP() []byte {
+	file_proto_cedula_proto_rawDescOnce.Do(func() {
+		file_proto_cedula_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_cedula_proto_rawDescData)
+	})
+	return file_proto_cedula_proto_rawDescData
+}
+
+var file_proto_cedula_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_cedula_proto_goTypes = []any{
+	(*ActividadEconomica)(nil), // 0: cedula.ActividadEconomica
+	(*CedulaResponse)(nil),     // 1: cedula.CedulaResponse
+	(*ErrorCampo)(nil),         // 2: cedula.ErrorCampo
+	(*ErrorResponse)(nil),      // 3: cedula.ErrorResponse
+}
+var file_proto_cedula_proto_depIdxs = []int32{
+	0, // 0: cedula.CedulaResponse.actividades:type_name -> cedula.ActividadEconomica
+	2, // 1: cedula.ErrorResponse.campos:type_name -> cedula.ErrorCampo
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_cedula_proto_init() }
+func file_proto_cedula_proto_init() {
+	if File_proto_cedula_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_cedula_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ActividadEconomica); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_cedula_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*CedulaResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_cedula_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ErrorCampo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_cedula_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ErrorResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_cedula_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_cedula_proto_goTypes,
+		DependencyIndexes: file_proto_cedula_proto_depIdxs,
+		MessageInfos:      file_proto_cedula_proto_msgTypes,
+	}.Build()
+	File_proto_cedula_proto = out.File
+	file_proto_cedula_proto_rawDesc = nil
+	file_proto_cedula_proto_goTypes = nil
+	file_proto_cedula_proto_depIdxs = nil
+}